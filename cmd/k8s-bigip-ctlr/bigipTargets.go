@@ -0,0 +1,163 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/controller"
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	"gopkg.in/yaml.v2"
+)
+
+// bigIPTargetNameRegex matches the names which can be referenced with bigipRef
+var bigIPTargetNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][-A-Za-z0-9_.]*$`)
+
+// bigIPTarget is an additional BIG-IP of the BIG-IP targets file
+type bigIPTarget struct {
+	Name                 string `yaml:"name"`
+	URL                  string `yaml:"url"`
+	Username             string `yaml:"username"`
+	Password             string `yaml:"password"`
+	CredentialsDirectory string `yaml:"credentials-directory"`
+	CredentialsSecret    string `yaml:"credentials-secret"`
+	Partition            string `yaml:"partition"`
+	DefaultRouteDomain   int    `yaml:"default-route-domain"`
+	// ServerName overrides --bigip-server-name for the verification of the BIG-IP certificate
+	ServerName string `yaml:"server-name"`
+
+	credsProvider controller.CredentialProvider
+	agent         *controller.Agent
+}
+
+// bigIPTargetsConfig is the format of the BIG-IP targets file
+type bigIPTargetsConfig struct {
+	Targets []*bigIPTarget `yaml:"targets"`
+}
+
+// readBigIPTargets parses the BIG-IP targets and validates their names and partitions
+func readBigIPTargets(data []byte) ([]*bigIPTarget, error) {
+	var targetsConfig bigIPTargetsConfig
+	if err := yaml.UnmarshalStrict(data, &targetsConfig); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	partitions := make(map[string]bool)
+	for _, partition := range *bigIPPartitions {
+		partitions[partition] = true
+	}
+	for _, target := range targetsConfig.Targets {
+		if !bigIPTargetNameRegex.MatchString(target.Name) {
+			return nil, fmt.Errorf("invalid BIG-IP target name %q", target.Name)
+		}
+		if names[target.Name] {
+			return nil, fmt.Errorf("duplicate BIG-IP target %v", target.Name)
+		}
+		names[target.Name] = true
+		switch {
+		case len(target.Partition) == 0:
+			return nil, fmt.Errorf("partition not specified for BIG-IP target %v", target.Name)
+		case target.Partition == "Common":
+			return nil, fmt.Errorf("Common cannot be the partition of BIG-IP target %v", target.Name)
+		case partitions[target.Partition]:
+			// Resources are routed to the BIG-IP targets by partition
+			return nil, fmt.Errorf("partition %v of BIG-IP target %v is already used", target.Partition, target.Name)
+		}
+		partitions[target.Partition] = true
+		if len(target.CredentialsDirectory) > 0 && len(target.CredentialsSecret) > 0 {
+			return nil, fmt.Errorf("only one of credentials-directory and credentials-secret "+
+				"can be specified for BIG-IP target %v", target.Name)
+		}
+	}
+	return targetsConfig.Targets, nil
+}
+
+// loadBigIPTargets reads the BIG-IP targets file and fetches the credentials of each target
+func loadBigIPTargets(path string) ([]*bigIPTarget, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := readBigIPTargets(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BIG-IP targets file %v: %v", path, err)
+	}
+	for _, target := range targets {
+		target.credsProvider, err = getCredentialProvider(target.CredentialsDirectory, target.CredentialsSecret, "")
+		if err != nil {
+			return nil, fmt.Errorf("BIG-IP target %v: %v", target.Name, err)
+		}
+		if target.credsProvider != nil {
+			err = setProviderCredentials(target.credsProvider, &target.Username, &target.Password, &target.URL)
+		} else if len(target.URL) == 0 || len(target.Username) == 0 || len(target.Password) == 0 {
+			err = fmt.Errorf("url, username and password or credentials are required")
+		} else {
+			err = verifyBigIPURL(&target.URL)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("BIG-IP target %v: %v", target.Name, err)
+		}
+	}
+	return targets, nil
+}
+
+// newBigIPTargetAgents creates the agents of the BIG-IP targets. The agents post the AS3 declarations
+// of their partition with the AS3 and TLS settings of the default BIG-IP, without network and GTM configuration.
+func newBigIPTargetAgents(targets []*bigIPTarget, params controller.AgentParams) []*controller.BigIPTarget {
+	var bigIPTargets []*controller.BigIPTarget
+	for _, target := range targets {
+		serverName := params.PostParams.ServerName
+		if len(target.ServerName) > 0 {
+			serverName = target.ServerName
+		}
+		targetParams := controller.AgentParams{
+			PostParams: controller.PostParams{
+				BIGIPUsername:           target.Username,
+				BIGIPPassword:           target.Password,
+				BIGIPURL:                target.URL,
+				TrustedCerts:            params.PostParams.TrustedCerts,
+				SSLInsecure:             params.PostParams.SSLInsecure,
+				ClientCert:              params.PostParams.ClientCert,
+				ClientKey:               params.PostParams.ClientKey,
+				ServerName:              serverName,
+				AS3PostDelay:            params.PostParams.AS3PostDelay,
+				LogResponse:             params.PostParams.LogResponse,
+				PostConcurrency:         params.PostParams.PostConcurrency,
+				RetryMaxInterval:        params.PostParams.RetryMaxInterval,
+				CircuitBreakerThreshold: params.PostParams.CircuitBreakerThreshold,
+				CircuitBreakerInterval:  params.PostParams.CircuitBreakerInterval,
+			},
+			Partition:  target.Partition,
+			LogLevel:   params.LogLevel,
+			UserAgent:  params.UserAgent,
+			EnableIPV6: params.EnableIPV6,
+			Batch:      params.Batch,
+			LTMOnly:    true,
+		}
+		target.agent = controller.NewAgent(targetParams)
+		log.Infof("[INIT] Configuring partition %v of BIG-IP target %v at %v",
+			target.Partition, target.Name, target.URL)
+		bigIPTargets = append(bigIPTargets, &controller.BigIPTarget{
+			Name:               target.Name,
+			Agent:              target.agent,
+			DefaultRouteDomain: target.DefaultRouteDomain,
+		})
+	}
+	return bigIPTargets
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BIG-IP Targets Tests", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "cis-targets")
+		Expect(err).ToNot(HaveOccurred())
+		flags.Parse([]string{"./bin/k8s-bigip-ctlr", "--bigip-partition=velcro1"})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+		_init()
+	})
+
+	It("Reads the BIG-IP targets with their credentials", func() {
+		credsDir := filepath.Join(dir, "creds")
+		Expect(os.Mkdir(credsDir, 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(credsDir, "username"), []byte("admin\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(credsDir, "password"), []byte("secret\n"), 0644)).To(Succeed())
+		targetsFile := filepath.Join(dir, "targets.yaml")
+		Expect(ioutil.WriteFile(targetsFile, []byte(`
+targets:
+- name: site-b
+  url: 10.1.1.2
+  username: admin
+  password: admin
+  partition: site-b
+  default-route-domain: 2
+  server-name: site-b.example.com
+- name: site-c
+  url: https://10.1.1.3
+  credentials-directory: `+credsDir+`
+  partition: site-c
+`), 0644)).To(Succeed())
+
+		targets, err := loadBigIPTargets(targetsFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targets).To(HaveLen(2))
+		Expect(targets[0].URL).To(Equal("https://10.1.1.2"))
+		Expect(targets[0].DefaultRouteDomain).To(Equal(2))
+		Expect(targets[0].ServerName).To(Equal("site-b.example.com"))
+		Expect(targets[0].credsProvider).To(BeNil())
+		Expect(targets[1].Username).To(Equal("admin"))
+		Expect(targets[1].Password).To(Equal("secret"))
+		Expect(targets[1].credsProvider).ToNot(BeNil())
+	})
+
+	It("Rejects invalid BIG-IP targets", func() {
+		_, err := readBigIPTargets([]byte("targets:\n- name: site-b\n  partition: velcro1\n"))
+		Expect(err).To(HaveOccurred(), "Partition of the default BIG-IP accepted")
+
+		_, err = readBigIPTargets([]byte("targets:\n- name: site-b\n  partition: b\n- name: site-b\n  partition: c\n"))
+		Expect(err).To(HaveOccurred(), "Duplicate name accepted")
+
+		_, err = readBigIPTargets([]byte("targets:\n- name: site-b\n  partition: b\n- name: site-c\n  partition: b\n"))
+		Expect(err).To(HaveOccurred(), "Duplicate partition accepted")
+
+		_, err = readBigIPTargets([]byte("targets:\n- name: site-b\n  partition: b\n  address: 10.1.1.2\n"))
+		Expect(err).To(HaveOccurred(), "Unknown field accepted")
+
+		_, err = readBigIPTargets([]byte("targets:\n- name: site-b\n  partition: Common\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,311 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const configFileCheckInterval = 10 * time.Second
+
+type (
+	// configFile holds the options of the config file by section and option name
+	configFile map[string]map[string]interface{}
+
+	// liveSettings are the options of the config file which are applied without restarting CIS
+	liveSettings struct {
+		namespaces         []string
+		logLevel           string
+		as3PostDelay       int
+		ciphers            string
+		cipherGroup        string
+		defaultRouteDomain int
+	}
+
+	// liveConfig watches the config file and applies the changes of the live settings
+	liveConfig struct {
+		path    string
+		data    []byte
+		cfg     configFile
+		current liveSettings
+
+		logConfig             *runtimeLogConfig
+		setNamespaces         func([]string) error
+		setPostDelay          func(int)
+		setCiphers            func(cipherGroup, ciphers string)
+		setDefaultRouteDomain func(int)
+	}
+)
+
+// liveConfigAgent is implemented by the agents which apply the live settings
+type liveConfigAgent interface {
+	SetPostDelay(seconds int)
+	SetCiphers(tls13CipherGroupReference, ciphers string)
+	SetDefaultRouteDomain(routeDomain int)
+}
+
+// liveOptions are the options of each section reloaded when the config file changes
+var liveOptions = map[string][]string{
+	"global":     {"log-level", "default-route-domain"},
+	"bigip":      {"as3-post-delay", "ciphers", "cipher-group"},
+	"kubernetes": {"namespace"},
+}
+
+// cmdLineFlags holds the options set on the command line, which override the config file
+var cmdLineFlags map[string]bool
+
+// configSections returns the flags of each section of the config file
+func configSections() map[string]*pflag.FlagSet {
+	return map[string]*pflag.FlagSet{
+		"global":     globalFlags,
+		"bigip":      bigIPFlags,
+		"kubernetes": kubeFlags,
+		"vxlan":      vxlanFlags,
+		"routes":     osRouteFlags,
+		"gtm":        gtmBigIPFlags,
+	}
+}
+
+func readConfigFile(data []byte) (configFile, error) {
+	cfg := make(configFile)
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	sections := configSections()
+	for section, options := range cfg {
+		fs, ok := sections[section]
+		if !ok {
+			return nil, fmt.Errorf("unknown section %v, valid sections are: "+
+				"global, bigip, kubernetes, vxlan, routes, gtm", section)
+		}
+		for name, value := range options {
+			if fs.Lookup(name) == nil || name == "config" {
+				return nil, fmt.Errorf("unknown option %v in section %v", name, section)
+			}
+			if _, err := configValues(value); err != nil {
+				return nil, fmt.Errorf("invalid value of option %v in section %v: %v", name, section, err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// configValues returns the value of an option as flag values, one for each item of a list
+func configValues(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case []interface{}, map[interface{}]interface{}:
+				return nil, fmt.Errorf("lists can only hold plain values")
+			}
+			values = append(values, fmt.Sprint(item))
+		}
+		return values, nil
+	case map[interface{}]interface{}:
+		return nil, fmt.Errorf("expected a plain value or a list")
+	default:
+		return []string{fmt.Sprint(v)}, nil
+	}
+}
+
+// loadConfigFile sets the options which are not set on the command line from the config file
+func loadConfigFile(path string) (*liveConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := readConfigFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %v: %v", path, err)
+	}
+	cmdLineFlags = make(map[string]bool)
+	flags.Visit(func(flag *pflag.Flag) {
+		cmdLineFlags[flag.Name] = true
+	})
+	sections := configSections()
+	for section, options := range cfg {
+		for name, value := range options {
+			if cmdLineFlags[name] {
+				continue
+			}
+			values, _ := configValues(value)
+			for _, v := range values {
+				if err := sections[section].Set(name, v); err != nil {
+					return nil, fmt.Errorf("invalid value of option %v in section %v of config file %v: %v",
+						name, section, path, err)
+				}
+			}
+		}
+	}
+	lc := &liveConfig{path: path, data: data, cfg: cfg}
+	lc.current = lc.settings(cfg)
+	return lc, nil
+}
+
+// settings returns the live settings of the config file. Options set on the command line
+// keep their value, options which are not in the config file have their default value.
+func (lc *liveConfig) settings(cfg configFile) liveSettings {
+	settings := lc.current
+	value := func(section, name string) ([]string, bool) {
+		if cmdLineFlags[name] {
+			return nil, false
+		}
+		if option, ok := cfg[section][name]; ok {
+			values, _ := configValues(option)
+			return values, true
+		}
+		flag := configSections()[section].Lookup(name)
+		if _, ok := flag.Value.(pflag.SliceValue); ok {
+			return nil, true
+		}
+		return []string{flag.DefValue}, true
+	}
+	last := func(values []string) string {
+		if len(values) == 0 {
+			return ""
+		}
+		return values[len(values)-1]
+	}
+	if values, ok := value("kubernetes", "namespace"); ok {
+		settings.namespaces = values
+	}
+	if values, ok := value("global", "log-level"); ok {
+		settings.logLevel = last(values)
+	}
+	if values, ok := value("global", "default-route-domain"); ok {
+		if rd, err := strconv.Atoi(last(values)); err == nil {
+			settings.defaultRouteDomain = rd
+		}
+	}
+	if values, ok := value("bigip", "as3-post-delay"); ok {
+		if delay, err := strconv.Atoi(last(values)); err == nil {
+			settings.as3PostDelay = delay
+		}
+	}
+	if values, ok := value("bigip", "ciphers"); ok {
+		settings.ciphers = last(values)
+	}
+	if values, ok := value("bigip", "cipher-group"); ok {
+		settings.cipherGroup = last(values)
+	}
+	return settings
+}
+
+// watch checks the config file for changes until the stop channel is closed
+func (lc *liveConfig) watch(stopCh <-chan struct{}) {
+	wait.Until(lc.reload, configFileCheckInterval, stopCh)
+}
+
+// reload applies the live settings of the config file when it changes
+func (lc *liveConfig) reload() {
+	data, err := ioutil.ReadFile(lc.path)
+	if err != nil {
+		log.Errorf("[INIT] Unable to read config file %v: %v", lc.path, err)
+		return
+	}
+	if bytes.Equal(data, lc.data) {
+		return
+	}
+	lc.data = data
+	cfg, err := readConfigFile(data)
+	if err != nil {
+		log.Errorf("[INIT] Invalid config file %v, keeping the current configuration: %v", lc.path, err)
+		return
+	}
+	if !reflect.DeepEqual(withoutLiveOptions(cfg), withoutLiveOptions(lc.cfg)) {
+		log.Warningf("[INIT] Config file %v changed options which are applied only when CIS restarts", lc.path)
+	}
+	lc.cfg = cfg
+	lc.apply(lc.settings(cfg))
+}
+
+// withoutLiveOptions returns the options of the config file which require a restart
+func withoutLiveOptions(cfg configFile) configFile {
+	options := make(configFile)
+	for section, values := range cfg {
+		for name, value := range values {
+			if !isLiveOption(section, name) {
+				if options[section] == nil {
+					options[section] = make(map[string]interface{})
+				}
+				options[section][name] = value
+			}
+		}
+	}
+	return options
+}
+
+func isLiveOption(section, name string) bool {
+	for _, option := range liveOptions[section] {
+		if option == name {
+			return true
+		}
+	}
+	return false
+}
+
+// apply changes the live settings which differ from the current settings
+func (lc *liveConfig) apply(settings liveSettings) {
+	source := "config file " + lc.path
+	if settings.logLevel != lc.current.logLevel && lc.logConfig != nil {
+		if err := lc.logConfig.apply(logSettings{LogLevel: settings.logLevel}, source); err != nil {
+			log.Errorf("[INIT] Invalid log-level in %v: %v", source, err)
+			settings.logLevel = lc.current.logLevel
+		}
+	}
+	if !reflect.DeepEqual(settings.namespaces, lc.current.namespaces) {
+		if lc.setNamespaces == nil {
+			log.Warningf("[INIT] namespace changes in %v are applied only when CIS restarts", source)
+		} else if err := lc.setNamespaces(settings.namespaces); err != nil {
+			log.Errorf("[INIT] Unable to change the namespaces to %v: %v", settings.namespaces, err)
+			settings.namespaces = lc.current.namespaces
+		}
+	}
+	if settings.as3PostDelay != lc.current.as3PostDelay && lc.setPostDelay != nil {
+		lc.setPostDelay(settings.as3PostDelay)
+		log.Infof("[INIT] as3-post-delay changed to %v by %v", settings.as3PostDelay, source)
+	}
+	if settings.ciphers != lc.current.ciphers || settings.cipherGroup != lc.current.cipherGroup {
+		if lc.setCiphers == nil {
+			log.Warningf("[INIT] ciphers and cipher-group changes in %v are not used in this mode, "+
+				"they are only used by the AS3 agent in ConfigMap/Ingress mode", source)
+		} else {
+			lc.setCiphers(settings.cipherGroup, settings.ciphers)
+			log.Infof("[INIT] ciphers changed to %v and cipher-group to %v by %v",
+				settings.ciphers, settings.cipherGroup, source)
+		}
+	}
+	if settings.defaultRouteDomain != lc.current.defaultRouteDomain && lc.setDefaultRouteDomain != nil {
+		lc.setDefaultRouteDomain(settings.defaultRouteDomain)
+		log.Infof("[INIT] default-route-domain changed to %v by %v", settings.defaultRouteDomain, source)
+	}
+	lc.current = settings
+}
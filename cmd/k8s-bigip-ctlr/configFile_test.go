@@ -0,0 +1,140 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Config File Tests", func() {
+	var cfgFile string
+
+	writeConfig := func(data string) {
+		Expect(ioutil.WriteFile(cfgFile, []byte(data), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		file, err := ioutil.TempFile("", "cis-config")
+		Expect(err).ToNot(HaveOccurred())
+		file.Close()
+		cfgFile = file.Name()
+	})
+
+	AfterEach(func() {
+		os.Remove(cfgFile)
+		_init()
+		log.SetLogLevel(log.LL_DEBUG)
+	})
+
+	It("Sets the options from the config file with the command line overriding", func() {
+		writeConfig(`
+global:
+  log-level: debug
+  default-route-domain: 2
+bigip:
+  bigip-url: bigip.example.com
+  bigip-username: admin
+  bigip-partition: [velcro1, velcro2]
+  as3-post-delay: 5
+kubernetes:
+  namespace:
+  - ns1
+  - ns2
+`)
+		os.Args = []string{
+			"./bin/k8s-bigip-ctlr",
+			"--config=" + cfgFile,
+			"--bigip-username=override",
+			"--as3-post-delay=10",
+		}
+		flags.Parse(os.Args)
+		_, err := loadConfigFile(*configFilePath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*logLevel).To(Equal("debug"))
+		Expect(*defaultRouteDomain).To(Equal(2))
+		Expect(*bigIPURL).To(Equal("bigip.example.com"))
+		Expect(*bigIPUsername).To(Equal("override"))
+		Expect(*bigIPPartitions).To(Equal([]string{"velcro1", "velcro2"}))
+		Expect(*as3PostDelay).To(Equal(10))
+		Expect(*namespaces).To(Equal([]string{"ns1", "ns2"}))
+	})
+
+	It("Rejects unknown sections, options and invalid values", func() {
+		flags.Parse([]string{"./bin/k8s-bigip-ctlr"})
+		writeConfig("unknown:\n  log-level: DEBUG\n")
+		_, err := loadConfigFile(cfgFile)
+		Expect(err).To(HaveOccurred())
+
+		writeConfig("global:\n  bigip-url: bigip.example.com\n")
+		_, err = loadConfigFile(cfgFile)
+		Expect(err).To(HaveOccurred(), "Option in the wrong section accepted")
+
+		writeConfig("bigip:\n  as3-post-delay: soon\n")
+		_, err = loadConfigFile(cfgFile)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Applies the live settings when the config file changes", func() {
+		writeConfig(`
+global:
+  log-level: INFO
+bigip:
+  as3-post-delay: 5
+  ciphers: DEFAULT
+kubernetes:
+  namespace: [ns1]
+`)
+		os.Args = []string{"./bin/k8s-bigip-ctlr", "--default-route-domain=3"}
+		flags.Parse(os.Args)
+		lc, err := loadConfigFile(cfgFile)
+		Expect(err).ToNot(HaveOccurred())
+		log.SetLogLevel(log.LL_INFO)
+
+		var updatedNamespaces []string
+		postDelay, routeDomain := -1, -1
+		var ciphers string
+		lc.logConfig = newRuntimeLogConfig("", false, nil)
+		lc.setNamespaces = func(namespaces []string) error {
+			updatedNamespaces = namespaces
+			return nil
+		}
+		lc.setPostDelay = func(delay int) { postDelay = delay }
+		lc.setCiphers = func(cipherGroup, c string) { ciphers = c }
+		lc.setDefaultRouteDomain = func(rd int) { routeDomain = rd }
+
+		// Unchanged file is not applied
+		lc.reload()
+		Expect(postDelay).To(Equal(-1))
+
+		writeConfig(`
+global:
+  log-level: ERROR
+  default-route-domain: 7
+bigip:
+  as3-post-delay: 1
+  ciphers: ECDHE
+kubernetes:
+  namespace: [ns1, ns2]
+`)
+		lc.reload()
+		Expect(log.GetLogLevel()).To(Equal(log.LogLevel(log.LL_ERROR)))
+		Expect(updatedNamespaces).To(Equal([]string{"ns1", "ns2"}))
+		Expect(postDelay).To(Equal(1))
+		Expect(ciphers).To(Equal("ECDHE"))
+		Expect(routeDomain).To(Equal(-1), "Option set on the command line changed by the config file")
+
+		// Removed options return to their default
+		writeConfig("kubernetes:\n  namespace: [ns1, ns2]\n")
+		lc.reload()
+		Expect(log.GetLogLevel()).To(Equal(log.LogLevel(log.LL_INFO)))
+		Expect(postDelay).To(Equal(0))
+
+		// Invalid file keeps the current settings
+		writeConfig("kubernetes:\n  namespace: {ns1: true}\n")
+		lc.reload()
+		Expect(updatedNamespaces).To(Equal([]string{"ns1", "ns2"}))
+	})
+})
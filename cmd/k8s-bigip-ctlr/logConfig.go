@@ -0,0 +1,200 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	logConfigPath          = "/log-config"
+	logConfigCheckInterval = 10 * time.Second
+
+	// Keys of the log config ConfigMap
+	logLevelKey       = "log-level"
+	logAS3ResponseKey = "log-as3-response"
+)
+
+type (
+	// runtimeLogConfig changes the log level and AS3 response logging without restarting CIS
+	runtimeLogConfig struct {
+		sync.Mutex
+		// tokenFile holds the bearer token authenticating requests to the HTTP endpoint
+		tokenFile      string
+		logAS3Response bool
+		setLogResponse func(bool)
+		// cfgMapSettings holds the settings last applied from the ConfigMap
+		cfgMapSettings *logSettings
+	}
+
+	logSettings struct {
+		LogLevel       string `json:"logLevel,omitempty"`
+		LogAS3Response *bool  `json:"logAS3Response,omitempty"`
+	}
+)
+
+// startRuntimeLogConfig serves the log config endpoint and watches the log config ConfigMap
+func startRuntimeLogConfig(setLogResponse func(bool), stopCh <-chan struct{}) *runtimeLogConfig {
+	lc := newRuntimeLogConfig(*logConfigToken, *logAS3Response, setLogResponse)
+	if len(*logConfigToken) > 0 {
+		http.Handle(logConfigPath, lc)
+	}
+	if namespaceCfgmapSlice := strings.Split(*logConfigCfgmap, "/"); len(namespaceCfgmapSlice) == 2 {
+		go wait.Until(func() {
+			lc.checkConfigMap(namespaceCfgmapSlice[0], namespaceCfgmapSlice[1])
+		}, logConfigCheckInterval, stopCh)
+	}
+	return lc
+}
+
+func newRuntimeLogConfig(tokenFile string, logAS3Response bool, setLogResponse func(bool)) *runtimeLogConfig {
+	return &runtimeLogConfig{
+		tokenFile:      tokenFile,
+		logAS3Response: logAS3Response,
+		setLogResponse: setLogResponse,
+	}
+}
+
+func (lc *runtimeLogConfig) current() logSettings {
+	lc.Lock()
+	defer lc.Unlock()
+	logAS3Response := lc.logAS3Response
+	return logSettings{
+		LogLevel:       strings.ToUpper(log.GetLogLevel().String()),
+		LogAS3Response: &logAS3Response,
+	}
+}
+
+// apply changes the settings which are set
+func (lc *runtimeLogConfig) apply(settings logSettings, source string) error {
+	var level *log.LogLevel
+	if settings.LogLevel != "" {
+		if level = log.NewLogLevel(settings.LogLevel); level == nil {
+			return fmt.Errorf("unknown log level %v, valid log levels are: DEBUG, INFO, WARNING, ERROR, CRITICAL",
+				settings.LogLevel)
+		}
+	}
+	lc.Lock()
+	defer lc.Unlock()
+	if level != nil && *level != log.GetLogLevel() {
+		log.SetLogLevel(*level)
+		log.Infof("[INIT] Log level changed to %v by %v", strings.ToUpper(level.String()), source)
+	}
+	if settings.LogAS3Response != nil && *settings.LogAS3Response != lc.logAS3Response {
+		lc.logAS3Response = *settings.LogAS3Response
+		if lc.setLogResponse != nil {
+			lc.setLogResponse(lc.logAS3Response)
+		}
+		log.Infof("[INIT] AS3 response logging set to %v by %v", lc.logAS3Response, source)
+	}
+	return nil
+}
+
+func (lc *runtimeLogConfig) authorized(r *http.Request) bool {
+	token, err := ioutil.ReadFile(lc.tokenFile)
+	if err != nil {
+		log.Errorf("[INIT] Unable to read log config token file %v: %v", lc.tokenFile, err)
+		return false
+	}
+	expected := strings.TrimSpace(string(token))
+	if expected == "" {
+		return false
+	}
+	provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) == 1
+}
+
+// ServeHTTP returns the log settings on GET and changes them on PUT or POST
+func (lc *runtimeLogConfig) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !lc.authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var settings logSettings
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := lc.apply(settings, "HTTP request from "+r.RemoteAddr); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lc.current())
+}
+
+// checkConfigMap applies the settings of the ConfigMap when they change.
+// Settings changed through the HTTP endpoint are kept until the ConfigMap changes.
+func (lc *runtimeLogConfig) checkConfigMap(namespace, name string) {
+	var data map[string]string
+	cm, err := getConfigMapUsingNamespaceAndName(namespace, name)
+	if err == nil {
+		data = cm.Data
+	} else if !errors.IsNotFound(err) {
+		log.Debugf("[INIT] Unable to fetch log config ConfigMap %v/%v: %v", namespace, name, err)
+		return
+	}
+	settings := logSettings{LogLevel: strings.TrimSpace(data[logLevelKey])}
+	if value, ok := data[logAS3ResponseKey]; ok {
+		logAS3Response, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			log.Errorf("[INIT] Invalid value %v for %v in ConfigMap %v/%v", value, logAS3ResponseKey, namespace, name)
+		} else {
+			settings.LogAS3Response = &logAS3Response
+		}
+	}
+
+	lc.Lock()
+	changed := lc.cfgMapSettings == nil || !settings.equal(*lc.cfgMapSettings)
+	lc.cfgMapSettings = &settings
+	lc.Unlock()
+	if !changed {
+		return
+	}
+	if err := lc.apply(settings, "ConfigMap "+namespace+"/"+name); err != nil {
+		log.Errorf("[INIT] Invalid log config in ConfigMap %v/%v: %v", namespace, name, err)
+	}
+}
+
+func (settings logSettings) equal(other logSettings) bool {
+	if settings.LogLevel != other.LogLevel {
+		return false
+	}
+	if settings.LogAS3Response == nil || other.LogAS3Response == nil {
+		return settings.LogAS3Response == other.LogAS3Response
+	}
+	return *settings.LogAS3Response == *other.LogAS3Response
+}
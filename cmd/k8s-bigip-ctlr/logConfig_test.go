@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Runtime Log Config Tests", func() {
+	var lc *runtimeLogConfig
+	var logResponse bool
+	var tokenFile string
+
+	BeforeEach(func() {
+		logResponse = false
+		file, err := ioutil.TempFile("", "log-config-token")
+		Expect(err).ToNot(HaveOccurred())
+		file.WriteString("secret-token\n")
+		file.Close()
+		tokenFile = file.Name()
+		lc = newRuntimeLogConfig(tokenFile, false, func(enabled bool) { logResponse = enabled })
+		log.SetLogLevel(log.LL_INFO)
+	})
+
+	AfterEach(func() {
+		os.Remove(tokenFile)
+		log.SetLogLevel(log.LL_DEBUG)
+	})
+
+	request := func(method, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, logConfigPath, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		lc.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("Changes log settings through the HTTP endpoint", func() {
+		Expect(request(http.MethodGet, "", "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(request(http.MethodPut, "wrong", `{"logLevel":"DEBUG"}`).Code).To(Equal(http.StatusUnauthorized))
+		Expect(log.GetLogLevel()).To(Equal(log.LogLevel(log.LL_INFO)))
+
+		rec := request(http.MethodPut, "secret-token", `{"logLevel":"debug","logAS3Response":true}`)
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(log.GetLogLevel()).To(Equal(log.LogLevel(log.LL_DEBUG)))
+		Expect(logResponse).To(BeTrue())
+		var settings logSettings
+		Expect(json.Unmarshal(rec.Body.Bytes(), &settings)).To(Succeed())
+		Expect(settings.LogLevel).To(Equal("DEBUG"))
+		Expect(*settings.LogAS3Response).To(BeTrue())
+
+		Expect(request(http.MethodPut, "secret-token", `{"logLevel":"verbose"}`).Code).To(Equal(http.StatusBadRequest))
+		Expect(request(http.MethodDelete, "secret-token", "").Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(request(http.MethodGet, "secret-token", "").Code).To(Equal(http.StatusOK))
+	})
+
+	It("Applies log settings from the ConfigMap when they change", func() {
+		defer func() { kubeClient = nil }()
+		cm := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cis-log-config", Namespace: "kube-system"},
+			Data:       map[string]string{logLevelKey: "ERROR", logAS3ResponseKey: "true"},
+		}
+		kubeClient = fake.NewSimpleClientset(cm)
+		lc.checkConfigMap("kube-system", "cis-log-config")
+		Expect(log.GetLogLevel()).To(Equal(log.LogLevel(log.LL_ERROR)))
+		Expect(logResponse).To(BeTrue())
+
+		// Unchanged ConfigMap keeps the settings changed through the HTTP endpoint
+		Expect(request(http.MethodPost, "secret-token", `{"logLevel":"INFO"}`).Code).To(Equal(http.StatusOK))
+		lc.checkConfigMap("kube-system", "cis-log-config")
+		Expect(log.GetLogLevel()).To(Equal(log.LogLevel(log.LL_INFO)))
+
+		cm.Data[logAS3ResponseKey] = "false"
+		kubeClient = fake.NewSimpleClientset(cm)
+		lc.checkConfigMap("kube-system", "cis-log-config")
+		Expect(log.GetLogLevel()).To(Equal(log.LogLevel(log.LL_ERROR)))
+		Expect(logResponse).To(BeFalse())
+	})
+})
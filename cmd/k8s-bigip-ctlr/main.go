@@ -25,6 +25,7 @@ import (
 
 	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/teem"
 
+	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/bigiptls"
 	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/controller"
 	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/health"
 	bigIPPrometheus "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/prometheus"
@@ -119,10 +120,15 @@ var (
 	controllerMode     *string
 	defaultRouteDomain *int
 
+	configFilePath   *string
 	pythonBaseDir    *string
 	logLevel         *string
 	ccclLogLevel     *string
 	logFile          *string
+	logFormat        *string
+	logRateLimit     *int
+	logConfigToken   *string
+	logConfigCfgmap  *string
 	verifyInterval   *int
 	nodePollInterval *int
 	syncInterval     *int
@@ -130,6 +136,9 @@ var (
 	httpAddress      *string
 	dgPath           string
 	disableTeems     *bool
+	debugAPI         *bool
+	otlpEndpoint     *string
+	otlpInsecure     *bool
 	enableIPV6       *bool
 
 	namespaces             *[]string
@@ -156,6 +165,12 @@ var (
 	bigIPPassword             *string
 	bigIPPartitions           *[]string
 	credsDir                  *string
+	credsSecret               *string
+	credsRefreshInterval      *int
+	vaultAddress              *string
+	vaultSecretPath           *string
+	vaultTokenFile            *string
+	vaultCAFile               *string
 	as3Validation             *bool
 	sslInsecure               *bool
 	ipam                      *bool
@@ -163,7 +178,23 @@ var (
 	tls13CipherGroupReference *string
 	ciphers                   *string
 	trustedCerts              *string
+	clientCertFile            *string
+	clientKeyFile             *string
+	clientCertSecret          *string
+	bigIPServerName           *string
 	as3PostDelay              *int
+	as3PostConcurrency        *int
+	as3BatchQuietPeriod       *int
+	as3BatchMaxDelay          *int
+	as3RetryMaxInterval       *int
+	circuitBreakerThreshold   *int
+	circuitBreakerInterval    *int
+	maxVSDeletePercent        *int
+	maxVSDeleteCount          *int
+	deletionGuardCfgmap       *string
+	pauseCfgmap               *string
+	declSnapshotCfgmap        *string
+	bigIPTargetsFile          *string
 
 	trustedCertsCfgmap     *string
 	agent                  *string
@@ -190,6 +221,8 @@ var (
 	gtmBigIPUsername *string
 	gtmBigIPPassword *string
 	gtmCredsDir      *string
+	gtmCredsSecret   *string
+	gtmVaultPath     *string
 
 	// package variables
 	isNodePort         bool
@@ -225,12 +258,28 @@ func _init() {
 	// Global flags
 	pythonBaseDir = globalFlags.String("python-basedir", "",
 		"DEPRECATED: Optional, directory location of python utilities")
+	configFilePath = globalFlags.String("config", "",
+		"Optional, YAML file with the options of CIS in the global, bigip, kubernetes, vxlan, routes and gtm "+
+			"sections. Options set on the command line override the file. Changes to namespace, log-level, "+
+			"as3-post-delay, ciphers, cipher-group and default-route-domain are applied without restarting CIS. "+
+			"ciphers and cipher-group are only used in ConfigMap/Ingress mode")
 	logLevel = globalFlags.String("log-level", "INFO",
 		"Optional, logging level")
 	ccclLogLevel = globalFlags.String("cccl-log-level", "",
 		"Optional, logging level for cccl")
 	logFile = globalFlags.String("log-file", "",
 		"Optional, filepath to store the CIS logs")
+	logFormat = globalFlags.String("log-format", "text",
+		"Optional, format of the CIS logs, text or json. "+
+			"json logs each message on a single line with its component, namespace, resource kind and name, tenant and request id")
+	logRateLimit = globalFlags.Int("log-rate-limit-interval", 0,
+		"Optional, interval (in seconds) within which identical repeated log messages are suppressed. 0 disables the rate limiting")
+	logConfigToken = globalFlags.String("log-config-token-file", "",
+		"Optional, file with the bearer token authenticating requests to change the log level and AS3 response logging "+
+			"at runtime on "+logConfigPath+" of the http-listen-address")
+	logConfigCfgmap = globalFlags.String("log-config-cfgmap", "",
+		"Optional, namespace/name of a ConfigMap with log-level and log-as3-response keys, watched to change the "+
+			"log level and AS3 response logging at runtime")
 	verifyInterval = globalFlags.Int("verify-interval", 30,
 		"Optional, interval (in seconds) at which to verify the BIG-IP configuration.")
 	nodePollInterval = globalFlags.Int("node-poll-interval", 30,
@@ -243,6 +292,14 @@ func _init() {
 		"Optional, address to serve http based informations (/metrics and /health).")
 	disableTeems = globalFlags.Bool("disable-teems", false,
 		"Optional, flag to disable sending telemetry data to TEEM")
+	debugAPI = globalFlags.Bool("debug-api", false,
+		"Optional, serve read-only controller state under /debug on the http-listen-address. "+
+			"Only supported in custom resource and controller modes.")
+	otlpEndpoint = globalFlags.String("otlp-endpoint", "",
+		"Optional, host:port of the OTLP/HTTP collector to export traces of the resource processing and AS3 posts. "+
+			"Only supported in custom resource and controller modes.")
+	otlpInsecure = globalFlags.Bool("otlp-insecure", false,
+		"Optional, export traces to the OTLP/HTTP collector without TLS.")
 	// Custom Resource
 	enableIPV6 = globalFlags.Bool("enable-ipv6", false,
 		"Optional, flag to enbale ipv6 network support.")
@@ -269,6 +326,27 @@ func _init() {
 	credsDir = bigIPFlags.String("credentials-directory", "",
 		"Optional, directory that contains the BIG-IP username, password, and/or "+
 			"url files. To be used instead of username, password, and/or url arguments.")
+	credsSecret = bigIPFlags.String("credentials-secret", "",
+		"Optional, Kubernetes Secret in the form namespace/name with the BIG-IP username, password, "+
+			"and/or url keys. To be used instead of username, password, and/or url arguments. "+
+			"Custom resource and controller modes only.")
+	credsRefreshInterval = bigIPFlags.Int("credentials-refresh-interval", 30,
+		"Optional, interval (in seconds) at which BIG-IP and GTM credentials are refreshed "+
+			"from their credentials directory, secret or vault. Set to 0 to disable. "+
+			"Custom resource and controller modes only, other modes read the credentials directory at startup.")
+	bigIPTargetsFile = bigIPFlags.String("bigip-targets", "",
+		"Optional, YAML file listing additional BIG-IPs, each configured with the VirtualServers and "+
+			"TransportServers which reference it with bigipRef. Custom resource and controller modes only.")
+	vaultAddress = bigIPFlags.String("vault-address", "",
+		"Optional, address of the Vault compatible secret store, e.g. https://vault:8200")
+	vaultSecretPath = bigIPFlags.String("vault-secret-path", "",
+		"Optional, path of the vault secret with the BIG-IP username, password, and/or url keys, "+
+			"e.g. secret/data/bigip. To be used instead of username, password, and/or url arguments. "+
+			"Custom resource and controller modes only.")
+	vaultTokenFile = bigIPFlags.String("vault-token-file", "",
+		"Optional, file containing the token used to authenticate to the vault.")
+	vaultCAFile = bigIPFlags.String("vault-ca-file", "",
+		"Optional, file containing CA certificates used to verify the vault server certificate.")
 	as3Validation = bigIPFlags.Bool("as3-validation", true,
 		"Optional, when set to false, disables as3 template validation on the controller.")
 	sslInsecure = bigIPFlags.Bool("insecure", false,
@@ -277,6 +355,43 @@ func _init() {
 		"Optional, when set to true, enable ipam feature for CRD.")
 	as3PostDelay = bigIPFlags.Int("as3-post-delay", 0,
 		"Optional, time (in seconds) that CIS waits to post the available AS3 declaration.")
+	as3PostConcurrency = bigIPFlags.Int("as3-post-concurrency", 1,
+		"Optional, maximum number of tenant declarations posted to BIG-IP at the same time. "+
+			"Each tenant is retried and polled independently of the other tenants. "+
+			"AS3 processes one declaration at a time and responds to the others with 503.")
+	as3BatchQuietPeriod = bigIPFlags.Int("as3-batch-quiet-period", 0,
+		"Optional, time (in milliseconds) without Kubernetes changes that CIS waits for before building the AS3 declaration, "+
+			"coalescing the changes in between. Set to 0 to disable batching.")
+	as3BatchMaxDelay = bigIPFlags.Int("as3-batch-max-delay", 10,
+		"Optional, maximum time (in seconds) that CIS waits for the as3-batch-quiet-period after the first change. "+
+			"Set to 0 to wait for the quiet period without limit.")
+	as3RetryMaxInterval = bigIPFlags.Int("as3-retry-max-interval", 300,
+		"Optional, maximum time (in seconds) between the retries of a failed AS3 declaration. "+
+			"Retries start after 30 seconds and back off exponentially.")
+	circuitBreakerThreshold = bigIPFlags.Int("bigip-circuit-breaker-threshold", 5,
+		"Optional, number of consecutive requests finding the BIG-IP unreachable or unavailable (503) after which "+
+			"CIS pauses the requests to the BIG-IP. 503 responses of AS3 busy with another declaration are not counted. "+
+			"Set to 0 to disable.")
+	circuitBreakerInterval = bigIPFlags.Int("bigip-circuit-breaker-interval", 60,
+		"Optional, time (in seconds) that CIS pauses the requests to an unreachable or unavailable BIG-IP "+
+			"before probing it again.")
+	maxVSDeletePercent = bigIPFlags.Int("max-vs-deletion-percent", 0,
+		"Optional, maximum percentage of virtual servers of a tenant that can be removed by a single declaration. "+
+			"Declarations removing more are held until allowed through the deletion-guard-cfgmap. Set to 0 to disable.")
+	maxVSDeleteCount = bigIPFlags.Int("max-vs-deletion-count", 0,
+		"Optional, maximum number of virtual servers of a tenant that can be removed by a single declaration. "+
+			"Declarations removing more are held until allowed through the deletion-guard-cfgmap. Set to 0 to disable.")
+	deletionGuardCfgmap = bigIPFlags.String("deletion-guard-cfgmap", "",
+		"Optional, ConfigMap in the form namespace/name on which the annotation "+
+			"cis.f5.com/allow-mass-deletion allows held declarations to proceed. The annotation value is "+
+			"either true or an RFC3339 timestamp until which deletions are allowed.")
+	pauseCfgmap = bigIPFlags.String("pause-cfgmap", "",
+		"Optional, ConfigMap in the form namespace/name to pause reconciliation. Its data keys are "+
+			"paused (true to pause the controller), partitions and namespaces (comma separated lists to pause). "+
+			"Resources and namespaces are also paused with the cis.f5.com/paused annotation.")
+	declSnapshotCfgmap = bigIPFlags.String("declaration-snapshot-cfgmap", "",
+		"Optional, ConfigMap in the form namespace/name persisting the tenant declarations applied to BIG-IP. "+
+			"On restart the tenants whose declaration is unchanged are not posted again.")
 	logAS3Response = bigIPFlags.Bool("log-as3-response", false,
 		"Optional, when set to true, add the body of AS3 API response in Controller logs.")
 	shareNodes = bigIPFlags.Bool("share-nodes", false,
@@ -288,6 +403,16 @@ func _init() {
 	ciphers = bigIPFlags.String("ciphers", "DEFAULT", "Optional, Configures a ciphersuite selection string. cipher-group and ciphers are mutually exclusive, only use one.")
 	trustedCertsCfgmap = bigIPFlags.String("trusted-certs-cfgmap", "",
 		"Optional, when certificates are provided, adds them to controller'trusted certificate store.")
+	clientCertFile = bigIPFlags.String("bigip-client-cert", "",
+		"Optional, file containing the PEM encoded client certificate presented to the BIG-IP management API.")
+	clientKeyFile = bigIPFlags.String("bigip-client-key", "",
+		"Optional, file containing the PEM encoded private key of the bigip-client-cert.")
+	clientCertSecret = bigIPFlags.String("bigip-client-cert-secret", "",
+		"Optional, TLS Secret in the form namespace/name with the client certificate and key "+
+			"presented to the BIG-IP management API. To be used instead of bigip-client-cert and bigip-client-key.")
+	bigIPServerName = bigIPFlags.String("bigip-server-name", "",
+		"Optional, name verified against the SAN of the BIG-IP management certificate. "+
+			"When set, the BIG-IP certificate is always verified.")
 	// TODO: Rephrase agent functionality
 	agent = bigIPFlags.String("agent", "as3",
 		"Optional, when set to cccl, orchestration agent will be CCCL instead of AS3")
@@ -414,6 +539,11 @@ func _init() {
 	gtmCredsDir = gtmBigIPFlags.String("gtm-credentials-directory", "",
 		"Optional, directory that contains the GTM BIG-IP username, password, and/or "+
 			"url files. To be used instead of username, password, and/or url arguments.")
+	gtmCredsSecret = gtmBigIPFlags.String("gtm-credentials-secret", "",
+		"Optional, Kubernetes Secret in the form namespace/name with the GTM BIG-IP username, password, "+
+			"and/or url keys. To be used instead of username, password, and/or url arguments.")
+	gtmVaultPath = gtmBigIPFlags.String("gtm-vault-secret-path", "",
+		"Optional, path of the vault secret with the GTM BIG-IP username, password, and/or url keys.")
 	gtmBigIPFlags.Usage = func() {
 		fmt.Fprintf(os.Stderr, "  GTM:\n%s\n", gtmBigIPFlags.FlagUsagesWrapped(width))
 	}
@@ -436,13 +566,27 @@ func _init() {
 	}
 }
 
-func initLogger(logLevel, logFile string) error {
+func initLogger(logLevel, logFile, logFormat string, rateLimitInterval int) error {
 	var logger log.Logger
 	if len(logFile) > 0 {
 		logger = log.NewFileLogger(logFile)
 	} else {
 		logger = log.NewConsoleLogger()
 	}
+	switch strings.ToLower(logFormat) {
+	case "text":
+	case "json":
+		// FileLogger redirects stdout to the log file
+		logger = log.NewJSONLogger(os.Stdout)
+	default:
+		return fmt.Errorf("Unknown log format requested: %s\n"+
+			"    Valid log formats are: text, json", logFormat)
+	}
+	if rateLimitInterval > 0 {
+		logger = log.NewRateLimitedLogger(logger, time.Duration(rateLimitInterval)*time.Second)
+	} else if rateLimitInterval < 0 {
+		return fmt.Errorf("invalid log rate limit interval %v", rateLimitInterval)
+	}
 	log.RegisterLogger(
 		log.LL_MIN_LEVEL, log.LL_MAX_LEVEL, logger)
 
@@ -471,7 +615,7 @@ func hasCommonPartition(partitions []string) bool {
 
 func verifyArgs() error {
 	*logLevel = strings.ToUpper(*logLevel)
-	logErr := initLogger(*logLevel, *logFile)
+	logErr := initLogger(*logLevel, *logFile, *logFormat, *logRateLimit)
 	if nil != logErr {
 		return logErr
 	}
@@ -490,10 +634,67 @@ func verifyArgs() error {
 	}
 
 	if (len(*bigIPURL) == 0 || len(*bigIPUsername) == 0 ||
-		len(*bigIPPassword) == 0) && len(*credsDir) == 0 {
+		len(*bigIPPassword) == 0) && len(*credsDir) == 0 &&
+		len(*credsSecret) == 0 && len(*vaultSecretPath) == 0 {
 		return fmt.Errorf("Missing BIG-IP credentials info")
 	}
 
+	if countNonEmpty(*credsDir, *credsSecret, *vaultSecretPath) > 1 ||
+		countNonEmpty(*gtmCredsDir, *gtmCredsSecret, *gtmVaultPath) > 1 {
+		return fmt.Errorf("Only one of credentials-directory, credentials-secret and vault-secret-path " +
+			"can be specified for a BIG-IP")
+	}
+
+	// The credentials are refreshed by the agent of the custom resource and controller modes only
+	if !*customResourceMode && *controllerMode == "" &&
+		(len(*credsSecret) > 0 || len(*vaultSecretPath) > 0 || flags.Changed("credentials-refresh-interval")) {
+		return fmt.Errorf("credentials-secret, vault-secret-path and credentials-refresh-interval " +
+			"are only supported in custom resource and controller modes")
+	}
+
+	if (*maxVSDeletePercent > 0 || *maxVSDeleteCount > 0) && len(strings.Split(*deletionGuardCfgmap, "/")) != 2 {
+		return fmt.Errorf("deletion-guard-cfgmap in the form namespace/name is required with " +
+			"max-vs-deletion-percent and max-vs-deletion-count")
+	}
+
+	if *as3PostConcurrency < 1 {
+		return fmt.Errorf("as3-post-concurrency must be at least 1")
+	}
+
+	if *as3BatchQuietPeriod < 0 || *as3BatchMaxDelay < 0 {
+		return fmt.Errorf("as3-batch-quiet-period and as3-batch-max-delay cannot be negative")
+	}
+
+	if *as3RetryMaxInterval < 30 {
+		return fmt.Errorf("as3-retry-max-interval must be at least 30 seconds")
+	}
+
+	if *circuitBreakerThreshold < 0 || *circuitBreakerInterval < 1 {
+		return fmt.Errorf("bigip-circuit-breaker-threshold cannot be negative and " +
+			"bigip-circuit-breaker-interval must be at least 1 second")
+	}
+
+	if len(*declSnapshotCfgmap) > 0 && len(strings.Split(*declSnapshotCfgmap, "/")) != 2 {
+		return fmt.Errorf("declaration-snapshot-cfgmap must be in the form namespace/name")
+	}
+
+	if len(*logConfigCfgmap) > 0 && len(strings.Split(*logConfigCfgmap, "/")) != 2 {
+		return fmt.Errorf("log-config-cfgmap must be in the form namespace/name")
+	}
+
+	if (len(*clientCertFile) == 0) != (len(*clientKeyFile) == 0) {
+		return fmt.Errorf("Both bigip-client-cert and bigip-client-key must be specified")
+	}
+
+	if len(*clientCertFile) > 0 && len(*clientCertSecret) > 0 {
+		return fmt.Errorf("Can not specify both bigip-client-cert and bigip-client-cert-secret")
+	}
+
+	if (len(*vaultSecretPath) > 0 || len(*gtmVaultPath) > 0) &&
+		(len(*vaultAddress) == 0 || len(*vaultTokenFile) == 0) {
+		return fmt.Errorf("vault-address and vault-token-file are required to read credentials from vault")
+	}
+
 	if len(*namespaces) != 0 && len(*namespaceLabel) != 0 {
 		return fmt.Errorf("Can not specify both namespace and namespace-label")
 	}
@@ -613,11 +814,15 @@ func getCredentials() error {
 			return err
 		}
 	}
-	// Verify URL is valid
-	if !strings.HasPrefix(*bigIPURL, "https://") {
-		*bigIPURL = "https://" + *bigIPURL
+	return verifyBigIPURL(bigIPURL)
+}
+
+// verifyBigIPURL prefixes the https scheme and verifies the URL has no path
+func verifyBigIPURL(bigipURL *string) error {
+	if !strings.HasPrefix(*bigipURL, "https://") {
+		*bigipURL = "https://" + *bigipURL
 	}
-	u, err := url.Parse(*bigIPURL)
+	u, err := url.Parse(*bigipURL)
 	if nil != err {
 		return fmt.Errorf("Error parsing url: %s", err)
 	}
@@ -628,6 +833,59 @@ func getCredentials() error {
 	return nil
 }
 
+func countNonEmpty(values ...string) int {
+	count := 0
+	for _, value := range values {
+		if len(value) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// getCredentialProvider returns the provider used to fetch and refresh BIG-IP credentials
+func getCredentialProvider(dir, secret, vaultPath string) (controller.CredentialProvider, error) {
+	switch {
+	case len(secret) > 0:
+		parts := strings.Split(secret, "/")
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return nil, fmt.Errorf("Invalid credentials secret %v, expected namespace/name", secret)
+		}
+		return controller.NewSecretCredentialProvider(kubeClient, parts[0], parts[1]), nil
+	case len(vaultPath) > 0:
+		return controller.NewVaultCredentialProvider(*vaultAddress, vaultPath, *vaultTokenFile, *vaultCAFile)
+	case len(dir) > 0:
+		return controller.NewFileCredentialProvider(dir), nil
+	}
+	return nil, nil
+}
+
+// setProviderCredentials overrides the CLI arguments with the credentials from the provider
+func setProviderCredentials(provider controller.CredentialProvider, username, password, bigipURL *string) error {
+	creds, _, err := provider.Credentials()
+	if err != nil {
+		return fmt.Errorf("Unable to fetch credentials from %v: %v", provider.Name(), err)
+	}
+	setField := func(field *string, value, fieldType string) error {
+		if len(value) > 0 {
+			*field = value
+		} else if len(*field) == 0 {
+			return fmt.Errorf("%s not specified in %v", fieldType, provider.Name())
+		}
+		return nil
+	}
+	if err = setField(username, creds.Username, "username"); err != nil {
+		return err
+	}
+	if err = setField(password, creds.Password, "password"); err != nil {
+		return err
+	}
+	if err = setField(bigipURL, creds.URL, "url"); err != nil {
+		return err
+	}
+	return verifyBigIPURL(bigipURL)
+}
+
 func getGTMCredentials() {
 	if len(*gtmCredsDir) > 0 {
 		var usr, pass, gtmBigipURL string
@@ -748,16 +1006,29 @@ func setupWatchers(appMgr *appmanager.Manager, resyncPeriod time.Duration) {
 
 func initController(
 	config *rest.Config,
+	targets []*bigIPTarget,
 ) *controller.Controller {
 
+	clientCert, clientKey := getBIGIPClientCert()
 	postMgrParams := controller.PostParams{
-		BIGIPUsername: *bigIPUsername,
-		BIGIPPassword: *bigIPPassword,
-		BIGIPURL:      *bigIPURL,
-		TrustedCerts:  "",
-		SSLInsecure:   true,
-		AS3PostDelay:  *as3PostDelay,
-		LogResponse:   *logAS3Response,
+		BIGIPUsername:           *bigIPUsername,
+		BIGIPPassword:           *bigIPPassword,
+		BIGIPURL:                *bigIPURL,
+		TrustedCerts:            "",
+		SSLInsecure:             true,
+		ClientCert:              clientCert,
+		ClientKey:               clientKey,
+		ServerName:              *bigIPServerName,
+		AS3PostDelay:            *as3PostDelay,
+		LogResponse:             *logAS3Response,
+		PostConcurrency:         *as3PostConcurrency,
+		RetryMaxInterval:        time.Duration(*as3RetryMaxInterval) * time.Second,
+		CircuitBreakerThreshold: *circuitBreakerThreshold,
+		CircuitBreakerInterval:  time.Duration(*circuitBreakerInterval) * time.Second,
+	}
+	// BIG-IP certificate is verified against the trusted certificates when a server name is configured
+	if len(*bigIPServerName) > 0 && len(*trustedCertsCfgmap) > 0 {
+		postMgrParams.TrustedCerts = getBIGIPTrustedCerts()
 	}
 
 	GtmParams := controller.GTMParams{
@@ -778,6 +1049,16 @@ func initController(
 		HttpAddress:    *httpAddress,
 		EnableIPV6:     *enableIPV6,
 		CCCLGTMAgent:   *ccclGtmAgent,
+		DebugAPI:       *debugAPI,
+		DeletionGuard: controller.DeletionGuardParams{
+			MaxDeletePercent: *maxVSDeletePercent,
+			MaxDeleteCount:   *maxVSDeleteCount,
+			OverrideCfgMap:   *deletionGuardCfgmap,
+		},
+		Batch: controller.BatchParams{
+			QuietPeriod: time.Duration(*as3BatchQuietPeriod) * time.Millisecond,
+			MaxDelay:    time.Duration(*as3BatchMaxDelay) * time.Second,
+		},
 	}
 
 	// When CIS is configured in OCP cluster mode disable ARP in globalSection
@@ -786,26 +1067,31 @@ func initController(
 	}
 
 	agent := controller.NewAgent(agentParams)
+	bigIPTargets := newBigIPTargetAgents(targets, agentParams)
 
 	ctlr := controller.NewController(
 		controller.Params{
-			Config:             config,
-			Namespaces:         *namespaces,
-			NamespaceLabel:     *namespaceLabel,
-			Partition:          (*bigIPPartitions)[0],
-			Agent:              agent,
-			PoolMemberType:     *poolMemberType,
-			VXLANName:          vxlanName,
-			VXLANMode:          vxlanMode,
-			UseNodeInternal:    *useNodeInternal,
-			NodePollInterval:   *nodePollInterval,
-			NodeLabelSelector:  *nodeLabelSelector,
-			IPAM:               *ipam,
-			ShareNodes:         *shareNodes,
-			DefaultRouteDomain: *defaultRouteDomain,
-			Mode:               controller.ControllerMode(*controllerMode),
-			RouteSpecConfigmap: *routeSpecConfigmap,
-			RouteLabel:         *routeLabel,
+			Config:                    config,
+			Namespaces:                *namespaces,
+			NamespaceLabel:            *namespaceLabel,
+			Partition:                 (*bigIPPartitions)[0],
+			Agent:                     agent,
+			PoolMemberType:            *poolMemberType,
+			VXLANName:                 vxlanName,
+			VXLANMode:                 vxlanMode,
+			UseNodeInternal:           *useNodeInternal,
+			NodePollInterval:          *nodePollInterval,
+			NodeLabelSelector:         *nodeLabelSelector,
+			IPAM:                      *ipam,
+			ShareNodes:                *shareNodes,
+			DefaultRouteDomain:        *defaultRouteDomain,
+			Mode:                      controller.ControllerMode(*controllerMode),
+			RouteSpecConfigmap:        *routeSpecConfigmap,
+			RouteLabel:                *routeLabel,
+			PauseConfigmap:            *pauseCfgmap,
+			DebugAPI:                  *debugAPI,
+			BigIPTargets:              bigIPTargets,
+			DeclarationSnapshotCfgMap: *declSnapshotCfgmap,
 		},
 	)
 
@@ -841,6 +1127,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	var liveCfg *liveConfig
+	if len(*configFilePath) > 0 {
+		liveCfg, err = loadConfigFile(*configFilePath)
+		if nil != err {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	if *printVersion {
 		fmt.Printf("Version: %s\nBuild: %s\n", version, buildInfo)
 		os.Exit(0)
@@ -900,6 +1195,16 @@ func main() {
 		log.Fatalf("[INIT] error connecting to the client: %v", err)
 		os.Exit(1)
 	}
+	bigIPCredsProvider, err := getCredentialProvider(*credsDir, *credsSecret, *vaultSecretPath)
+	if err != nil {
+		log.Fatalf("[INIT] %v", err)
+	}
+	// Credentials directory has already been read along with the CLI arguments
+	if len(*credsSecret) > 0 || len(*vaultSecretPath) > 0 {
+		if err = setProviderCredentials(bigIPCredsProvider, bigIPUsername, bigIPPassword, bigIPURL); err != nil {
+			log.Fatalf("[INIT] %v", err)
+		}
+	}
 	userAgentInfo = getUserAgentInfo()
 	td := &teem.TeemsData{
 		CisVersion:      version,
@@ -937,7 +1242,30 @@ func main() {
 
 	if *customResourceMode || *controllerMode != "" {
 		getGTMCredentials()
-		ctlr := initController(config)
+		gtmCredsProvider, err := getCredentialProvider(*gtmCredsDir, *gtmCredsSecret, *gtmVaultPath)
+		if err != nil {
+			log.Errorf("%v", err)
+		} else if len(*gtmCredsSecret) > 0 || len(*gtmVaultPath) > 0 {
+			if err = setProviderCredentials(gtmCredsProvider, gtmBigIPUsername, gtmBigIPPassword, gtmBigIPURL); err != nil {
+				log.Errorf("%v", err)
+			}
+		}
+		if len(*otlpEndpoint) > 0 {
+			stopTracing, err := initTracing(*otlpEndpoint, *otlpInsecure)
+			if err != nil {
+				log.Errorf("[INIT] Unable to export traces to OTLP endpoint %v: %v", *otlpEndpoint, err)
+			} else {
+				defer stopTracing()
+			}
+		}
+		var targets []*bigIPTarget
+		if len(*bigIPTargetsFile) > 0 {
+			targets, err = loadBigIPTargets(*bigIPTargetsFile)
+			if err != nil {
+				log.Fatalf("[INIT] %v", err)
+			}
+		}
+		ctlr := initController(config, targets)
 		ctlr.TeemData = td
 		if !(*disableTeems) {
 			key, err := ctlr.Agent.GetBigipRegKey()
@@ -948,9 +1276,25 @@ func main() {
 			ctlr.TeemData.RegistrationKey = key
 			ctlr.TeemData.Unlock()
 		}
+		stopCh := make(chan struct{})
+		go ctlr.Agent.WatchCredentials(bigIPCredsProvider, gtmCredsProvider,
+			time.Duration(*credsRefreshInterval)*time.Second, stopCh)
+		for _, target := range targets {
+			go target.agent.WatchCredentials(target.credsProvider, nil,
+				time.Duration(*credsRefreshInterval)*time.Second, stopCh)
+		}
+		logCfg := startRuntimeLogConfig(ctlr.Agent.SetLogResponse, stopCh)
+		if liveCfg != nil {
+			liveCfg.logConfig = logCfg
+			liveCfg.setNamespaces = ctlr.UpdateNamespaces
+			liveCfg.setPostDelay = ctlr.Agent.SetPostDelay
+			liveCfg.setDefaultRouteDomain = ctlr.UpdateDefaultRouteDomain
+			go liveCfg.watch(stopCh)
+		}
 		sigs := make(chan os.Signal, 1)
 		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 		sig := <-sigs
+		close(stopCh)
 		ctlr.Stop()
 		log.Infof("Exiting - signal %v\n", sig)
 		return
@@ -1062,6 +1406,7 @@ func main() {
 		SubPID: subPid,
 	}
 	http.Handle("/health", hc.HealthCheckHandler())
+	http.Handle("/ready", hc.ReadinessHandler())
 	bigIPPrometheus.RegisterMetrics()
 	go func() {
 		log.Fatal(http.ListenAndServe(*httpAddress, nil).Error())
@@ -1069,6 +1414,21 @@ func main() {
 
 	stopCh := make(chan struct{})
 
+	var setLogResponse func(bool)
+	if as3Agent, ok := appMgr.AgentCIS.(interface{ SetLogResponse(bool) }); ok {
+		setLogResponse = as3Agent.SetLogResponse
+	}
+	logCfg := startRuntimeLogConfig(setLogResponse, stopCh)
+	if liveCfg != nil {
+		liveCfg.logConfig = logCfg
+		if as3Agent, ok := appMgr.AgentCIS.(liveConfigAgent); ok {
+			liveCfg.setPostDelay = as3Agent.SetPostDelay
+			liveCfg.setCiphers = as3Agent.SetCiphers
+			liveCfg.setDefaultRouteDomain = as3Agent.SetDefaultRouteDomain
+		}
+		go liveCfg.watch(stopCh)
+	}
+
 	appMgr.Run(stopCh)
 
 	sigs := make(chan os.Signal, 1)
@@ -1144,6 +1504,7 @@ func getAgentParams(agent string) interface{} {
 }
 
 func getAS3Params() *as3.Params {
+	clientCert, clientKey := getBIGIPClientCert()
 	return &as3.Params{
 		SchemaLocal:               *schemaLocal,
 		AS3Validation:             *as3Validation,
@@ -1157,6 +1518,9 @@ func getAS3Params() *as3.Params {
 		BIGIPURL:                  *bigIPURL,
 		TrustedCerts:              getBIGIPTrustedCerts(),
 		SSLInsecure:               *sslInsecure,
+		ClientCert:                clientCert,
+		ClientKey:                 clientKey,
+		ServerName:                *bigIPServerName,
 		IPAM:                      *ipam,
 		AS3PostDelay:              *as3PostDelay,
 		LogResponse:               *logAS3Response,
@@ -1223,6 +1587,46 @@ func getBIGIPTrustedCerts() string {
 	return certs
 }
 
+// Read and validate the client certificate and key for BIG-IP mutual TLS
+func getBIGIPClientCert() (string, string) {
+	cert, key := readBIGIPClientCert()
+	if len(cert) > 0 || len(key) > 0 {
+		if _, err := bigiptls.ValidateClientCert(cert, key); err != nil {
+			log.Fatalf("[INIT] %v", err)
+		}
+	}
+	return cert, key
+}
+
+// Read client certificate and key for BIG-IP mutual TLS from files or a TLS secret
+func readBIGIPClientCert() (string, string) {
+	if len(*clientCertFile) > 0 {
+		cert, err := ioutil.ReadFile(*clientCertFile)
+		if err != nil {
+			log.Fatalf("[INIT] Unable to read BIG-IP client certificate: %v", err)
+		}
+		key, err := ioutil.ReadFile(*clientKeyFile)
+		if err != nil {
+			log.Fatalf("[INIT] Unable to read BIG-IP client key: %v", err)
+		}
+		return string(cert), string(key)
+	}
+	if len(*clientCertSecret) == 0 {
+		return "", ""
+	}
+	namespaceSecretSlice := strings.Split(*clientCertSecret, "/")
+	if len(namespaceSecretSlice) != 2 {
+		log.Fatalf("[INIT] Invalid bigip-client-cert-secret option provided, expected namespace/name")
+	}
+	secret, err := kubeClient.CoreV1().Secrets(namespaceSecretSlice[0]).Get(
+		context.TODO(), namespaceSecretSlice[1], metav1.GetOptions{})
+	if err != nil {
+		log.Fatalf("[INIT] Secret with name %v not found in namespace: %v, error: %v",
+			namespaceSecretSlice[1], namespaceSecretSlice[0], err)
+	}
+	return string(secret.Data[v1.TLSCertKey]), string(secret.Data[v1.TLSPrivateKeyKey])
+}
+
 func getConfigMapUsingNamespaceAndName(cfgMapNamespace, cfgMapName string) (*v1.ConfigMap, error) {
 	cfgMap, err := kubeClient.CoreV1().ConfigMaps(cfgMapNamespace).Get(context.TODO(), cfgMapName, metav1.GetOptions{})
 	if err != nil {
@@ -34,6 +34,8 @@ import (
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gexec"
 
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -429,6 +431,55 @@ var _ = Describe("Main Tests", func() {
 
 		})
 
+		It("gets credentials from a secret", func() {
+			defer _init()
+			os.Args = []string{
+				"./bin/k8s-bigip-ctlr",
+				"--namespace=testing",
+				"--credentials-secret=kube-system/bigip-login",
+				"--bigip-partition=velcro1",
+				"--bigip-url=bigip.example.com",
+				"--pool-member-type=nodeport",
+			}
+			flags.Parse(os.Args)
+			err := verifyArgs()
+			Expect(err).ToNot(BeNil(), "Credentials secret should require custom resource mode.")
+
+			*customResourceMode = true
+			err = verifyArgs()
+			Expect(err).To(BeNil())
+			err = getCredentials()
+			Expect(err).ToNot(HaveOccurred())
+
+			kubeClient = fake.NewSimpleClientset(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "bigip-login", Namespace: "kube-system"},
+				Data: map[string][]byte{
+					"username": []byte("user"),
+					"password": []byte("pass"),
+				},
+			})
+			provider, err := getCredentialProvider(*credsDir, *credsSecret, *vaultSecretPath)
+			Expect(err).ToNot(HaveOccurred())
+			err = setProviderCredentials(provider, bigIPUsername, bigIPPassword, bigIPURL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*bigIPURL).To(Equal("https://bigip.example.com"))
+			Expect(*bigIPUsername).To(Equal("user"))
+			Expect(*bigIPPassword).To(Equal("pass"))
+
+			_, err = getCredentialProvider("", "bigip-login", "")
+			Expect(err).ToNot(BeNil(), "Secret should be specified as namespace/name.")
+
+			*credsDir = "/tmp/k8s-test-creds"
+			err = verifyArgs()
+			Expect(err).ToNot(BeNil(), "Only one credentials source should be allowed.")
+
+			*credsDir = ""
+			*vaultSecretPath = "secret/data/bigip"
+			*credsSecret = ""
+			err = verifyArgs()
+			Expect(err).ToNot(BeNil(), "Vault address and token file should be required.")
+		})
+
 		It("handles vxlan flags", func() {
 			defer _init()
 			os.Args = []string{
@@ -28,6 +28,7 @@ type VirtualServerStatus struct {
 // VirtualServerSpec is the spec of the VirtualServer resource.
 type VirtualServerSpec struct {
 	Host                             string           `json:"host,omitempty"`
+	Hosts                            []string         `json:"hosts,omitempty"`
 	HostGroup                        string           `json:"hostGroup,omitempty"`
 	VirtualServerAddress             string           `json:"virtualServerAddress,omitempty"`
 	AdditionalVirtualServerAddresses []string         `json:"additionalVirtualServerAddresses,omitempty"`
@@ -53,6 +54,43 @@ type VirtualServerSpec struct {
 	AllowSourceRange                 []string         `json:"allowSourceRange,omitempty"`
 	HttpMrfRoutingEnabled            bool             `json:"httpMrfRoutingEnabled,omitempty"`
 	Partition                        string           `json:"partition,omitempty"`
+	BigIPRef                         string           `json:"bigipRef,omitempty"`
+	RequestHeaders                   *HeaderActions   `json:"requestHeaders,omitempty"`
+	ResponseHeaders                  *HeaderActions   `json:"responseHeaders,omitempty"`
+	DefaultPool                      *DefaultPool     `json:"defaultPool,omitempty"`
+	WebSocket                        *WebSocket       `json:"websocket,omitempty"`
+	TCPIdleTimeout                   int32            `json:"tcpIdleTimeout,omitempty"`
+}
+
+// WebSocket defines the WebSocket profile attached to the virtual, /Common/websocket by default.
+type WebSocket struct {
+	Profile string `json:"profile,omitempty"`
+}
+
+// DefaultPool defines the pool attached to the virtual, which serves the requests matching no pool.
+type DefaultPool struct {
+	Service           string             `json:"service"`
+	ServicePort       intstr.IntOrString `json:"servicePort"`
+	ServiceNamespace  string             `json:"serviceNamespace,omitempty"`
+	NodeMemberLabel   string             `json:"nodeMemberLabel,omitempty"`
+	Monitor           Monitor            `json:"monitor"`
+	Monitors          []Monitor          `json:"monitors"`
+	Balance           string             `json:"loadBalancingMethod,omitempty"`
+	ReselectTries     int32              `json:"reselectTries,omitempty"`
+	ServiceDownAction string             `json:"serviceDownAction,omitempty"`
+}
+
+// HeaderActions defines the HTTP headers inserted, replaced and removed in requests or responses.
+type HeaderActions struct {
+	Insert  []HTTPHeader `json:"insert,omitempty"`
+	Replace []HTTPHeader `json:"replace,omitempty"`
+	Remove  []string     `json:"remove,omitempty"`
+}
+
+// HTTPHeader defines the name and value of an HTTP header.
+type HTTPHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 // ServiceAddress Service IP address definition (BIG-IP virtual-address).
@@ -68,6 +106,8 @@ type ServiceAddress struct {
 type Pool struct {
 	Name              string             `json:"name,omitempty"`
 	Path              string             `json:"path,omitempty"`
+	PathType          string             `json:"pathType,omitempty"`
+	Protocol          string             `json:"protocol,omitempty"`
 	Service           string             `json:"service"`
 	ServicePort       intstr.IntOrString `json:"servicePort"`
 	NodeMemberLabel   string             `json:"nodeMemberLabel,omitempty"`
@@ -80,6 +120,56 @@ type Pool struct {
 	ReselectTries     int32              `json:"reselectTries,omitempty"`
 	ServiceDownAction string             `json:"serviceDownAction,omitempty"`
 	HostRewrite       string             `json:"hostRewrite,omitempty"`
+	// Weight is the share of the traffic sent to service when alternateBackends are set, 100 by default
+	Weight            *int32             `json:"weight,omitempty"`
+	AlternateBackends []AlternateBackend `json:"alternateBackends,omitempty"`
+	Match             *PoolMatch         `json:"match,omitempty"`
+	RequestHeaders    *HeaderActions     `json:"requestHeaders,omitempty"`
+	ResponseHeaders   *HeaderActions     `json:"responseHeaders,omitempty"`
+	Redirect          *PoolRedirect      `json:"redirect,omitempty"`
+	DirectResponse    *DirectResponse    `json:"directResponse,omitempty"`
+}
+
+// PoolRedirect defines the redirect of the requests to the path of a pool, which needs no service.
+type PoolRedirect struct {
+	URL           string `json:"url"`
+	Code          int32  `json:"code,omitempty"`
+	PreservePath  bool   `json:"preservePath,omitempty"`
+	PreserveQuery bool   `json:"preserveQuery,omitempty"`
+}
+
+// DirectResponse defines the response sent by BIG-IP to the requests to the path of a pool, which needs no service.
+type DirectResponse struct {
+	Status      int32  `json:"status"`
+	Body        string `json:"body,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// PoolMatch defines the conditions of the requests sent to a pool in addition to its host and path.
+type PoolMatch struct {
+	Headers         []HTTPMatch `json:"headers,omitempty"`
+	Cookies         []HTTPMatch `json:"cookies,omitempty"`
+	QueryParameters []HTTPMatch `json:"queryParameters,omitempty"`
+	Methods         []string    `json:"methods,omitempty"`
+	SourceAddresses []string    `json:"sourceAddresses,omitempty"`
+}
+
+// HTTPMatch matches the values of a named header, cookie or query parameter.
+type HTTPMatch struct {
+	Name string `json:"name"`
+	// Operand is one of equals, starts-with, ends-with, contains and regex, equals by default.
+	// Pools with a regex operand are matched by an iRule rather than the LTM policy.
+	Operand       string   `json:"operand,omitempty"`
+	Values        []string `json:"values"`
+	CaseSensitive bool     `json:"caseSensitive,omitempty"`
+}
+
+// AlternateBackend defines a service receiving a share of the traffic of a pool.
+type AlternateBackend struct {
+	Service          string `json:"service"`
+	ServiceNamespace string `json:"serviceNamespace,omitempty"`
+	// Weight is the share of the traffic sent to the service, 100 by default
+	Weight *int32 `json:"weight,omitempty"`
 }
 
 // Monitor defines a monitor object in BIG-IP.
@@ -220,6 +310,7 @@ type TransportServerSpec struct {
 	BotDefense           string           `json:"botDefense,omitempty"`
 	Profiles             ProfileSpec      `json:"profiles,omitempty"`
 	Partition            string           `json:"partition,omitempty"`
+	BigIPRef             string           `json:"bigipRef,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -256,7 +347,7 @@ type DNSPool struct {
 	DNSRecordType     string    `json:"dnsRecordType"`
 	LoadBalanceMethod string    `json:"loadBalanceMethod"`
 	PriorityOrder     int       `json:"order"`
-	Ratio             int       `json:"ratio""`
+	Ratio             int       `json:"ratio"`
 	Monitor           Monitor   `json:"monitor"`
 	Monitors          []Monitor `json:"monitors"`
 }
@@ -300,21 +391,38 @@ type LtmIRulesSpec struct {
 }
 
 type ProfileSpec struct {
-	TCP                ProfileTCP `json:"tcp,omitempty"`
-	UDP                string     `json:"udp,omitempty"`
-	HTTP               string     `json:"http,omitempty"`
-	HTTP2              string     `json:"http2,omitempty"`
-	RewriteProfile     string     `json:"rewriteProfile,omitempty"`
-	PersistenceProfile string     `json:"persistenceProfile,omitempty"`
-	LogProfiles        []string   `json:"logProfiles,omitempty"`
-	ProfileL4          string     `json:"profileL4,omitempty"`
-	ProfileMultiplex   string     `json:"profileMultiplex,omitempty"`
+	TCP                ProfileTCP    `json:"tcp,omitempty"`
+	UDP                string        `json:"udp,omitempty"`
+	HTTP               string        `json:"http,omitempty"`
+	HTTP2              string        `json:"http2,omitempty"`
+	RewriteProfile     string        `json:"rewriteProfile,omitempty"`
+	PersistenceProfile string        `json:"persistenceProfile,omitempty"`
+	LogProfiles        []string      `json:"logProfiles,omitempty"`
+	ProfileL4          string        `json:"profileL4,omitempty"`
+	ProfileMultiplex   string        `json:"profileMultiplex,omitempty"`
+	TCPSettings        *TCPSettings  `json:"tcpSettings,omitempty"`
+	HTTPSettings       *HTTPSettings `json:"httpSettings,omitempty"`
 }
 type ProfileTCP struct {
 	Client string `json:"client,omitempty"`
 	Server string `json:"server,omitempty"`
 }
 
+// TCPSettings defines the parameters of the TCP profile generated for the virtual
+type TCPSettings struct {
+	IdleTimeout       int32  `json:"idleTimeout,omitempty"`
+	Nagle             string `json:"nagle,omitempty"`
+	KeepAliveInterval int32  `json:"keepAliveInterval,omitempty"`
+}
+
+// HTTPSettings defines the parameters of the HTTP profile generated for the virtual
+type HTTPSettings struct {
+	XForwardedFor *bool  `json:"xForwardedFor,omitempty"`
+	ServerHeader  string `json:"serverHeader,omitempty"`
+	MaxHeaderSize int32  `json:"maxHeaderSize,omitempty"`
+	ProxyType     string `json:"proxyType,omitempty"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -26,6 +26,27 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlternateBackend) DeepCopyInto(out *AlternateBackend) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlternateBackend.
+func (in *AlternateBackend) DeepCopy() *AlternateBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(AlternateBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DNSPool) DeepCopyInto(out *DNSPool) {
 	*out = *in
@@ -48,6 +69,44 @@ func (in *DNSPool) DeepCopy() *DNSPool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultPool) DeepCopyInto(out *DefaultPool) {
+	*out = *in
+	out.Monitor = in.Monitor
+	if in.Monitors != nil {
+		in, out := &in.Monitors, &out.Monitors
+		*out = make([]Monitor, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultPool.
+func (in *DefaultPool) DeepCopy() *DefaultPool {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DirectResponse) DeepCopyInto(out *DirectResponse) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectResponse.
+func (in *DirectResponse) DeepCopy() *DirectResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(DirectResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalDNS) DeepCopyInto(out *ExternalDNS) {
 	*out = *in
@@ -131,6 +190,95 @@ func (in *ExternalDNSSpec) DeepCopy() *ExternalDNSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPHeader) DeepCopyInto(out *HTTPHeader) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPHeader.
+func (in *HTTPHeader) DeepCopy() *HTTPHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPMatch) DeepCopyInto(out *HTTPMatch) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPMatch.
+func (in *HTTPMatch) DeepCopy() *HTTPMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPSettings) DeepCopyInto(out *HTTPSettings) {
+	*out = *in
+	if in.XForwardedFor != nil {
+		in, out := &in.XForwardedFor, &out.XForwardedFor
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPSettings.
+func (in *HTTPSettings) DeepCopy() *HTTPSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeaderActions) DeepCopyInto(out *HeaderActions) {
+	*out = *in
+	if in.Insert != nil {
+		in, out := &in.Insert, &out.Insert
+		*out = make([]HTTPHeader, len(*in))
+		copy(*out, *in)
+	}
+	if in.Replace != nil {
+		in, out := &in.Replace, &out.Replace
+		*out = make([]HTTPHeader, len(*in))
+		copy(*out, *in)
+	}
+	if in.Remove != nil {
+		in, out := &in.Remove, &out.Remove
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeaderActions.
+func (in *HeaderActions) DeepCopy() *HeaderActions {
+	if in == nil {
+		return nil
+	}
+	out := new(HeaderActions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressLink) DeepCopyInto(out *IngressLink) {
 	*out = *in
@@ -398,6 +546,43 @@ func (in *Pool) DeepCopyInto(out *Pool) {
 		*out = make([]Monitor, len(*in))
 		copy(*out, *in)
 	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AlternateBackends != nil {
+		in, out := &in.AlternateBackends, &out.AlternateBackends
+		*out = make([]AlternateBackend, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = new(PoolMatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequestHeaders != nil {
+		in, out := &in.RequestHeaders, &out.RequestHeaders
+		*out = new(HeaderActions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResponseHeaders != nil {
+		in, out := &in.ResponseHeaders, &out.ResponseHeaders
+		*out = new(HeaderActions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Redirect != nil {
+		in, out := &in.Redirect, &out.Redirect
+		*out = new(PoolRedirect)
+		**out = **in
+	}
+	if in.DirectResponse != nil {
+		in, out := &in.DirectResponse, &out.DirectResponse
+		*out = new(DirectResponse)
+		**out = **in
+	}
 	return
 }
 
@@ -411,6 +596,69 @@ func (in *Pool) DeepCopy() *Pool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolMatch) DeepCopyInto(out *PoolMatch) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]HTTPMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Cookies != nil {
+		in, out := &in.Cookies, &out.Cookies
+		*out = make([]HTTPMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.QueryParameters != nil {
+		in, out := &in.QueryParameters, &out.QueryParameters
+		*out = make([]HTTPMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SourceAddresses != nil {
+		in, out := &in.SourceAddresses, &out.SourceAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolMatch.
+func (in *PoolMatch) DeepCopy() *PoolMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolRedirect) DeepCopyInto(out *PoolRedirect) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolRedirect.
+func (in *PoolRedirect) DeepCopy() *PoolRedirect {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolRedirect)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProfileSpec) DeepCopyInto(out *ProfileSpec) {
 	*out = *in
@@ -420,6 +668,16 @@ func (in *ProfileSpec) DeepCopyInto(out *ProfileSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.TCPSettings != nil {
+		in, out := &in.TCPSettings, &out.TCPSettings
+		*out = new(TCPSettings)
+		**out = **in
+	}
+	if in.HTTPSettings != nil {
+		in, out := &in.HTTPSettings, &out.HTTPSettings
+		*out = new(HTTPSettings)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -465,6 +723,22 @@ func (in *ServiceAddress) DeepCopy() *ServiceAddress {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPSettings) DeepCopyInto(out *TCPSettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPSettings.
+func (in *TCPSettings) DeepCopy() *TCPSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLS) DeepCopyInto(out *TLS) {
 	*out = *in
@@ -747,6 +1021,11 @@ func (in *VirtualServerList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualServerSpec) DeepCopyInto(out *VirtualServerSpec) {
 	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Pools != nil {
 		in, out := &in.Pools, &out.Pools
 		*out = make([]Pool, len(*in))
@@ -775,6 +1054,26 @@ func (in *VirtualServerSpec) DeepCopyInto(out *VirtualServerSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RequestHeaders != nil {
+		in, out := &in.RequestHeaders, &out.RequestHeaders
+		*out = new(HeaderActions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResponseHeaders != nil {
+		in, out := &in.ResponseHeaders, &out.ResponseHeaders
+		*out = new(HeaderActions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultPool != nil {
+		in, out := &in.DefaultPool, &out.DefaultPool
+		*out = new(DefaultPool)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WebSocket != nil {
+		in, out := &in.WebSocket, &out.WebSocket
+		*out = new(WebSocket)
+		**out = **in
+	}
 	return
 }
 
@@ -803,3 +1102,19 @@ func (in *VirtualServerStatus) DeepCopy() *VirtualServerStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebSocket) DeepCopyInto(out *WebSocket) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebSocket.
+func (in *WebSocket) DeepCopy() *WebSocket {
+	if in == nil {
+		return nil
+	}
+	out := new(WebSocket)
+	in.DeepCopyInto(out)
+	return out
+}
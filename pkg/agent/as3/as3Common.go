@@ -596,12 +596,13 @@ func (am *AS3Manager) createUpdateTLSServer(prof CustomProfile, svcName string,
 						return (tlsServer.Certificates[i].Certificate < tlsServer.Certificates[j].Certificate)
 					})
 			}
+			tls13CipherGroupReference, ciphers := am.getCiphers()
 			if am.enableTLS == "1.2" {
-				tlsServer.Ciphers = am.ciphers
+				tlsServer.Ciphers = ciphers
 			} else if am.enableTLS == "1.3" {
 				tlsServer.Tls1_3Enabled = true
 				tlsServer.CipherGroup = &as3ResourcePointer{
-					BigIP: am.tls13CipherGroupReference,
+					BigIP: tls13CipherGroupReference,
 				}
 			}
 
@@ -159,7 +159,7 @@ func (am *AS3Manager) processCfgMap(rscCfgMap *AgentCfgMap) (
 
 	for tnt, apps := range obj {
 		tenantObj := dec[string(tnt)].(map[string]interface{})
-		tenantObj[as3defaultRouteDomain] = am.defaultRouteDomain
+		tenantObj[as3defaultRouteDomain] = am.getDefaultRouteDomain()
 		for app, pools := range apps {
 			appObj := tenantObj[string(app)].(map[string]interface{})
 			for _, pn := range pools {
@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/writer"
@@ -119,6 +120,8 @@ type AS3Manager struct {
 	shareNodes                bool
 	defaultRouteDomain        int
 	poolMemberType            string
+	// settingsMutex guards the ciphers and the default route domain, which can be changed at runtime
+	settingsMutex sync.RWMutex
 }
 
 // Struct to allow NewManager to receive all or only specific parameters.
@@ -139,6 +142,9 @@ type Params struct {
 	BIGIPPassword       string
 	BIGIPURL            string
 	TrustedCerts        string
+	ClientCert          string
+	ClientKey           string
+	ServerName          string
 	AS3PostDelay        int
 	ConfigWriter        writer.Writer
 	EventChan           chan interface{}
@@ -189,6 +195,9 @@ func NewAS3Manager(params *Params) *AS3Manager {
 			BIGIPURL:      params.BIGIPURL,
 			TrustedCerts:  params.TrustedCerts,
 			SSLInsecure:   params.SSLInsecure,
+			ClientCert:    params.ClientCert,
+			ClientKey:     params.ClientKey,
+			ServerName:    params.ServerName,
 			AS3PostDelay:  params.AS3PostDelay,
 			LogResponse:   params.LogResponse}),
 	}
@@ -469,7 +478,7 @@ func (am *AS3Manager) getEmptyAs3DeclarationForCISManagedPartition(partition str
 	decl["controls"] = controlObj
 	if partition != "" {
 		tenantObj := make(as3Tenant)
-		tenantObj.initDefault(am.defaultRouteDomain)
+		tenantObj.initDefault(am.getDefaultRouteDomain())
 		decl[partition] = tenantObj
 	}
 	data, _ := json.Marshal(as3Config)
@@ -523,6 +532,44 @@ func (am *AS3Manager) CleanAS3Tenant(partition string) (bool, string) {
 	return am.PostManager.postConfigRequests(string(emptyAS3Declaration), am.PostManager.getAS3APIURL([]string{partition}))
 }
 
+// SetLogResponse enables or disables logging of AS3 responses from BIG-IP
+func (am *AS3Manager) SetLogResponse(enabled bool) {
+	am.PostManager.SetLogResponse(enabled)
+}
+
+// SetPostDelay sets the time (in seconds) waited before posting a declaration to BIG-IP
+func (am *AS3Manager) SetPostDelay(seconds int) {
+	am.PostManager.SetPostDelay(seconds)
+}
+
+// SetCiphers sets the TLS 1.3 cipher group and the TLS 1.2 ciphers of the TLS server
+// profiles in the following declarations
+func (am *AS3Manager) SetCiphers(tls13CipherGroupReference, ciphers string) {
+	am.settingsMutex.Lock()
+	defer am.settingsMutex.Unlock()
+	am.tls13CipherGroupReference = tls13CipherGroupReference
+	am.ciphers = ciphers
+}
+
+func (am *AS3Manager) getCiphers() (string, string) {
+	am.settingsMutex.RLock()
+	defer am.settingsMutex.RUnlock()
+	return am.tls13CipherGroupReference, am.ciphers
+}
+
+// SetDefaultRouteDomain sets the default route domain of the tenants in the following declarations
+func (am *AS3Manager) SetDefaultRouteDomain(routeDomain int) {
+	am.settingsMutex.Lock()
+	defer am.settingsMutex.Unlock()
+	am.defaultRouteDomain = routeDomain
+}
+
+func (am *AS3Manager) getDefaultRouteDomain() int {
+	am.settingsMutex.RLock()
+	defer am.settingsMutex.RUnlock()
+	return am.defaultRouteDomain
+}
+
 // fetchAS3Schema ...
 func (am *AS3Manager) fetchAS3Schema() {
 	log.Debugf("[AS3] Validating AS3 schema with  %v", as3SchemaFileName)
@@ -536,11 +583,12 @@ func (am *AS3Manager) ConfigDeployer() {
 	// For the very first post after starting controller, need not wait to post
 	firstPost := true
 	am.unprocessableEntityStatus = false
-	postDelayTimeout := time.Duration(am.PostManager.AS3PostDelay) * time.Second
 	for msgReq := range am.ReqChan {
-		if !firstPost && am.PostManager.AS3PostDelay != 0 {
+		postDelay := am.PostManager.getPostDelay()
+		postDelayTimeout := time.Duration(postDelay) * time.Second
+		if !firstPost && postDelay != 0 {
 			// Time (in seconds) that CIS waits to post the AS3 declaration to BIG-IP.
-			log.Debugf("[AS3] Delaying post to BIG-IP for %v seconds", am.PostManager.AS3PostDelay)
+			log.Debugf("[AS3] Delaying post to BIG-IP for %v seconds", postDelay)
 			_ = <-time.After(postDelayTimeout)
 		}
 
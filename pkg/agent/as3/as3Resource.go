@@ -44,7 +44,7 @@ func (am *AS3Manager) generateAS3ResourceDeclaration() as3ADC {
 		partitions = am.Resources.Partitions
 	}
 	for partition := range partitions {
-		adc.initTenant(partition, am.defaultRouteDomain)
+		adc.initTenant(partition, am.getDefaultRouteDomain())
 		sharedApp := adc.getAS3SharedApp(partition)
 
 		// Process CIS Resources to create AS3 Resources
@@ -18,15 +18,15 @@ package as3
 
 import (
 	"bytes"
-	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/bigiptls"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
 	routeclient "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 )
@@ -52,6 +52,8 @@ type PostManager struct {
 	HttpClient *http.Client
 	activeCfg  config
 	PostParams
+	// settingsMutex guards LogResponse and AS3PostDelay in PostParams, which can be changed at runtime
+	settingsMutex sync.RWMutex
 }
 
 type PostParams struct {
@@ -60,7 +62,13 @@ type PostParams struct {
 	BIGIPURL      string
 	TrustedCerts  string
 	SSLInsecure   bool
-	AS3PostDelay  int
+	// ClientCert and ClientKey are the PEM encoded certificate and key
+	// presented to the BIG-IP management API for mutual TLS
+	ClientCert string
+	ClientKey  string
+	// ServerName enforces verification of the BIG-IP certificate against this SAN
+	ServerName   string
+	AS3PostDelay int
 	//Log the AS3 response body in Controller logs
 	LogResponse   bool
 	RouteClientV1 routeclient.RouteV1Interface
@@ -81,25 +89,47 @@ func NewPostManager(params PostParams) *PostManager {
 	return pm
 }
 
+// SetLogResponse enables or disables logging of AS3 responses from BIG-IP
+func (postMgr *PostManager) SetLogResponse(enabled bool) {
+	postMgr.settingsMutex.Lock()
+	defer postMgr.settingsMutex.Unlock()
+	postMgr.LogResponse = enabled
+}
+
+func (postMgr *PostManager) isLogResponseEnabled() bool {
+	postMgr.settingsMutex.RLock()
+	defer postMgr.settingsMutex.RUnlock()
+	return postMgr.LogResponse
+}
+
+// SetPostDelay sets the time (in seconds) waited before posting a declaration to BIG-IP
+func (postMgr *PostManager) SetPostDelay(seconds int) {
+	postMgr.settingsMutex.Lock()
+	defer postMgr.settingsMutex.Unlock()
+	postMgr.AS3PostDelay = seconds
+}
+
+func (postMgr *PostManager) getPostDelay() int {
+	postMgr.settingsMutex.RLock()
+	defer postMgr.settingsMutex.RUnlock()
+	return postMgr.AS3PostDelay
+}
+
 func (postMgr *PostManager) setupBIGIPRESTClient() {
-	// Get the SystemCertPool, continue with an empty pool on error
-	rootCAs, _ := x509.SystemCertPool()
-	if rootCAs == nil {
-		rootCAs = x509.NewCertPool()
-	}
 	// TODO: Make sure appMgr sets certificates in bigipInfo
-	certs := []byte(postMgr.TrustedCerts)
-
-	// Append our certs to the system pool
-	if ok := rootCAs.AppendCertsFromPEM(certs); !ok {
-		log.Debug("[AS3] No certs appended, using only system certs")
+	tlsConfig, err := bigiptls.NewConfig(bigiptls.Params{
+		TrustedCerts: postMgr.TrustedCerts,
+		SSLInsecure:  postMgr.SSLInsecure,
+		ServerName:   postMgr.ServerName,
+		ClientCert:   postMgr.ClientCert,
+		ClientKey:    postMgr.ClientKey,
+	})
+	if err != nil {
+		log.Errorf("[AS3] %v", err)
 	}
 
 	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: postMgr.SSLInsecure,
-			RootCAs:            rootCAs,
-		},
+		TLSClientConfig: tlsConfig,
 	}
 
 	postMgr.HttpClient = &http.Client{
@@ -248,7 +278,7 @@ func (postMgr *PostManager) httpReq(request *http.Request) (*http.Response, map[
 	err = json.Unmarshal(body, &response)
 	if err != nil {
 		log.Errorf("[AS3] Response body unmarshal failed: %v\n", err)
-		if postMgr.LogResponse {
+		if postMgr.isLogResponseEnabled() {
 			log.Errorf("[AS3] Raw response from Big-IP: %v", string(body))
 		}
 		return nil, nil
@@ -281,7 +311,7 @@ func (postMgr *PostManager) handleResponseStatusNotFound(responseMap map[string]
 		log.Errorf("[AS3] Big-IP Responded with error code: %v", http.StatusNotFound)
 	}
 
-	if postMgr.LogResponse {
+	if postMgr.isLogResponseEnabled() {
 		log.Errorf("[AS3] Raw response from Big-IP: %v ", responseMap)
 	}
 	return true, responseStatusNotFound
@@ -294,7 +324,7 @@ func (postMgr *PostManager) handleStatusUnprocessableEntity(responseMap map[stri
 		log.Errorf("[AS3] Big-IP Responded with error code: %v", http.StatusUnprocessableEntity)
 	}
 
-	if postMgr.LogResponse {
+	if postMgr.isLogResponseEnabled() {
 		log.Errorf("[AS3] Raw response from Big-IP: %v ", responseMap)
 	}
 	return false, responseStatusUnprocessableEntity
@@ -313,7 +343,7 @@ func (postMgr *PostManager) handleResponseOthers(responseMap map[string]interfac
 		log.Errorf("[AS3] Big-IP Responded with code: %v", responseMap["code"])
 	}
 
-	if postMgr.LogResponse {
+	if postMgr.isLogResponseEnabled() {
 		log.Errorf("[AS3] Raw response from Big-IP: %v ", responseMap)
 	}
 	//return postMgr.postOnEventOrTimeout(timeoutMedium, cfg)
@@ -1995,7 +1995,7 @@ func (appMgr *Manager) syncIngresses(
 						log.Errorf("[CORE] %s", msg)
 						appMgr.recordV1IngressEvent(ing, "InvalidData", msg)
 					} else {
-						if nil != ing.Spec.DefaultBackend {
+						if nil == ing.Spec.Rules {
 							fullPoolName := fmt.Sprintf("/%s/%s", rsCfg.Virtual.Partition,
 								FormatIngressPoolName(sKey.Namespace, sKey.ServiceName))
 							RemoveUnReferredHealthMonitors(rsCfg, fullPoolName, monitors)
@@ -3764,6 +3764,9 @@ func getIngressV1Backend(ing *netv1.Ingress) []string {
 				}
 			}
 		}
+		if nil != ing.Spec.DefaultBackend && nil != ing.Spec.DefaultBackend.Service {
+			services[ing.Spec.DefaultBackend.Service.Name] = true
+		}
 	} else { // single-service
 		services[ing.Spec.DefaultBackend.Service.Name] = true
 	}
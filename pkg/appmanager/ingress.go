@@ -122,9 +122,10 @@ func (appMgr *Manager) checkV1Ingress(
 
 		// If we have a config for this IP:Port, and either that config or the current config
 		// is for a single service ingress, then we don't allow the new Ingress to share the VS
-		// It doesn't make sense for single service Ingresses to share a VS
+		// It doesn't make sense for single service Ingresses to share a VS.
+		// A multi-service config with the default pool of its default backend has more pools
 		if oldCfg, exists := appMgr.resources.GetByName(rsName); exists {
-			if (oldCfg.Virtual.PoolName != "" || ing.Spec.Rules == nil) &&
+			if ((oldCfg.Virtual.PoolName != "" && len(oldCfg.Pools) == 1) || ing.Spec.Rules == nil) &&
 				oldCfg.MetaData.IngName != ing.ObjectMeta.Name &&
 				oldCfg.Virtual.VirtualAddress.BindAddr != "" {
 				log.Warningf(
@@ -163,9 +164,10 @@ func (appMgr *Manager) checkV1SingleServivceIngress(
 		rsName := NameRef{Name: FormatIngressVSName(bindAddr, portStruct.port), Partition: partition}
 		// If we have a config for this IP:Port, and either that config or the current config
 		// is for a single service ingress, then we don't allow the new Ingress to share the VS
-		// It doesn't make sense for single service Ingresses to share a VS
+		// It doesn't make sense for single service Ingresses to share a VS.
+		// A multi-service config with the default pool of its default backend has more pools
 		if oldCfg, exists := appMgr.resources.GetByName(rsName); exists {
-			if (oldCfg.Virtual.PoolName != "" || ing.Spec.Rules == nil) &&
+			if ((oldCfg.Virtual.PoolName != "" && len(oldCfg.Pools) == 1) || ing.Spec.Rules == nil) &&
 				oldCfg.MetaData.IngName != ing.ObjectMeta.Name &&
 				oldCfg.Virtual.VirtualAddress.BindAddr != "" {
 				log.Warningf(
@@ -366,6 +368,34 @@ func (appMgr *Manager) recordV1IngressEvent(
 	evNotifier.RecordEvent(ing, v1.EventTypeNormal, reason, message)
 }
 
+// getV1IngressDefaultBackendPool returns the pool for the default backend of the Ingress
+func getV1IngressDefaultBackendPool(
+	ing *netv1.Ingress,
+	svcIndexer cache.Indexer,
+	partition string,
+	balance string,
+) (Pool, error) {
+	var backendPort int32
+	var err error
+	backend := ing.Spec.DefaultBackend.Service
+	if backend.Port.Number != 0 {
+		backendPort = backend.Port.Number
+	} else if backend.Port.Name != "" {
+		backendPort, err = GetServicePort(ing.ObjectMeta.Namespace, backend.Name, svcIndexer, backend.Port.Name, ResourceTypeIngress)
+	}
+	pool := Pool{
+		Name: FormatIngressPoolName(
+			ing.ObjectMeta.Namespace,
+			backend.Name,
+		),
+		Partition:   partition,
+		Balance:     balance,
+		ServiceName: backend.Name,
+		ServicePort: backendPort,
+	}
+	return pool, err
+}
+
 func processV1IngressRules(
 	ing *netv1.IngressSpec,
 	urlRewriteMap map[string]string,
@@ -398,16 +428,26 @@ func processV1IngressRules(
 					continue
 				}
 				ruleName := formatIngressRuleName(rule.Host, path.Path, poolName)
+				// Exact paths have their own rule, which precedes the rule of the same prefix path
+				ruleKey := uri
+				exact := path.PathType != nil && *path.PathType == netv1.PathTypeExact
+				if exact {
+					ruleName += "_exact"
+					ruleKey += "#exact"
+				}
 				// This blank name gets overridden by an ordinal later on
 				rl, err = createRule(uri, poolName, partition, ruleName)
 				if nil != err {
 					log.Warningf("[CORE] Error configuring rule: %v", err)
 					return nil, nil, nil
 				}
+				if exact {
+					setExactPathCondition(rl, path.Path)
+				}
 				if true == strings.HasPrefix(uri, "*.") {
-					wildcards[uri] = rl
+					wildcards[ruleKey] = rl
 				} else {
-					rlMap[uri] = rl
+					rlMap[ruleKey] = rl
 				}
 
 				// Process url-rewrite annotation
@@ -688,6 +728,28 @@ func (appMgr *Manager) createRSConfigFromV1Ingress(
 			}
 		}
 
+		// The default backend of a multi-service Ingress becomes the default
+		// pool of the virtual, serving requests that match none of the rules
+		if nil != ing.Spec.DefaultBackend && nil != ing.Spec.DefaultBackend.Service {
+			pool, err := getV1IngressDefaultBackendPool(ing, svcIndexer, cfg.Virtual.Partition, balance)
+			if err != nil {
+				log.Warningf("[CORE] Error fetching service port for ingress %s/%s: %v", ing.Namespace, ing.Name, err)
+			} else {
+				exists := false
+				for _, pl := range pools {
+					if pl.Name == pool.Name {
+						exists = true
+						break
+					}
+				}
+				if !exists {
+					pools = append(pools, pool)
+				}
+				ssPoolName = pool.Name
+				cfg.Virtual.PoolName = JoinBigipPath(cfg.Virtual.Partition, ssPoolName)
+			}
+		}
+
 		rules, urlRewriteRefs, appRootRefs = processV1IngressRules(
 			&ing.Spec,
 			urlRewriteMap,
@@ -698,25 +760,9 @@ func (appMgr *Manager) createRSConfigFromV1Ingress(
 		)
 		plcy = CreatePolicy(*rules, cfg.Virtual.Name, cfg.Virtual.Partition)
 	} else { // single-service
-		var backendPort int32
-		var err error
-		if ing.Spec.DefaultBackend.Service.Port.Number != 0 {
-			backendPort = ing.Spec.DefaultBackend.Service.Port.Number
-		} else if ing.Spec.DefaultBackend.Service.Port.Name != "" {
-			backendPort, err = GetServicePort(ns, ing.Spec.DefaultBackend.Service.Name, svcIndexer, ing.Spec.DefaultBackend.Service.Port.Name, ResourceTypeIngress)
-			if err != nil {
-				log.Warningf("[CORE] Error fetching service port for ingress %s/%s: %v", ing.Namespace, ing.Name, err)
-			}
-		}
-		pool := Pool{
-			Name: FormatIngressPoolName(
-				ing.ObjectMeta.Namespace,
-				ing.Spec.DefaultBackend.Service.Name,
-			),
-			Partition:   cfg.Virtual.Partition,
-			Balance:     balance,
-			ServiceName: ing.Spec.DefaultBackend.Service.Name,
-			ServicePort: backendPort,
+		pool, err := getV1IngressDefaultBackendPool(ing, svcIndexer, cfg.Virtual.Partition, balance)
+		if err != nil {
+			log.Warningf("[CORE] Error fetching service port for ingress %s/%s: %v", ing.Namespace, ing.Name, err)
 		}
 		ssPoolName = pool.Name
 		pools = append(pools, pool)
@@ -736,6 +782,10 @@ func (appMgr *Manager) createRSConfigFromV1Ingress(
 		}
 	}
 	cfg.MetaData.IngName = ing.ObjectMeta.Name
+	ingKey := ing.ObjectMeta.Namespace + "/" + ing.ObjectMeta.Name
+	if ssPoolName != "" {
+		cfg.MetaData.DefaultPoolIngress = ingKey
+	}
 
 	resources.Lock()
 	defer resources.Unlock()
@@ -764,11 +814,28 @@ func (appMgr *Manager) createRSConfigFromV1Ingress(
 			}
 		}
 		if len(cfg.Pools) > 1 && nil != ing.Spec.Rules {
-			cfg.Virtual.PoolName = ""
+			// Keep the default backend of a multi-service Ingress as the virtual's
+			// default pool, the default pool of the other Ingresses is kept
+			defaultPoolIngress := cfg.MetaData.DefaultPoolIngress
+			if ssPoolName != "" {
+				poolName := JoinBigipPath(cfg.Virtual.Partition, ssPoolName)
+				if cfg.Virtual.PoolName != "" && cfg.Virtual.PoolName != poolName &&
+					defaultPoolIngress != "" && defaultPoolIngress != ingKey {
+					log.Warningf("[CORE] Ingress %v and Ingress %v have different default backends for virtual %v, "+
+						"keeping the default backend of Ingress %v", defaultPoolIngress, ingKey, cfg.Virtual.Name, defaultPoolIngress)
+				} else {
+					cfg.Virtual.PoolName = poolName
+					cfg.MetaData.DefaultPoolIngress = ingKey
+				}
+			} else if defaultPoolIngress == ingKey {
+				cfg.Virtual.PoolName = ""
+				cfg.MetaData.DefaultPoolIngress = ""
+			}
 		} else if nil == ing.Spec.Rules {
 			// If updating an Ingress from multi-service to single-service, we need to
 			// reset the virtual's default pool
 			cfg.Virtual.PoolName = JoinBigipPath(cfg.Virtual.Partition, ssPoolName)
+			cfg.MetaData.DefaultPoolIngress = ingKey
 		}
 
 		// If any of the new rules already exist, update them; else add them
@@ -778,7 +845,8 @@ func (appMgr *Manager) createRSConfigFromV1Ingress(
 				found := false
 				for i, rl := range policy.Rules {
 					if rl.Name == newRule.Name || (!IsAnnotationRule(rl.Name) &&
-						!IsAnnotationRule(newRule.Name) && rl.FullURI == newRule.FullURI) {
+						!IsAnnotationRule(newRule.Name) && rl.FullURI == newRule.FullURI &&
+						IsExactPathRule(rl) == IsExactPathRule(newRule)) {
 						found = true
 						// Replace old rule with new rule, but make sure Ordinal is correct.
 						newRule.Ordinal = rl.Ordinal
@@ -265,6 +265,148 @@ var _ = Describe("V1 Ingress Tests", func() {
 			Expect(tf).To(Equal(false))
 			Expect(svcQKey).To(BeNil())
 		})
+
+		It("honours the path type of the ingress paths", func() {
+			exact := netv1.PathTypeExact
+			prefix := netv1.PathTypePrefix
+			newPath := func(path, svcName string, pathType *netv1.PathType) netv1.HTTPIngressPath {
+				return netv1.HTTPIngressPath{
+					Path:     path,
+					PathType: pathType,
+					Backend: netv1.IngressBackend{
+						Service: &netv1.IngressServiceBackend{Name: svcName, Port: netv1.ServiceBackendPort{Number: 80}},
+					},
+				}
+			}
+			spec := netv1.IngressSpec{
+				Rules: []netv1.IngressRule{{
+					Host: "foo.com",
+					IngressRuleValue: netv1.IngressRuleValue{
+						HTTP: &netv1.HTTPIngressRuleValue{
+							Paths: []netv1.HTTPIngressPath{
+								newPath("/foo", "svc1", &prefix),
+								newPath("/foo", "svc2", &exact),
+								newPath("/foo/bar", "svc1", nil),
+							},
+						},
+					},
+				}},
+			}
+			pools := []Pool{{Name: "svc1_pool", ServiceName: "svc1"}, {Name: "svc2_pool", ServiceName: "svc2"}}
+			rules, _, _ := processV1IngressRules(&spec, nil, nil, nil, pools, "velcro")
+			Expect(*rules).To(HaveLen(3))
+			// Longer paths first, then the exact path before the prefix path
+			Expect((*rules)[0].FullURI).To(Equal("foo.com/foo/bar"))
+			Expect((*rules)[1].Actions[0].Pool).To(Equal("/velcro/svc2_pool"))
+			Expect((*rules)[1].Conditions[1]).To(Equal(&Condition{
+				Equals:  true,
+				HTTPURI: true,
+				Path:    true,
+				Name:    "0",
+				Request: true,
+				Values:  []string{"/foo"},
+			}))
+			Expect((*rules)[2].Actions[0].Pool).To(Equal("/velcro/svc1_pool"))
+			Expect((*rules)[2].Conditions[1].PathSegment).To(BeTrue())
+		})
+
+		It("attaches the default backend of a multi-service ingress as the default pool", func() {
+			spec := netv1.IngressSpec{
+				IngressClassName: &IngressClassName,
+				DefaultBackend: &netv1.IngressBackend{
+					Service: &netv1.IngressServiceBackend{Name: "svc404", Port: netv1.ServiceBackendPort{Number: 8080}},
+				},
+				Rules: []netv1.IngressRule{{
+					Host: "foo.com",
+					IngressRuleValue: netv1.IngressRuleValue{
+						HTTP: &netv1.HTTPIngressRuleValue{
+							Paths: []netv1.HTTPIngressPath{{
+								Path: "/foo",
+								Backend: netv1.IngressBackend{
+									Service: &netv1.IngressServiceBackend{Name: "svc1", Port: netv1.ServiceBackendPort{Number: 80}},
+								},
+							}},
+						},
+					},
+				}},
+			}
+			ing := NewV1Ingress("ingress", "1", namespace, spec,
+				map[string]string{
+					F5VsBindAddrAnnotation:  "1.2.3.4",
+					F5VsPartitionAnnotation: "velcro",
+				})
+			svc1 := test.NewService("svc1", "1", namespace, v1.ServiceTypeClusterIP,
+				[]v1.ServicePort{newServicePort("svc1", 80)})
+			svc404 := test.NewService("svc404", "1", namespace, v1.ServiceTypeClusterIP,
+				[]v1.ServicePort{newServicePort("svc404", 8080)})
+			Expect(mockMgr.addService(svc1)).To(BeTrue(), "Service should be processed.")
+			Expect(mockMgr.addService(svc404)).To(BeTrue(), "Service should be processed.")
+			Expect(mockMgr.addV1Ingress(ing)).To(BeTrue(), "Ingress resource should be processed.")
+
+			_, svcQKeys := mockMgr.appMgr.checkV1Ingress(ing)
+			Expect(svcQKeys).To(HaveLen(2), "Default backend service not watched")
+
+			resources := mockMgr.resources()
+			svcKey := ServiceKey{Namespace: namespace, ServiceName: "svc404", ServicePort: 8080}
+			cfg, found := resources.Get(svcKey, NameRef{Name: FormatIngressVSName("1.2.3.4", 80), Partition: "velcro"})
+			Expect(found).To(BeTrue())
+			Expect(cfg.Pools).To(HaveLen(2))
+			Expect(cfg.Virtual.PoolName).To(Equal(JoinBigipPath("velcro", FormatIngressPoolName(namespace, "svc404"))))
+			Expect(cfg.Policies[0].Rules).To(HaveLen(1))
+		})
+
+		It("keeps the default pool of another ingress sharing the virtual", func() {
+			newSpec := func(defaultSvc, host, svc string) netv1.IngressSpec {
+				spec := netv1.IngressSpec{
+					IngressClassName: &IngressClassName,
+					Rules: []netv1.IngressRule{{
+						Host: host,
+						IngressRuleValue: netv1.IngressRuleValue{
+							HTTP: &netv1.HTTPIngressRuleValue{
+								Paths: []netv1.HTTPIngressPath{{
+									Path: "/foo",
+									Backend: netv1.IngressBackend{
+										Service: &netv1.IngressServiceBackend{Name: svc, Port: netv1.ServiceBackendPort{Number: 80}},
+									},
+								}},
+							},
+						},
+					}},
+				}
+				if defaultSvc != "" {
+					spec.DefaultBackend = &netv1.IngressBackend{
+						Service: &netv1.IngressServiceBackend{Name: defaultSvc, Port: netv1.ServiceBackendPort{Number: 8080}},
+					}
+				}
+				return spec
+			}
+			annotations := map[string]string{
+				F5VsBindAddrAnnotation:  "1.2.3.4",
+				F5VsPartitionAnnotation: "velcro",
+			}
+			for _, svc := range []string{"svc1", "svc2", "svc3"} {
+				Expect(mockMgr.addService(test.NewService(svc, "1", namespace, v1.ServiceTypeClusterIP,
+					[]v1.ServicePort{newServicePort(svc, 80)}))).To(BeTrue(), "Service should be processed.")
+			}
+			for _, svc := range []string{"svc404", "svc405"} {
+				Expect(mockMgr.addService(test.NewService(svc, "1", namespace, v1.ServiceTypeClusterIP,
+					[]v1.ServicePort{newServicePort(svc, 8080)}))).To(BeTrue(), "Service should be processed.")
+			}
+			ing1 := NewV1Ingress("ingress1", "1", namespace, newSpec("svc404", "foo.com", "svc1"), annotations)
+			ing2 := NewV1Ingress("ingress2", "1", namespace, newSpec("", "bar.com", "svc2"), annotations)
+			ing3 := NewV1Ingress("ingress3", "1", namespace, newSpec("svc405", "baz.com", "svc3"), annotations)
+			Expect(mockMgr.addV1Ingress(ing1)).To(BeTrue(), "Ingress resource should be processed.")
+			Expect(mockMgr.addV1Ingress(ing2)).To(BeTrue(), "Ingress resource should be processed.")
+			Expect(mockMgr.addV1Ingress(ing3)).To(BeTrue(), "Ingress resource should be processed.")
+
+			resources := mockMgr.resources()
+			svcKey := ServiceKey{Namespace: namespace, ServiceName: "svc404", ServicePort: 8080}
+			cfg, found := resources.Get(svcKey, NameRef{Name: FormatIngressVSName("1.2.3.4", 80), Partition: "velcro"})
+			Expect(found).To(BeTrue())
+			Expect(cfg.Virtual.PoolName).To(Equal(JoinBigipPath("velcro", FormatIngressPoolName(namespace, "svc404"))),
+				"Default pool of ingress1 replaced")
+			Expect(cfg.MetaData.DefaultPoolIngress).To(Equal(namespace + "/ingress1"))
+		})
 	})
 
 	Context("V1 ingress health monitors", func() {
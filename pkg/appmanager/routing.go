@@ -113,6 +113,26 @@ func createRule(uri, poolName, partition, ruleName string) (*Rule, error) {
 	return &rl, nil
 }
 
+// setExactPathCondition matches the whole path of the rule instead of its path segments,
+// for the Ingress paths of type Exact
+func setExactPathCondition(rl *Rule, path string) {
+	var conditions []*Condition
+	for _, cond := range rl.Conditions {
+		if !cond.PathSegment {
+			conditions = append(conditions, cond)
+		}
+	}
+	rl.Conditions = append(conditions, &Condition{
+		Equals:  true,
+		HTTPURI: true,
+		Path:    true,
+		Name:    "0",
+		Index:   0,
+		Request: true,
+		Values:  []string{path},
+	})
+}
+
 // format the rule name for an Ingress
 func formatIngressRuleName(host, path, pool string) string {
 	var rule string
@@ -160,16 +180,26 @@ func processIngressRules(
 					continue
 				}
 				ruleName := formatIngressRuleName(rule.Host, path.Path, poolName)
+				// Exact paths have their own rule, which precedes the rule of the same prefix path
+				ruleKey := uri
+				exact := path.PathType != nil && *path.PathType == v1beta1.PathTypeExact
+				if exact {
+					ruleName += "_exact"
+					ruleKey += "#exact"
+				}
 				// This blank name gets overridden by an ordinal later on
 				rl, err = createRule(uri, poolName, partition, ruleName)
 				if nil != err {
 					log.Warningf("[CORE] Error configuring rule: %v", err)
 					return nil, nil, nil
 				}
+				if exact {
+					setExactPathCondition(rl, path.Path)
+				}
 				if true == strings.HasPrefix(uri, "*.") {
-					wildcards[uri] = rl
+					wildcards[ruleKey] = rl
 				} else {
-					rlMap[uri] = rl
+					rlMap[ruleKey] = rl
 				}
 
 				// Process url-rewrite annotation
@@ -0,0 +1,78 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package bigiptls builds the TLS settings of the REST clients of BIG-IP
+package bigiptls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+)
+
+// Params are the TLS settings of a BIG-IP
+type Params struct {
+	TrustedCerts string
+	SSLInsecure  bool
+	// ServerName is the name verified in the BIG-IP certificate instead of the URL host
+	ServerName string
+	ClientCert string
+	ClientKey  string
+}
+
+// NewConfig returns the TLS config of a BIG-IP REST client. The trusted certificates are appended to
+// the system pool. An error is returned with the config without client certificate when the client
+// certificate or key is invalid.
+func NewConfig(params Params) (*tls.Config, error) {
+	// Get the SystemCertPool, continue with an empty pool on error
+	rootCAs, _ := x509.SystemCertPool()
+	if rootCAs == nil {
+		rootCAs = x509.NewCertPool()
+	}
+	// Append our certs to the system pool
+	if ok := rootCAs.AppendCertsFromPEM([]byte(params.TrustedCerts)); !ok {
+		log.Debug("[AS3] No certs appended, using only system certs")
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: params.SSLInsecure,
+		RootCAs:            rootCAs,
+	}
+	// Verify the BIG-IP certificate against the configured SAN instead of the URL host
+	if params.ServerName != "" {
+		tlsConfig.ServerName = params.ServerName
+		tlsConfig.InsecureSkipVerify = false
+	}
+	if params.ClientCert != "" || params.ClientKey != "" {
+		clientCert, err := ValidateClientCert(params.ClientCert, params.ClientKey)
+		if err != nil {
+			return tlsConfig, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+	return tlsConfig, nil
+}
+
+// ValidateClientCert parses the PEM encoded client certificate and key for BIG-IP authentication
+func ValidateClientCert(cert, key string) (tls.Certificate, error) {
+	clientCert, err := tls.X509KeyPair([]byte(cert), []byte(key))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("invalid client certificate for BIG-IP authentication: %v", err)
+	}
+	return clientCert, nil
+}
@@ -0,0 +1,13 @@
+package bigiptls_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBigIPTLS(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "BIG-IP TLS Suite")
+}
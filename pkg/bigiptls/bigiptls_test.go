@@ -0,0 +1,27 @@
+package bigiptls_test
+
+import (
+	. "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/bigiptls"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BIG-IP TLS Tests", func() {
+	It("Verifies the server name instead of skipping verification", func() {
+		tlsConfig, err := NewConfig(Params{SSLInsecure: true, ServerName: "bigip.mgmt.local"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tlsConfig.ServerName).To(Equal("bigip.mgmt.local"))
+		Expect(tlsConfig.InsecureSkipVerify).To(BeFalse())
+		Expect(tlsConfig.RootCAs).NotTo(BeNil())
+	})
+
+	It("Rejects invalid client certificate", func() {
+		tlsConfig, err := NewConfig(Params{SSLInsecure: true, ClientCert: "invalid", ClientKey: "invalid"})
+		Expect(err).To(HaveOccurred())
+		Expect(tlsConfig.InsecureSkipVerify).To(BeTrue())
+		Expect(tlsConfig.Certificates).To(BeEmpty())
+
+		_, err = ValidateClientCert("invalid", "")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -25,8 +25,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	bigIPPrometheus "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/prometheus"
 	rsc "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/resource"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
 	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/writer"
@@ -57,7 +60,9 @@ var baseAS3Config = `{
 var DEFAULT_PARTITION string
 
 func NewAgent(params AgentParams) *Agent {
-	DEFAULT_PARTITION = params.Partition
+	if !params.LTMOnly {
+		DEFAULT_PARTITION = params.Partition
+	}
 	postMgr := NewPostManager(params.PostParams)
 	configWriter, err := writer.NewConfigWriter()
 	if nil != err {
@@ -69,23 +74,36 @@ func NewAgent(params AgentParams) *Agent {
 		ConfigWriter:          configWriter,
 		EventChan:             make(chan interface{}),
 		postChan:              make(chan ResourceConfigRequest, 1),
-		retryChan:             make(chan struct{}, 1),
 		respChan:              make(chan resourceStatusMeta, 1),
 		cachedTenantDeclMap:   make(map[string]as3Tenant),
 		incomingTenantDeclMap: make(map[string]as3Tenant),
 		retryTenantDeclMap:    make(map[string]*tenantParams),
+		pendingTenantDeclMap:  make(map[string]as3Tenant),
+		tenantWorkers:         make(map[string]*tenantWorker),
 		tenantPriorityMap:     make(map[string]int),
 		userAgent:             params.UserAgent,
 		HttpAddress:           params.HttpAddress,
 		ccclGTMAgent:          params.CCCLGTMAgent,
+		debugAPI:              params.DebugAPI,
+		batch:                 params.Batch,
+		deletionGuard: deletionGuard{
+			DeletionGuardParams: params.DeletionGuard,
+			heldTenants:         make(map[string]struct{}),
+		},
+	}
+	// LTM only agents have no VxlanMgr consuming the pool members
+	if params.LTMOnly {
+		agent.EventChan = nil
 	}
 	// agentWorker runs as a separate go routine
-	// blocks on postChan to get new/updated configuration to be posted to BIG-IP
+	// blocks on postChan to get new/updated configuration and hands the tenant declarations to the tenant workers
 	go agent.agentWorker()
 
-	// retryWorker runs as a separate go routine
-	// blocks on retryChan ; retries failed declarations and polls for accepted tenant statuses
-	go agent.retryWorker()
+	// deletionGuardWorker runs as a separate go routine
+	// reposts the config held by the deletion guard once the held deletions are allowed
+	if agent.deletionGuard.enabled() {
+		go agent.deletionGuardWorker(deletionGuardInterval)
+	}
 
 	// If running in VXLAN mode, extract the partition name from the tunnel
 	// to be used in configuring a net instance of CCCL for that partition
@@ -134,8 +152,11 @@ func NewAgent(params AgentParams) *Agent {
 			GtmBigIPURL:      params.GTMParams.GTMBigIpUrl,
 		}
 	}
+	agent.globalCfg = gs
+	agent.bigIPCfg = bs
+	agent.gtmBigIPCfg = gtm
 	//For IPV6 net config is not required. f5-sdk doesnt support ipv6
-	if !(params.EnableIPV6) {
+	if !(params.EnableIPV6) && !params.LTMOnly {
 		agent.startPythonDriver(
 			gs,
 			bs,
@@ -143,6 +164,11 @@ func NewAgent(params AgentParams) *Agent {
 			params.PythonBaseDir,
 		)
 	}
+	// Enable "/health", "/ready" and "/metrics" endpoint with controller,
+	// the agents of the BIG-IP targets are reported by the agent of the default BIG-IP
+	if !params.LTMOnly {
+		go agent.healthCheck(!params.EnableIPV6)
+	}
 	// Set the AS3 version for the agent
 	err = agent.IsBigIPAppServicesAvailable()
 	if err != nil {
@@ -153,6 +179,54 @@ func NewAgent(params AgentParams) *Agent {
 	return agent
 }
 
+// UpdateBigIPCredentials rotates the credentials used for BIG-IP LTM configuration.
+// AS3 requests pick up the new credentials immediately and the python driver
+// is handed an updated config section, informers are not restarted.
+func (agent *Agent) UpdateBigIPCredentials(username, password, url string) {
+	agent.PostManager.UpdateCredentials(username, password, url)
+	log.Infof("[AS3] Updated BIG-IP credentials for %v", url)
+
+	agent.driverCfgMutex.Lock()
+	defer agent.driverCfgMutex.Unlock()
+	agent.bigIPCfg.BigIPUsername = username
+	agent.bigIPCfg.BigIPPassword = password
+	agent.bigIPCfg.BigIPURL = url
+	agent.sendDriverSection("bigip", agent.bigIPCfg)
+}
+
+// UpdateGTMCredentials rotates the credentials used for the GTM BIG-IP
+func (agent *Agent) UpdateGTMCredentials(username, password, url string) {
+	agent.driverCfgMutex.Lock()
+	defer agent.driverCfgMutex.Unlock()
+	agent.gtmBigIPCfg.GtmBigIPUsername = username
+	agent.gtmBigIPCfg.GtmBigIPPassword = password
+	agent.gtmBigIPCfg.GtmBigIPURL = url
+	log.Infof("Updated GTM BIG-IP credentials for %v", url)
+	if agent.globalCfg.GTM {
+		agent.sendDriverSection("gtm_bigip", agent.gtmBigIPCfg)
+	}
+}
+
+// sendDriverSection writes a config section for the python driver if it is running
+func (agent *Agent) sendDriverSection(name string, section interface{}) {
+	if agent.EnableIPV6 || agent.PythonDriverPID == 0 {
+		return
+	}
+	doneCh, errCh, err := agent.ConfigWriter.SendSection(name, section)
+	if nil != err {
+		log.Warningf("Failed to write %v config section: %v", name, err)
+		return
+	}
+	select {
+	case <-doneCh:
+		log.Debugf("Wrote %v config section", name)
+	case e := <-errCh:
+		log.Warningf("Failed to write %v config section: %v", name, e)
+	case <-time.After(time.Second):
+		log.Warningf("Did not receive write response in 1s")
+	}
+}
+
 func (agent *Agent) Stop() {
 	agent.ConfigWriter.Stop()
 	if !(agent.EnableIPV6) {
@@ -202,6 +276,7 @@ func (agent *Agent) IsBigIPAppServicesAvailable() error {
 }
 
 func (agent *Agent) PostConfig(rsConfig ResourceConfigRequest) {
+	atomic.AddInt32(&agent.configRequests, 1)
 	// Always push latest activeConfig to channel
 	// Case1: Put latest config into the channel
 	// Case2: If channel is blocked because of earlier config, pop out earlier config and push latest config
@@ -215,11 +290,12 @@ func (agent *Agent) PostConfig(rsConfig ResourceConfigRequest) {
 }
 
 // agentWorker blocks on postChan
-// whenever it gets unblocked, it creates an as3 declaration for modified tenants and posts the request
+// whenever it gets unblocked, it creates as3 declarations for modified tenants and hands them to the tenant workers
 func (agent *Agent) agentWorker() {
 	for rsConfig := range agent.postChan {
-		// If there are no retries going on in parallel, acquiring lock will be straight forward.
-		// Otherwise, we will wait for retryWorker to complete its current iteration
+		rsConfig = agent.batchConfigRequests(rsConfig)
+		agent.seedDeletionGuard()
+
 		agent.declUpdate.Lock()
 
 		// Fetch the latest config from channel
@@ -227,12 +303,17 @@ func (agent *Agent) agentWorker() {
 		case rsConfig = <-agent.postChan:
 		case <-time.After(1 * time.Microsecond):
 		}
+		if coalesced := atomic.SwapInt32(&agent.configRequests, 0); coalesced > 0 {
+			bigIPPrometheus.CoalescedConfigRequests.Observe(float64(coalesced))
+		}
 
-		if !(agent.EnableIPV6) && agent.ccclGTMAgent {
+		if !(agent.EnableIPV6) && agent.ccclGTMAgent && !agent.tenantPause.all {
 			agent.PostGTMConfig(rsConfig)
 		}
 
-		decl := agent.createTenantAS3Declaration(rsConfig)
+		agent.createTenantAS3Declaration(rsConfig)
+		lastConfig := rsConfig
+		agent.lastConfig = &lastConfig
 
 		if len(agent.incomingTenantDeclMap) == 0 {
 			agent.declUpdate.Unlock()
@@ -242,65 +323,42 @@ func (agent *Agent) agentWorker() {
 		var updatedTenants []string
 		// initializing the priority tenants
 		var priorityTenants []string
-		/*
-			For every incoming post request, create a new tenantResponseMap.
-			tenantResponseMap will be updated with responses during postConfig.
-			It holds the updatedTenants in the current iteration's as keys.
-			This is needed to update response code in cases (202/404) when httpResponse body does not contain the tenant details.
-		*/
-		agent.tenantResponseMap = make(map[string]tenantResponse)
-
-		for tenant := range agent.incomingTenantDeclMap {
+		updates := make(map[string]as3Tenant, len(agent.incomingTenantDeclMap))
+		if agent.pendingTenantDeclMap == nil {
+			agent.pendingTenantDeclMap = make(map[string]as3Tenant)
+		}
+		for tenant, decl := range agent.incomingTenantDeclMap {
 			if _, ok := agent.tenantPriorityMap[tenant]; ok {
 				priorityTenants = append(priorityTenants, tenant)
 			} else {
 				updatedTenants = append(updatedTenants, tenant)
 			}
-			agent.tenantResponseMap[tenant] = tenantResponse{}
+			updates[tenant] = decl
+			agent.pendingTenantDeclMap[tenant] = decl
 		}
+		agent.updateDebugState()
+		agent.declUpdate.Unlock()
+
+		go agent.updatePoolMembers(rsConfig)
 
 		// Update the priority tenants first
+		var settled sync.WaitGroup
 		if len(priorityTenants) > 0 {
-			agent.postTenantsDeclaration(decl, rsConfig, priorityTenants)
+			agent.dispatchTenantUpdates(priorityTenants, updates, rsConfig, &settled)
+			settled.Wait()
 		}
 		// Updating the remaining tenants
-		agent.postTenantsDeclaration(decl, rsConfig, updatedTenants)
+		agent.dispatchTenantUpdates(updatedTenants, updates, rsConfig, &settled)
 
-		agent.declUpdate.Unlock()
+		// notify resourceStatusUpdate response handler once all the tenants are posted
+		go func(id int) {
+			settled.Wait()
+			agent.notifyRscStatusHandler(id, true)
+		}(rsConfig.reqId)
 	}
-}
-
-// Post the tenants declaration
-func (agent *Agent) postTenantsDeclaration(decl as3Declaration, rsConfig ResourceConfigRequest, tenants []string) {
-	cfg := agentConfig{
-		data:      string(decl),
-		as3APIURL: agent.getAS3APIURL(tenants),
-		id:        rsConfig.reqId,
+	for _, worker := range agent.tenantWorkers {
+		close(worker.updateChan)
 	}
-
-	agent.publishConfig(cfg)
-
-	go agent.updatePoolMembers(rsConfig)
-
-	agent.updateTenantResponse(true)
-
-	if len(agent.retryTenantDeclMap) > 0 {
-		// Activate retry
-		select {
-		case agent.retryChan <- struct{}{}:
-		case <-agent.retryChan:
-			agent.retryChan <- struct{}{}
-		}
-	}
-
-	/*
-		If there are any tenants with 201 response code,
-		poll for its status continuously and block incoming requests
-	*/
-	agent.pollTenantStatus()
-
-	// notify resourceStatusUpdate response handler on successful tenant update
-	agent.notifyRscStatusHandler(cfg.id, true)
 }
 
 func (agent *Agent) notifyRscStatusHandler(id int, overwriteCfg bool) {
@@ -309,12 +367,18 @@ func (agent *Agent) notifyRscStatusHandler(id int, overwriteCfg bool) {
 		id,
 		make(map[string]struct{}),
 	}
+	agent.declUpdate.Lock()
 	for tenant := range agent.retryTenantDeclMap {
 		rscUpdateMeta.failedTenants[tenant] = struct{}{}
 	}
-	// If triggerred from retry block, process the previous successful request completely
+	agent.declUpdate.Unlock()
+	// If triggerred from retry block, the notification already pending, if any, reports the retried tenants
+	// so that the tenant workers do not wait for the status handler
 	if !overwriteCfg {
-		agent.respChan <- rscUpdateMeta
+		select {
+		case agent.respChan <- rscUpdateMeta:
+		default:
+		}
 	} else {
 		// Always push latest id to channel
 		// Case1: Put latest id into the channel
@@ -365,150 +429,6 @@ func (agent *Agent) updatePoolMembers(rsConfig ResourceConfigRequest) {
 	}
 }
 
-func (agent *Agent) updateTenantResponse(agentWorkerUpdate bool) {
-	/*
-		Non 200 ok tenants will be added to retryTenantDeclMap map
-		Locks to update the map will be acquired in the calling method
-	*/
-	for tenant, resp := range agent.tenantResponseMap {
-		if resp.agentResponseCode == 200 {
-			// update cachedTenantDeclMap with successfully posted declaration
-			if agentWorkerUpdate {
-				agent.cachedTenantDeclMap[tenant] = agent.incomingTenantDeclMap[tenant]
-			} else {
-				agent.cachedTenantDeclMap[tenant] = agent.retryTenantDeclMap[tenant].as3Decl.(as3Tenant)
-			}
-			// if received the 200 response remove the entry from tenantPriorityMap
-			if _, ok := agent.tenantPriorityMap[tenant]; ok {
-				delete(agent.tenantPriorityMap, tenant)
-			}
-		}
-		if agentWorkerUpdate {
-			agent.updateRetryMap(tenant, resp, agent.incomingTenantDeclMap[tenant])
-		} else {
-			agent.updateRetryMap(tenant, resp, agent.retryTenantDeclMap[tenant].as3Decl)
-		}
-	}
-}
-
-// retryWorker blocks on retryChan
-// whenever it gets unblocked, retries failed declarations and polls for accepted tenant statuses
-func (agent *Agent) retryWorker() {
-
-	/*
-		retryWorker runs as a goroutine. It is idle until an arrives at retryChan.
-		retryTenantDeclMal holds all information about tenant adc configuration and response codes.
-
-		Once retryChan is signalled, retryWorker posts tenant declarations and/or polls for accepted tenants' statuses continuously until it succeeds
-		Locks are used to block retries if an incoming request arrives at agentWorker.
-
-		For each iteration, retryWorker tries to acquire agent.declUpdate lock.
-		During an ongoing agentWorker's activity, retryWorker tries to wait until agent.declUpdate lock is acquired
-		Similarly, during an ongoing retry, agentWorker waits for graceful termination of ongoing iteration - i.e., until agent.declUpdate is unlocked
-
-	*/
-
-	for range agent.retryChan {
-
-		for len(agent.retryTenantDeclMap) != 0 {
-
-			agent.declUpdate.Lock()
-
-			// If we had a delay in acquiring lock, re-check if we have any tenants to be retried
-			if len(agent.retryTenantDeclMap) == 0 {
-				agent.declUpdate.Unlock()
-				break
-			}
-
-			log.Debugf("[AS3] Posting failed tenants configuration in %v seconds", timeoutMedium)
-
-			//If there are any 201 tenants, poll for its status
-			agent.pollTenantStatus()
-
-			//If there are any failed tenants, retry posting them
-			agent.retryFailedTenant()
-
-			agent.notifyRscStatusHandler(0, false)
-
-			agent.declUpdate.Unlock()
-		}
-	}
-}
-
-func (agent *Agent) retryFailedTenant() {
-	var retryTenants []string
-
-	// this map is to collect all non-201 tenant configs
-	retryDecl := make(map[string]as3Tenant)
-
-	agent.tenantResponseMap = make(map[string]tenantResponse)
-
-	for tenant, cfg := range agent.retryTenantDeclMap {
-		// So, when we call updateTenantResponse, we have to retain failed agentResponseCodes and taskId's correctly
-		agent.tenantResponseMap[tenant] = tenantResponse{agentResponseCode: cfg.agentResponseCode, taskId: cfg.taskId}
-		if cfg.taskId == "" {
-			retryTenants = append(retryTenants, tenant)
-			retryDecl[tenant] = cfg.as3Decl.(as3Tenant)
-		}
-	}
-
-	if len(retryTenants) > 0 {
-		// Until all accepted tenants are not processed, we do not want to re-post failed tenants since we will anyways get a 503
-		cfg := agentConfig{
-			data:      string(agent.createAS3Declaration(retryDecl)),
-			as3APIURL: agent.getAS3APIURL(retryTenants),
-			id:        0,
-		}
-		// Ignoring timeouts for custom errors
-		<-time.After(timeoutMedium)
-
-		agent.postConfig(&cfg)
-
-		agent.updateTenantResponse(false)
-	}
-
-}
-
-func (agent *Agent) pollTenantStatus() {
-
-	var acceptedTenants []string
-	// Create a set to hold unique polling ids
-	acceptedTenantIds := map[string]struct{}{}
-
-	agent.tenantResponseMap = make(map[string]tenantResponse)
-
-	for tenant, cfg := range agent.retryTenantDeclMap {
-		// So, when we call updateTenantResponse, we have to retain failed agentResponseCodes and taskId's correctly
-		agent.tenantResponseMap[tenant] = tenantResponse{agentResponseCode: cfg.agentResponseCode, taskId: cfg.taskId}
-		if cfg.taskId != "" {
-			if _, found := acceptedTenantIds[cfg.taskId]; !found {
-				acceptedTenantIds[cfg.taskId] = struct{}{}
-				acceptedTenants = append(acceptedTenants, tenant)
-			}
-		}
-	}
-
-	for len(acceptedTenantIds) > 0 {
-		// Keep retrying until accepted tenant statuses are updated
-		// This prevents agent from unlocking and thus any incoming post requests (config changes) also need to hold on
-		for taskId := range acceptedTenantIds {
-			<-time.After(timeoutMedium)
-			agent.getTenantConfigStatus(taskId)
-		}
-		for _, tenant := range acceptedTenants {
-			acceptedTenantIds = map[string]struct{}{}
-			// Even if there is any pending tenant which is not updated, keep retrying for that ID
-			if agent.tenantResponseMap[tenant].taskId != "" {
-				acceptedTenantIds[agent.tenantResponseMap[tenant].taskId] = struct{}{}
-			}
-		}
-	}
-
-	if len(acceptedTenants) > 0 {
-		agent.updateTenantResponse(false)
-	}
-}
-
 func (agent *Agent) PostGTMConfig(config ResourceConfigRequest) {
 
 	dnsConfig := make(map[string]interface{})
@@ -539,11 +459,24 @@ func (agent *Agent) PostGTMConfig(config ResourceConfigRequest) {
 
 // Creates AS3 adc only for tenants with updated configuration
 func (agent *Agent) createTenantAS3Declaration(config ResourceConfigRequest) as3Declaration {
+	_, span := startSpan(config.spanCtx, "createTenantAS3Declaration", attrRequestID.Int(config.reqId))
+	defer span.End()
 	// Re-initialise incomingTenantDeclMap map and tenantPriorityMap for each new config request
 	agent.incomingTenantDeclMap = make(map[string]as3Tenant)
 	agent.tenantPriorityMap = make(map[string]int)
 	for tenant, cfg := range agent.createAS3LTMAndGTMConfigADC(config) {
-		if !reflect.DeepEqual(cfg, agent.cachedTenantDeclMap[tenant]) {
+		// A declaration handed to the tenant worker is compared with the declaration being posted
+		if _, pending := agent.pendingTenantDeclMap[tenant]; pending {
+			if !reflect.DeepEqual(cfg, agent.pendingTenantDeclMap[tenant]) {
+				agent.incomingTenantDeclMap[tenant] = cfg.(as3Tenant)
+			} else {
+				tenantLogger(tenant, config.reqId).Debugf("[AS3] No change in %v tenant configuration", tenant)
+			}
+		} else if _, cached := agent.cachedTenantDeclMap[tenant]; !cached && agent.snapshot.matches(tenant, cfg) {
+			// The declaration applied before the restart is not posted again
+			agent.cachedTenantDeclMap[tenant] = cfg.(as3Tenant)
+			tenantLogger(tenant, config.reqId).Infof("[AS3] Skipping %v tenant configuration unchanged since restart", tenant)
+		} else if !reflect.DeepEqual(cfg, agent.cachedTenantDeclMap[tenant]) {
 			agent.incomingTenantDeclMap[tenant] = cfg.(as3Tenant)
 		} else {
 			// cachedTenantDeclMap always holds the current configuration on BigIP(lets say A)
@@ -551,9 +484,11 @@ func (agent *Agent) createTenantAS3Declaration(config ResourceConfigRequest) as3
 			// delete entry from retryTenantDeclMap if any
 			delete(agent.retryTenantDeclMap, tenant)
 
-			log.Debugf("[AS3] No change in %v tenant configuration", tenant)
+			tenantLogger(tenant, config.reqId).Debugf("[AS3] No change in %v tenant configuration", tenant)
 		}
 	}
+	agent.holdMassDeletions(config)
+	agent.holdPausedTenants(config)
 
 	// gtmAS3
 	//gtmPartitionConfig := agent.createAS3GTMConfigADC(config)
@@ -565,9 +500,25 @@ func (agent *Agent) createTenantAS3Declaration(config ResourceConfigRequest) as3
 	//	}
 	//}
 
+	var tenants []string
+	for tenant := range agent.incomingTenantDeclMap {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	span.SetAttributes(tenantsAttribute(tenants))
+
 	return agent.createAS3Declaration(agent.incomingTenantDeclMap)
 }
 
+// tenantLogger returns a logger recording the tenant and config request id with each message
+func tenantLogger(tenant string, reqId int) *log.Entry {
+	return log.WithFields(log.Fields{
+		log.FieldComponent: "agent",
+		log.FieldTenant:    tenant,
+		log.FieldRequestID: reqId,
+	})
+}
+
 func (agent *Agent) createAS3Declaration(tenantDeclMap map[string]as3Tenant) as3Declaration {
 	var as3Config map[string]interface{}
 
@@ -890,7 +841,10 @@ func processIrulesForCRD(cfg *ResourceConfig, svc *as3Service) {
 		if strings.HasSuffix(iRuleNoPort, HttpRedirectIRuleName) ||
 			strings.HasSuffix(iRuleNoPort, HttpRedirectNoHostIRuleName) ||
 			strings.HasSuffix(iRuleName, TLSIRuleName) ||
-			strings.HasSuffix(iRuleName, ABPathIRuleName) {
+			strings.HasSuffix(iRuleName, ABPathIRuleName) ||
+			strings.HasSuffix(iRuleName, DirectResponseIRuleName) ||
+			strings.HasSuffix(iRuleName, RegexPathIRuleName) ||
+			strings.HasSuffix(iRuleName, RegexMatchIRuleName) {
 
 			IRules = append(IRules, iRuleName)
 		} else {
@@ -929,15 +883,14 @@ func createServiceDecl(cfg *ResourceConfig, sharedApp as3Application, tenant str
 			)
 		}
 		svc.PolicyEndpoint = peps
-	case numPolicies == 0:
-		// No policies since we need to handle the pool name.
+	}
+	// The default pool serves the requests for which no policy rule selects a pool
+	if cfg.Virtual.PoolName != "" {
 		ps := strings.Split(cfg.Virtual.PoolName, "/")
-		if cfg.Virtual.PoolName != "" {
-			svc.Pool = fmt.Sprintf("/%s/%s/%s",
-				tenant,
-				as3SharedApplication,
-				ps[len(ps)-1])
-		}
+		svc.Pool = fmt.Sprintf("/%s/%s/%s",
+			tenant,
+			as3SharedApplication,
+			ps[len(ps)-1])
 	}
 	if cfg.Virtual.TLSTermination != TLSPassthrough {
 		svc.Layer4 = cfg.Virtual.IpProtocol
@@ -991,6 +944,8 @@ func createServiceDecl(cfg *ResourceConfig, sharedApp as3Application, tenant str
 			BigIP: cfg.Virtual.ProfileMultiplex,
 		}
 	}
+
+	createTCPProfileDecl(cfg, svc, sharedApp)
 	// updating the virtual server to https if a passthrough datagroup is found
 	name := getRSCfgResName(cfg.Virtual.Name, PassthroughHostsDgName)
 	mapKey := NameRef{
@@ -1009,16 +964,26 @@ func createServiceDecl(cfg *ResourceConfig, sharedApp as3Application, tenant str
 		_, name := getPartitionAndName(profile.Name)
 		switch profile.Context {
 		case "http2":
+			var profileHTTP2 *as3ResourcePointer
 			if !profile.BigIPProfile {
-				svc.ProfileHTTP2 = name
+				if strings.HasSuffix(name, GRPCHTTP2ProfileName) {
+					sharedApp[name] = &as3HTTP2Profile{Class: "HTTP2_Profile"}
+				}
+				profileHTTP2 = &as3ResourcePointer{Use: name}
 			} else {
-				svc.ProfileHTTP2 = &as3ResourcePointer{
+				profileHTTP2 = &as3ResourcePointer{
 					BigIP: fmt.Sprintf("%v", profile.Name),
 				}
 			}
+			if cfg.Virtual.GRPC {
+				// gRPC backends only serve HTTP/2, which is used on the server side as well
+				svc.ProfileHTTP2 = as3ProfileHTTP2{Ingress: profileHTTP2, Egress: profileHTTP2}
+			} else {
+				svc.ProfileHTTP2 = profileHTTP2
+			}
 		case "http":
 			if !profile.BigIPProfile {
-				svc.ProfileHTTP = name
+				svc.ProfileHTTP = &as3ResourcePointer{Use: name}
 			} else {
 				svc.ProfileHTTP = &as3ResourcePointer{
 					BigIP: fmt.Sprintf("%v", profile.Name),
@@ -1027,6 +992,12 @@ func createServiceDecl(cfg *ResourceConfig, sharedApp as3Application, tenant str
 		}
 	}
 
+	if cfg.Virtual.TLSTermination != TLSPassthrough {
+		createHTTPProfileDecl(cfg, svc, sharedApp)
+	} else if cfg.Virtual.ProfileWebSocket != "" {
+		log.Warningf("[AS3] Ignoring the WebSocket profile of virtual %s with passthrough termination", cfg.Virtual.Name)
+	}
+
 	//Attaching WAF policy
 	if cfg.Virtual.WAF != "" {
 		svc.WAF = &as3ResourcePointer{
@@ -1140,6 +1111,20 @@ func createRuleCondition(rl *Rule, rulesData *as3Rule, port int) {
 			if c.Equals {
 				condition.Path.Operand = "equals"
 			}
+		} else if c.HTTPHeader || c.HTTPCookie {
+			condition.Name = c.Name
+			condition.Type = "httpHeader"
+			if c.HTTPCookie {
+				condition.Type = "httpCookie"
+			}
+			condition.All = newAS3PolicyCompareString(c)
+		} else if c.HTTPMethod {
+			condition.Type = "httpMethod"
+			condition.All = newAS3PolicyCompareString(c)
+		} else if c.QueryParameter {
+			condition.Name = c.Name
+			condition.Type = "httpUri"
+			condition.QueryParameter = newAS3PolicyCompareString(c)
 		} else if c.Tcp {
 			if c.Address && len(c.Values) > 0 {
 				condition.Type = "tcp"
@@ -1156,6 +1141,24 @@ func createRuleCondition(rl *Rule, rulesData *as3Rule, port int) {
 	}
 }
 
+// newAS3PolicyCompareString creates the comparison of a header, cookie, method or query parameter condition
+func newAS3PolicyCompareString(c *condition) *as3PolicyCompareString {
+	compare := &as3PolicyCompareString{
+		CaseSensitive: c.CaseSensitive,
+		Values:        c.Values,
+		Operand:       "equals",
+	}
+	switch {
+	case c.StartsWith:
+		compare.Operand = "starts-with"
+	case c.EndsWith:
+		compare.Operand = "ends-with"
+	case c.Contains:
+		compare.Operand = "contains"
+	}
+	return compare
+}
+
 // Create AS3 Rule Action for CRD
 func createRuleAction(rl *Rule, rulesData *as3Rule) {
 	for _, v := range rl.Actions {
@@ -1166,10 +1169,13 @@ func createRuleAction(rl *Rule, rulesData *as3Rule) {
 		if v.Request {
 			action.Event = "request"
 		}
+		if v.Response {
+			action.Event = "response"
+		}
 		if v.Redirect {
 			action.Type = "httpRedirect"
 		}
-		if v.HTTPHost {
+		if v.HTTPHost || v.HTTPHeader {
 			action.Type = "httpHeader"
 		}
 		if v.HTTPURI {
@@ -1178,6 +1184,9 @@ func createRuleAction(rl *Rule, rulesData *as3Rule) {
 		if v.Location != "" {
 			action.Location = v.Location
 		}
+		if v.Code != 0 {
+			action.Code = v.Code
+		}
 		// Handle vsHostname rewrite.
 		if v.Replace && v.HTTPHost {
 			action.Replace = &as3ActionReplaceMap{
@@ -1185,6 +1194,18 @@ func createRuleAction(rl *Rule, rulesData *as3Rule) {
 				Name:  "host",
 			}
 		}
+		// Handle request and response header manipulation.
+		if v.HTTPHeader {
+			header := &as3ActionReplaceMap{Name: v.HeaderName, Value: v.Value}
+			switch {
+			case v.Insert:
+				action.Insert = header
+			case v.Replace:
+				action.Replace = header
+			case v.Remove:
+				action.Remove = &as3ActionReplaceMap{Name: v.HeaderName}
+			}
+		}
 		// handle uri rewrite.
 		if v.Replace && v.HTTPURI {
 			action.Replace = &as3ActionReplaceMap{
@@ -1216,6 +1237,14 @@ func createRuleAction(rl *Rule, rulesData *as3Rule) {
 		if v.Drop {
 			action.Type = "drop"
 		}
+		// Tcl variable read by the iRules
+		if v.SetVariable {
+			action.Type = "tcl"
+			action.SetVariable = &as3ActionSetVariable{
+				Name:       v.VariableName,
+				Expression: v.Value,
+			}
+		}
 
 		rulesData.Actions = append(rulesData.Actions, action)
 	}
@@ -1488,7 +1517,11 @@ func createMonitorDecl(cfg *ResourceConfig, sharedApp as3Application) {
 			}
 			monitor.TimeUnitilUp = &val
 			monitor.Send = v.Send
-		case "https":
+		case "https", "http2", GRPCMonitor:
+			if v.Type == GRPCMonitor {
+				// gRPC health checks are sent over HTTP/2
+				monitor.MonitorType = "http2"
+			}
 			//Todo: For https monitor type
 			adaptiveFalse := false
 			monitor.Adaptive = &adaptiveFalse
@@ -1571,6 +1604,10 @@ func createTransportServiceDecl(cfg *ResourceConfig, sharedApp as3Application) {
 		}
 	}
 
+	if svc.Class == "Service_TCP" {
+		createTCPProfileDecl(cfg, svc, sharedApp)
+	}
+
 	// Attaching Profiles from Policy CRD
 	for _, profile := range cfg.Virtual.Profiles {
 		_, name := getPartitionAndName(profile.Name)
@@ -1618,6 +1655,58 @@ func createTransportServiceDecl(cfg *ResourceConfig, sharedApp as3Application) {
 }
 
 // Process common declaration for VS and TS
+// createTCPProfileDecl creates the TCP profile of the tcpSettings, used on both sides of the virtual,
+// unless TCP profiles are specified
+func createTCPProfileDecl(cfg *ResourceConfig, svc *as3Service, sharedApp as3Application) {
+	if cfg.Virtual.TCPSettings == nil {
+		return
+	}
+	if svc.ProfileTCP != nil {
+		log.Warningf("[AS3] Ignoring the TCP settings of virtual %s with TCP profiles", cfg.Virtual.Name)
+		return
+	}
+	profileName := getRSCfgResName(cfg.Virtual.Name, TCPProfileName)
+	sharedApp[profileName] = &as3TCPProfile{
+		Class:             "TCP_Profile",
+		IdleTimeout:       cfg.Virtual.TCPSettings.IdleTimeout,
+		Nagle:             cfg.Virtual.TCPSettings.Nagle,
+		KeepAliveInterval: cfg.Virtual.TCPSettings.KeepAliveInterval,
+	}
+	svc.ProfileTCP = &as3ResourcePointer{Use: profileName}
+}
+
+// createHTTPProfileDecl creates the HTTP profile of the virtual with the httpSettings, the WebSocket
+// profile and the chunking preserving the trailers of gRPC, unless a BIG-IP HTTP profile is specified.
+// The httpSettings and a WebSocket profile with a BIG-IP HTTP profile are rejected when the VirtualServer is processed.
+func createHTTPProfileDecl(cfg *ResourceConfig, svc *as3Service, sharedApp as3Application) {
+	if cfg.Virtual.HTTPSettings == nil && !cfg.Virtual.GRPC && cfg.Virtual.ProfileWebSocket == "" {
+		return
+	}
+	if svc.ProfileHTTP != nil {
+		log.Warningf("[AS3] Ignoring the gRPC chunking of virtual %s with HTTP profile", cfg.Virtual.Name)
+		return
+	}
+	httpProfile := &as3HTTPProfile{Class: "HTTP_Profile"}
+	if cfg.Virtual.GRPC {
+		// Chunked messages carry the trailers, which gRPC uses for the status, AS3 translates
+		// preserve to sustain on TMOS 15.0 or newer
+		httpProfile.RequestChunking = "preserve"
+		httpProfile.ResponseChunking = "preserve"
+	}
+	if cfg.Virtual.HTTPSettings != nil {
+		httpProfile.XForwardedFor = cfg.Virtual.HTTPSettings.XForwardedFor
+		httpProfile.ServerHeaderValue = cfg.Virtual.HTTPSettings.ServerHeader
+		httpProfile.MaxHeaderSize = cfg.Virtual.HTTPSettings.MaxHeaderSize
+		httpProfile.ProxyType = cfg.Virtual.HTTPSettings.ProxyType
+	}
+	if cfg.Virtual.ProfileWebSocket != "" {
+		httpProfile.ProfileWebSocket = &as3ResourcePointer{BigIP: cfg.Virtual.ProfileWebSocket}
+	}
+	profileName := getRSCfgResName(cfg.Virtual.Name, HTTPProfileName)
+	sharedApp[profileName] = httpProfile
+	svc.ProfileHTTP = &as3ResourcePointer{Use: profileName}
+}
+
 func processCommonDecl(cfg *ResourceConfig, svc *as3Service) {
 
 	if cfg.Virtual.SNAT == "auto" || cfg.Virtual.SNAT == "none" {
@@ -2,6 +2,8 @@ package controller
 
 import (
 	"encoding/json"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v2/config/apis/cis/v1"
 	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/test"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -421,6 +423,218 @@ var _ = Describe("Backend Tests", func() {
 			Expect(ok).To(BeTrue())
 			Expect(val).NotTo(BeNil())
 		})
+
+		It("Default pool of a virtual with policies", func() {
+			rsCfg := &ResourceConfig{}
+			rsCfg.Virtual.Name = "crd_vs_172.13.14.15"
+			rsCfg.Virtual.Destination = "/test/172.13.14.15:80"
+			rsCfg.Virtual.PoolName = "default_backend_8080_default_test_com"
+			rsCfg.Virtual.Policies = []nameRef{{Name: "policy1", Partition: "test"}}
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+
+			svc, ok := app["crd_vs_172.13.14.15"].(*as3Service)
+			Expect(ok).To(BeTrue())
+			Expect(svc.PolicyEndpoint).To(Equal("/test/Shared/policy1"))
+			Expect(svc.Pool).To(Equal("/test/Shared/default_backend_8080_default_test_com"))
+		})
+
+		It("Policy rule of a pool with a direct response", func() {
+			vs := &cisapiv1.VirtualServer{}
+			vs.Namespace = "default"
+			vs.Name = "vs"
+			rl, err := createRule("test.com/maintenance", "directresponse", "vs_test_com_maintenance_directresponse", nil, "")
+			Expect(err).To(BeNil())
+			rl.Actions = []*action{getDirectResponseAction(vs, 1)}
+			rsCfg := &ResourceConfig{}
+			rsCfg.Virtual.Destination = "/test/172.13.14.15:80"
+			rsCfg.Policies = Policies{{Name: "policy1", Strategy: "first-match", Rules: Rules{rl}}}
+			app := as3Application{}
+			createPoliciesDecl(rsCfg, app)
+
+			ep := app["policy1"].(*as3EndpointPolicy)
+			Expect(ep.Rules[0].Actions).To(Equal([]*as3Action{{
+				Type:        "tcl",
+				Event:       "request",
+				SetVariable: &as3ActionSetVariable{Name: DirectResponseVariable, Expression: "default/vs/1"},
+			}}))
+			Expect(validateAS3Application(app)).To(BeEmpty(), "Invalid AS3 declaration")
+		})
+
+		It("HTTP/2 profiles of a virtual with gRPC pools", func() {
+			rsCfg := &ResourceConfig{}
+			rsCfg.Virtual.Name = "crd_vs_172.13.14.15"
+			rsCfg.Virtual.Destination = "/test/172.13.14.15:443"
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.Virtual.SNAT = DEFAULT_SNAT
+			rsCfg.Virtual.Profiles = ProfileRefs{{Name: "/Common/clientssl", Context: CustomProfileClient, BigIPProfile: true}}
+			rsCfg.enableGRPC()
+			send, recv := getPoolMonitorSend("test.com", cisapiv1.Monitor{Type: GRPCMonitor})
+			rsCfg.Monitors = []Monitor{{Name: "grpc_monitor", Type: GRPCMonitor, Interval: 10, Timeout: 31, Send: send, Recv: recv}}
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			processTLSProfilesForAS3(&rsCfg.Virtual, app["crd_vs_172.13.14.15"].(*as3Service), rsCfg.Virtual.Name)
+			createMonitorDecl(rsCfg, app)
+
+			http2ProfileName := getRSCfgResName(rsCfg.Virtual.Name, GRPCHTTP2ProfileName)
+			httpProfileName := getRSCfgResName(rsCfg.Virtual.Name, HTTPProfileName)
+			svc := app["crd_vs_172.13.14.15"].(*as3Service)
+			Expect(svc.ProfileHTTP2).To(Equal(as3ProfileHTTP2{
+				Ingress: &as3ResourcePointer{Use: http2ProfileName},
+				Egress:  &as3ResourcePointer{Use: http2ProfileName},
+			}), "gRPC backends not sent HTTP/2")
+			Expect(app[http2ProfileName]).To(Equal(&as3HTTP2Profile{Class: "HTTP2_Profile"}))
+			Expect(svc.HttpMrfRoutingEnabled).To(BeTrue())
+			Expect(svc.ProfileHTTP).To(Equal(&as3ResourcePointer{Use: httpProfileName}))
+			Expect(app[httpProfileName]).To(Equal(&as3HTTPProfile{
+				Class:            "HTTP_Profile",
+				RequestChunking:  "preserve",
+				ResponseChunking: "preserve",
+			}))
+			monitor := app["grpc_monitor"].(*as3Monitor)
+			Expect(monitor.MonitorType).To(Equal("http2"))
+			Expect(monitor.Send).To(HavePrefix("POST /grpc.health.v1.Health/Check HTTP/2.0\r\nHost: test.com\r\n"))
+			Expect(monitor.Receive).To(Equal("grpc-status: 0"))
+			// The bundled AS3 schema allows a single side in the ingress and egress form of profileHTTP2
+			svc.ProfileHTTP2 = as3ProfileHTTP2{Egress: &as3ResourcePointer{Use: http2ProfileName}}
+			Expect(validateAS3Application(app)).To(BeEmpty(), "Invalid AS3 declaration")
+		})
+
+		It("WebSocket and TCP idle timeout profiles of a virtual", func() {
+			rsCfg := &ResourceConfig{}
+			rsCfg.Virtual.Name = "crd_vs_172.13.14.15"
+			rsCfg.Virtual.Destination = "/test/172.13.14.15:80"
+			rsCfg.Virtual.SNAT = DEFAULT_SNAT
+			rsCfg.Virtual.ProfileWebSocket = DefaultWebSocketProfile
+			rsCfg.Virtual.TCPSettings = &ProfileTCPSettings{IdleTimeout: 3600}
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+
+			tcpProfileName := getRSCfgResName(rsCfg.Virtual.Name, TCPProfileName)
+			httpProfileName := getRSCfgResName(rsCfg.Virtual.Name, HTTPProfileName)
+			svc := app["crd_vs_172.13.14.15"].(*as3Service)
+			Expect(svc.ProfileHTTP).To(Equal(&as3ResourcePointer{Use: httpProfileName}))
+			Expect(app[httpProfileName]).To(Equal(&as3HTTPProfile{
+				Class:            "HTTP_Profile",
+				ProfileWebSocket: &as3ResourcePointer{BigIP: DefaultWebSocketProfile},
+			}))
+			Expect(svc.ProfileTCP).To(Equal(&as3ResourcePointer{Use: tcpProfileName}))
+			Expect(app[tcpProfileName]).To(Equal(&as3TCPProfile{Class: "TCP_Profile", IdleTimeout: 3600}))
+			Expect(validateAS3Application(app)).To(BeEmpty(), "Invalid AS3 declaration")
+		})
+
+		It("TCP and HTTP profiles of the settings of a virtual", func() {
+			xForwardedFor := false
+			rsCfg := &ResourceConfig{}
+			rsCfg.Virtual.Name = "crd_vs_172.13.14.15"
+			rsCfg.Virtual.Destination = "/test/172.13.14.15:80"
+			rsCfg.Virtual.SNAT = DEFAULT_SNAT
+			rsCfg.Virtual.ProfileWebSocket = DefaultWebSocketProfile
+			rsCfg.Virtual.TCPSettings = &ProfileTCPSettings{IdleTimeout: 600, Nagle: "disable", KeepAliveInterval: 60}
+			rsCfg.Virtual.HTTPSettings = &ProfileHTTPSettings{
+				XForwardedFor: &xForwardedFor,
+				ServerHeader:  "none",
+				MaxHeaderSize: 65536,
+				ProxyType:     "reverse",
+			}
+			app := as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+
+			tcpProfileName := getRSCfgResName(rsCfg.Virtual.Name, TCPProfileName)
+			httpProfileName := getRSCfgResName(rsCfg.Virtual.Name, HTTPProfileName)
+			svc := app["crd_vs_172.13.14.15"].(*as3Service)
+			Expect(svc.ProfileTCP).To(Equal(&as3ResourcePointer{Use: tcpProfileName}))
+			Expect(app[tcpProfileName]).To(Equal(&as3TCPProfile{
+				Class:             "TCP_Profile",
+				IdleTimeout:       600,
+				Nagle:             "disable",
+				KeepAliveInterval: 60,
+			}))
+			Expect(svc.ProfileHTTP).To(Equal(&as3ResourcePointer{Use: httpProfileName}))
+			Expect(app[httpProfileName]).To(Equal(&as3HTTPProfile{
+				Class:             "HTTP_Profile",
+				XForwardedFor:     &xForwardedFor,
+				ServerHeaderValue: "none",
+				MaxHeaderSize:     65536,
+				ProxyType:         "reverse",
+				ProfileWebSocket:  &as3ResourcePointer{BigIP: DefaultWebSocketProfile},
+			}))
+			Expect(validateAS3Application(app)).To(BeEmpty(), "Invalid AS3 declaration")
+
+			// Settings are ignored with BIG-IP profiles, httpSettings with a BIG-IP HTTP profile are
+			// rejected when the VirtualServer is processed
+			rsCfg.Virtual.TCP.Client = "/Common/f5-tcp-progressive"
+			rsCfg.Virtual.Profiles = ProfileRefs{{Name: "/Common/http", Context: "http", BigIPProfile: true}}
+			app = as3Application{}
+			createServiceDecl(rsCfg, app, "test")
+			svc = app["crd_vs_172.13.14.15"].(*as3Service)
+			Expect(svc.ProfileTCP).To(Equal(&as3ResourcePointer{BigIP: "/Common/f5-tcp-progressive"}))
+			Expect(svc.ProfileHTTP).To(Equal(&as3ResourcePointer{BigIP: "/Common/http"}))
+			Expect(app).NotTo(HaveKey(tcpProfileName))
+			Expect(app).NotTo(HaveKey(httpProfileName))
+		})
+
+		It("Rule conditions of pool match", func() {
+			rl := &Rule{
+				Conditions: createMatchConditions(&cisapiv1.PoolMatch{
+					Headers:         []cisapiv1.HTTPMatch{{Name: "X-Tenant", Operand: "starts-with", Values: []string{"blue"}}},
+					Cookies:         []cisapiv1.HTTPMatch{{Name: "canary", Values: []string{"true"}, CaseSensitive: true}},
+					QueryParameters: []cisapiv1.HTTPMatch{{Name: "debug", Operand: "contains", Values: []string{"1"}}},
+					Methods:         []string{"get", "POST"},
+					SourceAddresses: []string{"10.0.0.0/8"},
+				}),
+			}
+			rulesData := &as3Rule{}
+			createRuleCondition(rl, rulesData, 80)
+			Expect(rulesData.Conditions).To(HaveLen(5))
+			Expect(*rulesData.Conditions[0]).To(Equal(as3Condition{
+				Type:  "httpHeader",
+				Name:  "X-Tenant",
+				Event: "request",
+				All:   &as3PolicyCompareString{Values: []string{"blue"}, Operand: "starts-with"},
+			}))
+			Expect(rulesData.Conditions[1].Type).To(Equal("httpCookie"))
+			Expect(rulesData.Conditions[1].All.CaseSensitive).To(BeTrue())
+			Expect(rulesData.Conditions[2].Type).To(Equal("httpUri"))
+			Expect(rulesData.Conditions[2].Name).To(Equal("debug"))
+			Expect(rulesData.Conditions[2].QueryParameter.Operand).To(Equal("contains"))
+			Expect(rulesData.Conditions[3].Type).To(Equal("httpMethod"))
+			Expect(rulesData.Conditions[3].All.Values).To(Equal([]string{"GET", "POST"}))
+			Expect(rulesData.Conditions[4].Type).To(Equal("tcp"))
+		})
+
+		It("Rule actions of header manipulation", func() {
+			rl := &Rule{}
+			actions, err := getHeaderActions(&cisapiv1.HeaderActions{
+				Insert: []cisapiv1.HTTPHeader{{Name: "X-Forwarded-Proto", Value: "https"}},
+			}, false, 0)
+			Expect(err).To(BeNil())
+			rl.Actions = append(rl.Actions, actions...)
+			actions, err = getHeaderActions(&cisapiv1.HeaderActions{
+				Replace: []cisapiv1.HTTPHeader{{Name: "Strict-Transport-Security", Value: "max-age=31536000"}},
+				Remove:  []string{"Server"},
+			}, true, len(rl.Actions))
+			Expect(err).To(BeNil())
+			rl.Actions = append(rl.Actions, actions...)
+			rulesData := &as3Rule{}
+			createRuleAction(rl, rulesData)
+			Expect(rulesData.Actions).To(HaveLen(3))
+			Expect(*rulesData.Actions[0]).To(Equal(as3Action{
+				Type:   "httpHeader",
+				Event:  "request",
+				Insert: &as3ActionReplaceMap{Name: "X-Forwarded-Proto", Value: "https"},
+			}))
+			Expect(*rulesData.Actions[1]).To(Equal(as3Action{
+				Type:    "httpHeader",
+				Event:   "response",
+				Replace: &as3ActionReplaceMap{Name: "Strict-Transport-Security", Value: "max-age=31536000"},
+			}))
+			Expect(*rulesData.Actions[2]).To(Equal(as3Action{
+				Type:   "httpHeader",
+				Event:  "response",
+				Remove: &as3ActionReplaceMap{Name: "Server"},
+			}))
+		})
 	})
 
 	Describe("JSON comparision of AS3 declaration", func() {
@@ -0,0 +1,146 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	bigIPPrometheus "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/prometheus"
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+)
+
+// backoff computes exponentially increasing intervals between retries, from base up to max
+type backoff struct {
+	base    time.Duration
+	max     time.Duration
+	attempt uint
+}
+
+func newBackoff(base, max time.Duration) *backoff {
+	if max < base {
+		max = base
+	}
+	return &backoff{base: base, max: max}
+}
+
+// next returns the interval before the next retry, with a random jitter of up to half the interval
+// so that the tenants failing together are not retried together
+func (b *backoff) next() time.Duration {
+	interval := b.max
+	if b.attempt < 32 && b.base<<b.attempt < b.max {
+		interval = b.base << b.attempt
+	}
+	b.attempt++
+	half := int64(interval / 2)
+	if half <= 0 {
+		return interval
+	}
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// circuitBreaker stops the requests to BIG-IP once threshold consecutive requests found the BIG-IP unreachable
+// or unavailable. After openInterval a single request is sent to probe the BIG-IP, which closes the breaker on success.
+type circuitBreaker struct {
+	sync.Mutex
+	bigIP        string
+	threshold    int
+	openInterval time.Duration
+	failures     int
+	// openUntil is zero while the breaker is closed
+	openUntil time.Time
+	probing   bool
+}
+
+func newCircuitBreaker(bigIP string, threshold int, openInterval time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{
+		bigIP:        bigIP,
+		threshold:    threshold,
+		openInterval: openInterval,
+	}
+}
+
+// allow checks that a request can be sent to BIG-IP
+func (cb *circuitBreaker) allow() bool {
+	if cb == nil {
+		return true
+	}
+	cb.Lock()
+	defer cb.Unlock()
+	if cb.openUntil.IsZero() {
+		return true
+	}
+	if cb.probing || time.Now().Before(cb.openUntil) {
+		return false
+	}
+	cb.probing = true
+	return true
+}
+
+// success records a response of BIG-IP
+func (cb *circuitBreaker) success() {
+	if cb == nil {
+		return
+	}
+	cb.Lock()
+	defer cb.Unlock()
+	if !cb.openUntil.IsZero() {
+		log.Infof("[AS3] BIG-IP %v is available, resuming requests", cb.bigIP)
+		bigIPPrometheus.CircuitBreakerOpen.WithLabelValues(cb.bigIP).Set(0)
+	}
+	cb.failures = 0
+	cb.openUntil = time.Time{}
+	cb.probing = false
+}
+
+// failure records a request which found BIG-IP unreachable or unavailable
+func (cb *circuitBreaker) failure() {
+	if cb == nil {
+		return
+	}
+	cb.Lock()
+	defer cb.Unlock()
+	cb.failures++
+	cb.probing = false
+	if cb.openUntil.IsZero() && cb.failures < cb.threshold {
+		return
+	}
+	if cb.openUntil.IsZero() {
+		log.Warningf("[AS3] BIG-IP %v is unreachable or unavailable after %v consecutive requests, "+
+			"pausing requests for %v", cb.bigIP, cb.failures, cb.openInterval)
+		bigIPPrometheus.CircuitBreakerOpen.WithLabelValues(cb.bigIP).Set(1)
+	}
+	cb.openUntil = time.Now().Add(cb.openInterval)
+}
+
+// check returns an error while the breaker is open
+func (cb *circuitBreaker) check() error {
+	if cb == nil {
+		return nil
+	}
+	cb.Lock()
+	defer cb.Unlock()
+	if cb.openUntil.IsZero() {
+		return nil
+	}
+	return fmt.Errorf("BIG-IP %v is unreachable or unavailable after %v consecutive requests", cb.bigIP, cb.failures)
+}
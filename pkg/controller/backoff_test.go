@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/health"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Backoff and Circuit Breaker Tests", func() {
+	It("Backs off exponentially with jitter up to the max interval", func() {
+		b := newBackoff(time.Second, 5*time.Second)
+		for _, interval := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+			next := b.next()
+			Expect(next).To(BeNumerically(">=", interval/2))
+			Expect(next).To(BeNumerically("<=", interval))
+		}
+		Expect(newBackoff(time.Second, 0).max).To(Equal(time.Second))
+	})
+
+	It("Opens after consecutive failures and probes the BIG-IP after the interval", func() {
+		Expect(newCircuitBreaker("bigip.com", 0, time.Second)).To(BeNil())
+		var disabled *circuitBreaker
+		Expect(disabled.allow()).To(BeTrue())
+
+		cb := newCircuitBreaker("bigip.com", 2, 50*time.Millisecond)
+		cb.failure()
+		Expect(cb.allow()).To(BeTrue())
+		Expect(cb.check()).To(Succeed())
+		cb.failure()
+		Expect(cb.allow()).To(BeFalse())
+		Expect(cb.check()).NotTo(Succeed())
+
+		time.Sleep(60 * time.Millisecond)
+		Expect(cb.allow()).To(BeTrue(), "BIG-IP not probed")
+		Expect(cb.allow()).To(BeFalse(), "More than one probe allowed")
+		cb.failure()
+		Expect(cb.allow()).To(BeFalse())
+
+		time.Sleep(60 * time.Millisecond)
+		Expect(cb.allow()).To(BeTrue())
+		cb.success()
+		Expect(cb.allow()).To(BeTrue())
+		Expect(cb.check()).To(Succeed())
+	})
+
+	It("Reports the open circuit breakers of the BIG-IP targets only by readiness", func() {
+		agent := &Agent{PostManager: &PostManager{}}
+		targetAgent := &Agent{PostManager: &PostManager{}}
+		targetAgent.breaker = newCircuitBreaker("target.com", 1, time.Minute)
+		agent.addTargetAgent(targetAgent)
+		hc := health.HealthChecker{NoSubProcess: true, BigIPCheck: agent.checkBigIPs}
+		Expect(agent.checkBigIPs()).To(Succeed())
+
+		targetAgent.breaker.failure()
+		Expect(agent.checkBigIPs()).NotTo(Succeed())
+		rec := httptest.NewRecorder()
+		hc.HealthCheckHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK), "Liveness fails while BIG-IP is unavailable")
+		rec = httptest.NewRecorder()
+		hc.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		Expect(rec.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("Does not post while the circuit breaker is open", func() {
+		mockPM := newMockPostManger()
+		mockPM.BIGIPURL = "bigip.com"
+		mockPM.breaker = newCircuitBreaker("bigip.com", 1, time.Minute)
+		mockPM.breaker.failure()
+		cfg := &agentConfig{
+			data:              "{}",
+			as3APIURL:         mockPM.getAS3APIURL([]string{"test"}),
+			tenants:           []string{"test"},
+			tenantResponseMap: tenantResponses{"test": tenantResponse{}},
+		}
+		mockPM.postConfig(cfg)
+		Expect(cfg.tenantResponseMap["test"].agentResponseCode).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("Stays closed while AS3 is busy with another declaration", func() {
+		busy := true
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			if busy {
+				w.Write([]byte(`{"code":503,"message":"Configuration operation in progress on device, please try again in 2 minutes"}`))
+			} else {
+				w.Write([]byte(`{"code":503,"message":"restjavad is unavailable"}`))
+			}
+		}))
+		defer server.Close()
+		pm := NewPostManager(PostParams{BIGIPURL: server.URL, PostConcurrency: 4, CircuitBreakerThreshold: 2,
+			CircuitBreakerInterval: time.Minute})
+		post := func(tenant string) *agentConfig {
+			cfg := &agentConfig{
+				data:              "{}",
+				as3APIURL:         pm.getAS3APIURL([]string{tenant}),
+				tenants:           []string{tenant},
+				tenantResponseMap: tenantResponses{tenant: tenantResponse{}},
+			}
+			pm.postConfig(cfg)
+			return cfg
+		}
+
+		var wg sync.WaitGroup
+		for _, tenant := range []string{"test1", "test2", "test3", "test4"} {
+			wg.Add(1)
+			go func(tenant string) {
+				defer wg.Done()
+				cfg := post(tenant)
+				Expect(cfg.tenantResponseMap[tenant].agentResponseCode).To(Equal(http.StatusServiceUnavailable))
+			}(tenant)
+		}
+		wg.Wait()
+		Expect(pm.breaker.check()).To(Succeed(), "Circuit breaker opened while AS3 is busy")
+
+		busy = false
+		post("test1")
+		post("test2")
+		Expect(pm.breaker.check()).NotTo(Succeed(), "Circuit breaker closed while BIG-IP is unavailable")
+	})
+})
@@ -0,0 +1,58 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+)
+
+// batchConfigRequests waits until no config request arrives for the quiet period, or until the
+// max delay after the first config request, and returns the latest config request
+func (agent *Agent) batchConfigRequests(rsConfig ResourceConfigRequest) ResourceConfigRequest {
+	if agent.batch.QuietPeriod <= 0 {
+		return rsConfig
+	}
+	quiet := time.NewTimer(agent.batch.QuietPeriod)
+	defer quiet.Stop()
+	// The batch is not capped without max delay
+	var deadline <-chan time.Time
+	if agent.batch.MaxDelay > 0 {
+		maxDelay := time.NewTimer(agent.batch.MaxDelay)
+		defer maxDelay.Stop()
+		deadline = maxDelay.C
+	}
+	for {
+		select {
+		case latest, ok := <-agent.postChan:
+			if !ok {
+				return rsConfig
+			}
+			rsConfig = latest
+			if !quiet.Stop() {
+				<-quiet.C
+			}
+			quiet.Reset(agent.batch.QuietPeriod)
+		case <-quiet.C:
+			return rsConfig
+		case <-deadline:
+			log.Debugf("[AS3] Config requests did not settle within %v, building the declaration", agent.batch.MaxDelay)
+			return rsConfig
+		}
+	}
+}
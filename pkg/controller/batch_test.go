@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Config Request Batching Tests", func() {
+	var agent *Agent
+
+	BeforeEach(func() {
+		agent = newMockAgent(nil)
+	})
+
+	// postRequests posts config requests with increasing request ids until done is closed
+	postRequests := func(interval time.Duration, count int, done chan struct{}) {
+		go func() {
+			for id := 2; id <= count; id++ {
+				select {
+				case <-done:
+					return
+				case <-time.After(interval):
+				}
+				agent.PostConfig(ResourceConfigRequest{reqId: id})
+			}
+		}()
+	}
+
+	It("Returns the config request without batching", func() {
+		Expect(agent.batchConfigRequests(ResourceConfigRequest{reqId: 1}).reqId).To(Equal(1))
+	})
+
+	It("Waits for the quiet period and returns the latest config request", func() {
+		agent.batch = BatchParams{QuietPeriod: 100 * time.Millisecond}
+		done := make(chan struct{})
+		defer close(done)
+		postRequests(20*time.Millisecond, 4, done)
+		rsConfig := agent.batchConfigRequests(ResourceConfigRequest{reqId: 1})
+		Expect(rsConfig.reqId).To(Equal(4))
+		Expect(atomic.LoadInt32(&agent.configRequests)).To(BeEquivalentTo(3))
+	})
+
+	It("Stops waiting after the max delay", func() {
+		agent.batch = BatchParams{QuietPeriod: 100 * time.Millisecond, MaxDelay: 200 * time.Millisecond}
+		done := make(chan struct{})
+		defer close(done)
+		postRequests(20*time.Millisecond, 1000, done)
+		start := time.Now()
+		rsConfig := agent.batchConfigRequests(ResourceConfigRequest{reqId: 1})
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(rsConfig.reqId).To(BeNumerically(">", 1))
+	})
+})
@@ -0,0 +1,139 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+)
+
+// setBigIPTargets registers the additional BIG-IP targets and handles the responses of their agents
+func (ctlr *Controller) setBigIPTargets(targets []*BigIPTarget) {
+	ctlr.bigIPTargets = make(map[string]*BigIPTarget, len(targets))
+	for _, target := range targets {
+		ctlr.bigIPTargets[target.Name] = target
+		if ctlr.Agent != nil {
+			ctlr.Agent.addTargetAgent(target.Agent)
+		}
+		go ctlr.bigIPTargetResponseHandler(target)
+	}
+}
+
+// addTargetAgent adds the agent of a BIG-IP target to the readiness of the agent
+func (agent *Agent) addTargetAgent(targetAgent *Agent) {
+	agent.targetAgentsMutex.Lock()
+	defer agent.targetAgentsMutex.Unlock()
+	agent.targetAgents = append(agent.targetAgents, targetAgent)
+}
+
+// checkBigIPs returns an error while the requests to the default BIG-IP or to a BIG-IP target are paused
+func (agent *Agent) checkBigIPs() error {
+	if err := agent.breaker.check(); err != nil {
+		return err
+	}
+	agent.targetAgentsMutex.Lock()
+	defer agent.targetAgentsMutex.Unlock()
+	for _, targetAgent := range agent.targetAgents {
+		if err := targetAgent.breaker.check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isValidBigIPRef checks that bigipRef is empty or references a BIG-IP target
+func (ctlr *Controller) isValidBigIPRef(bigipRef string) bool {
+	if bigipRef == "" {
+		return true
+	}
+	_, ok := ctlr.bigIPTargets[bigipRef]
+	return ok
+}
+
+// getPartitionBigIPTarget returns the BIG-IP target of the partition, nil for the partitions of the default BIG-IP
+func (ctlr *Controller) getPartitionBigIPTarget(partition string) *BigIPTarget {
+	for _, target := range ctlr.bigIPTargets {
+		if target.Agent.Partition == partition {
+			return target
+		}
+	}
+	return nil
+}
+
+// splitBigIPTargetConfigs moves the partitions of the BIG-IP targets out of the config request
+// into a config request for each target, with the route domain of the target
+func (ctlr *Controller) splitBigIPTargetConfigs(config *ResourceConfigRequest) map[string]ResourceConfigRequest {
+	if len(ctlr.bigIPTargets) == 0 {
+		return nil
+	}
+	targetConfigs := make(map[string]ResourceConfigRequest, len(ctlr.bigIPTargets))
+	for name, target := range ctlr.bigIPTargets {
+		targetConfigs[name] = ResourceConfigRequest{
+			ltmConfig:          make(LTMConfig),
+			shareNodes:         config.shareNodes,
+			defaultRouteDomain: target.DefaultRouteDomain,
+		}
+	}
+	for partition, partitionConfig := range config.ltmConfig {
+		if target := ctlr.getPartitionBigIPTarget(partition); target != nil {
+			targetConfigs[target.Name].ltmConfig[partition] = partitionConfig
+			delete(config.ltmConfig, partition)
+		}
+	}
+	return targetConfigs
+}
+
+// postBigIPTargetConfigs posts the config requests of the BIG-IP targets to their agents
+func (ctlr *Controller) postBigIPTargetConfigs(targetConfigs map[string]ResourceConfigRequest, config ResourceConfigRequest) {
+	for name, targetConfig := range targetConfigs {
+		target := ctlr.bigIPTargets[name]
+		targetConfig.reqId = config.reqId
+		targetConfig.spanCtx = config.spanCtx
+		target.requestMutex.Lock()
+		target.lastRequest = newRequestMeta(targetConfig)
+		target.requestMutex.Unlock()
+		target.Agent.PostConfig(targetConfig)
+	}
+}
+
+// bigIPTargetResponseHandler updates the statuses of the resources of a BIG-IP target
+// and resets the priority of its partition with the responses of the agent of the target
+func (ctlr *Controller) bigIPTargetResponseHandler(target *BigIPTarget) {
+	partition := target.Agent.Partition
+	for rscUpdateMeta := range target.Agent.respChan {
+		if _, found := rscUpdateMeta.failedTenants[partition]; found {
+			log.Errorf("[AS3] Failed to post partition %v to BIG-IP %v", partition, target.Name)
+		}
+		target.requestMutex.Lock()
+		rm := target.lastRequest
+		target.requestMutex.Unlock()
+		if len(rm.meta) == 0 {
+			// no resources left in the partition
+			if _, found := rscUpdateMeta.failedTenants[partition]; !found {
+				ctlr.resources.updatePartitionPriority(partition, 0)
+			}
+			continue
+		}
+		ctlr.updateRequestStatus(rm, rscUpdateMeta)
+	}
+}
+
+// stopBigIPTargets stops the agents of the BIG-IP targets
+func (ctlr *Controller) stopBigIPTargets() {
+	for _, target := range ctlr.bigIPTargets {
+		target.Agent.Stop()
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"container/list"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BIG-IP Targets Tests", func() {
+	var mockCtlr *mockController
+	var siteB *BigIPTarget
+
+	BeforeEach(func() {
+		mockCtlr = newMockController()
+		mockCtlr.Partition = "test"
+		mockCtlr.Agent = &Agent{Partition: "test", postChan: make(chan ResourceConfigRequest, 1)}
+		siteB = &BigIPTarget{
+			Name:               "site-b",
+			Agent:              &Agent{Partition: "site-b", postChan: make(chan ResourceConfigRequest, 1)},
+			DefaultRouteDomain: 2,
+		}
+		mockCtlr.bigIPTargets = map[string]*BigIPTarget{siteB.Name: siteB}
+	})
+
+	It("Selects the partition of the referenced BIG-IP", func() {
+		Expect(mockCtlr.getCRPartition("", "")).To(Equal("test"))
+		Expect(mockCtlr.getCRPartition("dev", "")).To(Equal("dev"))
+		Expect(mockCtlr.getCRPartition("dev", "site-b")).To(Equal("site-b"))
+		Expect(mockCtlr.isValidBigIPRef("")).To(BeTrue())
+		Expect(mockCtlr.isValidBigIPRef("site-b")).To(BeTrue())
+		Expect(mockCtlr.isValidBigIPRef("site-c")).To(BeFalse())
+		Expect(mockCtlr.getPartitionBigIPTarget("site-b")).To(Equal(siteB))
+		Expect(mockCtlr.getPartitionBigIPTarget("dev")).To(BeNil())
+	})
+
+	It("Posts the partitions of each BIG-IP to its agent", func() {
+		mockCtlr.requestQueue = &requestQueue{sync.Mutex{}, list.New()}
+		siteBVS := &ResourceConfig{MetaData: metaData{baseResources: map[string]string{"default/vs-b": VirtualServer}}}
+		testVS := &ResourceConfig{MetaData: metaData{baseResources: map[string]string{"default/vs": VirtualServer}}}
+		config := ResourceConfigRequest{
+			ltmConfig: LTMConfig{
+				"test":   &PartitionConfig{ResourceMap: ResourceMap{"vs": testVS}},
+				"dev":    &PartitionConfig{ResourceMap: make(ResourceMap)},
+				"site-b": &PartitionConfig{ResourceMap: ResourceMap{"vs-b": siteBVS}, Priority: 1},
+			},
+			shareNodes:         true,
+			defaultRouteDomain: 1,
+		}
+		targetConfigs := mockCtlr.splitBigIPTargetConfigs(&config)
+		Expect(config.ltmConfig).To(HaveLen(2))
+		Expect(config.ltmConfig).To(HaveKey("test"))
+		Expect(config.ltmConfig).To(HaveKey("dev"))
+		config.reqId = mockCtlr.enqueueReq(config)
+		Expect(mockCtlr.requestQueue.Front().Value.(requestMeta).meta).To(Equal(map[string]string{"default/vs": VirtualServer}),
+			"Status of the resources of the BIG-IP target updated with the response of the default BIG-IP")
+		config.reqId = 3
+		mockCtlr.postBigIPTargetConfigs(targetConfigs, config)
+		Expect(siteB.lastRequest.meta).To(Equal(map[string]string{"default/vs-b": VirtualServer}))
+		Expect(siteB.lastRequest.partition).To(Equal("site-b"))
+
+		var targetConfig ResourceConfigRequest
+		Expect(siteB.Agent.postChan).To(Receive(&targetConfig))
+		Expect(targetConfig.ltmConfig).To(HaveLen(1))
+		Expect(targetConfig.ltmConfig["site-b"].Priority).To(Equal(1))
+		Expect(targetConfig.defaultRouteDomain).To(Equal(2))
+		Expect(targetConfig.shareNodes).To(BeTrue())
+		Expect(targetConfig.reqId).To(Equal(3))
+	})
+})
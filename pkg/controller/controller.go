@@ -19,6 +19,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -90,6 +91,21 @@ const (
 	TLSAllowInsecure    = "allow"
 	TLSNoInsecure       = "none"
 
+	// Path types of VirtualServer pools, prefix by default
+	PathTypeExact  = "exact"
+	PathTypePrefix = "prefix"
+	PathTypeRegex  = "regex"
+
+	// Operand of the header, cookie and query parameter matches of VirtualServer pools matched by an iRule
+	MatchOperandRegex = "regex"
+
+	// Protocols of VirtualServer pools, http by default
+	PoolProtocolHTTP = "http"
+	PoolProtocolGRPC = "grpc"
+
+	// GRPCMonitor is the type of the pool monitors sending gRPC health checks
+	GRPCMonitor = "grpc"
+
 	LBServiceIPAMLabelAnnotation  = "cis.f5.com/ipamLabel"
 	HealthMonitorAnnotation       = "cis.f5.com/health"
 	LBServicePolicyNameAnnotation = "cis.f5.com/policyName"
@@ -129,6 +145,9 @@ func NewController(params Params) *Controller {
 		nodeLabelSelector:  params.NodeLabelSelector,
 		vxlanName:          params.VXLANName,
 		vxlanMode:          params.VXLANMode,
+		pauseCfgMap:        params.PauseConfigmap,
+		snapshotCfgMap:     params.DeclarationSnapshotCfgMap,
+		debugAPI:           params.DebugAPI,
 	}
 
 	log.Debug("Controller Created")
@@ -161,6 +180,19 @@ func NewController(params Params) *Controller {
 		log.Errorf("Failed to Setup Clients: %v", err)
 	}
 
+	if ctlr.Agent.deletionGuard.OverrideCfgMap != "" {
+		ctlr.setupDeletionGuard()
+	}
+
+	ctlr.setupPauseControl()
+
+	ctlr.setBigIPTargets(params.BigIPTargets)
+	ctlr.setupDeclarationSnapshot()
+
+	if ctlr.debugAPI {
+		ctlr.registerDebugHandlers(http.DefaultServeMux)
+	}
+
 	if ctlr.namespaceLabel == "" {
 		if len(params.Namespaces) == 0 {
 			ctlr.namespaces[""] = true
@@ -415,6 +447,18 @@ func (ctlr *Controller) Start() {
 
 	go wait.Until(ctlr.nextGenResourceWorker, time.Second, stopChan)
 
+	if ctlr.deletionGuardInformer != nil {
+		go ctlr.deletionGuardInformer.Run(stopChan)
+	}
+
+	if ctlr.pauseInformer != nil {
+		go ctlr.pauseInformer.Run(stopChan)
+	}
+
+	if ctlr.pauseNsInformer != nil {
+		go ctlr.pauseNsInformer.Run(stopChan)
+	}
+
 	<-stopChan
 	ctlr.Stop()
 }
@@ -443,6 +487,7 @@ func (ctlr *Controller) Stop() {
 	}
 
 	ctlr.Agent.Stop()
+	ctlr.stopBigIPTargets()
 	if ctlr.ipamCli != nil {
 		ctlr.ipamCli.Stop()
 	}
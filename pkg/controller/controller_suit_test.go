@@ -9,9 +9,11 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	routeapi "github.com/openshift/api/route/v1"
+	"github.com/xeipuuv/gojsonschema"
 	"io/ioutil"
 	v1 "k8s.io/api/core/v1"
 	"net/http"
+	"path/filepath"
 	"testing"
 )
 
@@ -22,6 +24,8 @@ func TestController(t *testing.T) {
 
 var configPath = "../../test/configs/"
 
+var as3SchemaPath = "../../schemas/as3-schema-3.41.0-1-cis.json"
+
 type (
 	mockController struct {
 		*Controller
@@ -58,7 +62,6 @@ func newMockPostManger() *mockPostManager {
 		Responses:   []int{},
 		RespIndex:   0,
 	}
-	mockPM.tenantResponseMap = make(map[string]tenantResponse)
 	mockPM.firstPost = true
 	return mockPM
 }
@@ -93,6 +96,39 @@ func (mockPM *mockPostManager) setResponses(responces []responceCtx, method stri
 	mockPM.httpClient = client
 }
 
+// validateAS3Application returns the errors of the AS3 declaration of an application
+// of the test tenant, validated with the bundled AS3 schema
+func validateAS3Application(app as3Application) []string {
+	agent := &Agent{
+		AS3VersionInfo: as3VersionInfo{
+			as3Version:       "3.41.0",
+			as3Release:       "1",
+			as3SchemaVersion: "3.41.0",
+		},
+	}
+	app["class"] = "Application"
+	app["template"] = "shared"
+	decl := agent.createAS3Declaration(map[string]as3Tenant{
+		"test": {
+			"class":              "Tenant",
+			as3SharedApplication: app,
+		},
+	})
+	schemaPath, _ := filepath.Abs(as3SchemaPath)
+	result, err := gojsonschema.Validate(
+		gojsonschema.NewReferenceLoader("file://"+schemaPath),
+		gojsonschema.NewStringLoader(string(decl)),
+	)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var errs []string
+	for _, desc := range result.Errors() {
+		errs = append(errs, desc.String())
+	}
+	return errs
+}
+
 func newMockAgent(writer writer.Writer) *Agent {
 	return &Agent{
 		PostManager:     nil,
@@ -0,0 +1,288 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CredentialProvider supplies BIG-IP credentials from an external source
+type CredentialProvider interface {
+	// Name describes the provider in log messages
+	Name() string
+	// Credentials returns the current credentials and the duration they are leased for.
+	// A zero lease means the credentials do not expire.
+	// Fields which are not provided by the source are returned empty.
+	Credentials() (BigIPCredentials, time.Duration, error)
+}
+
+type fileCredentialProvider struct {
+	dir string
+}
+
+// NewFileCredentialProvider returns a provider reading the username, password and url
+// files of a credentials directory
+func NewFileCredentialProvider(dir string) CredentialProvider {
+	return &fileCredentialProvider{dir: dir}
+}
+
+func (provider *fileCredentialProvider) Name() string {
+	return fmt.Sprintf("credentials directory %v", provider.dir)
+}
+
+func (provider *fileCredentialProvider) Credentials() (BigIPCredentials, time.Duration, error) {
+	return readCredentialsDir(provider.dir), 0, nil
+}
+
+type secretCredentialProvider struct {
+	kubeClient kubernetes.Interface
+	namespace  string
+	name       string
+}
+
+// NewSecretCredentialProvider returns a provider reading the username, password and url
+// keys of a Kubernetes Secret
+func NewSecretCredentialProvider(kubeClient kubernetes.Interface, namespace, name string) CredentialProvider {
+	return &secretCredentialProvider{
+		kubeClient: kubeClient,
+		namespace:  namespace,
+		name:       name,
+	}
+}
+
+func (provider *secretCredentialProvider) Name() string {
+	return fmt.Sprintf("secret %v/%v", provider.namespace, provider.name)
+}
+
+func (provider *secretCredentialProvider) Credentials() (BigIPCredentials, time.Duration, error) {
+	secret, err := provider.kubeClient.CoreV1().Secrets(provider.namespace).Get(
+		context.TODO(), provider.name, metav1.GetOptions{})
+	if err != nil {
+		return BigIPCredentials{}, 0, err
+	}
+	return BigIPCredentials{
+		Username: strings.TrimSpace(string(secret.Data["username"])),
+		Password: strings.TrimSpace(string(secret.Data["password"])),
+		URL:      strings.TrimSpace(string(secret.Data["url"])),
+	}, 0, nil
+}
+
+type vaultCredentialProvider struct {
+	address    string
+	secretPath string
+	tokenFile  string
+	httpClient *http.Client
+
+	token          string
+	tokenRenewable bool
+	tokenTTL       time.Duration
+	tokenExpiry    time.Time
+
+	creds          BigIPCredentials
+	leaseID        string
+	leaseRenewable bool
+	leaseExpiry    time.Time
+}
+
+// vaultResponse is the common envelope of Vault HTTP API responses
+type vaultResponse struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Auth          *struct {
+		LeaseDuration int  `json:"lease_duration"`
+		Renewable     bool `json:"renewable"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// NewVaultCredentialProvider returns a provider reading the username, password and url
+// keys of a secret from a Vault compatible HTTP secret store. Both KV version 1 and 2
+// secret engines are supported. The access token is read from tokenFile on every refresh
+// so that it can be rotated by an external agent, and is renewed while it is renewable.
+func NewVaultCredentialProvider(address, secretPath, tokenFile, caFile string) (CredentialProvider, error) {
+	if address == "" || secretPath == "" || tokenFile == "" {
+		return nil, fmt.Errorf("vault address, secret path and token file are required")
+	}
+	tlsConfig := &tls.Config{}
+	if caFile != "" {
+		rootCAs, _ := x509.SystemCertPool()
+		if rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		caCert, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read vault CA file: %v", err)
+		}
+		if ok := rootCAs.AppendCertsFromPEM(caCert); !ok {
+			return nil, fmt.Errorf("no certificates found in vault CA file %v", caFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	return &vaultCredentialProvider{
+		address:    strings.TrimSuffix(address, "/"),
+		secretPath: strings.Trim(secretPath, "/"),
+		tokenFile:  tokenFile,
+		httpClient: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+			Timeout:   timeoutSmall,
+		},
+	}, nil
+}
+
+func (provider *vaultCredentialProvider) Name() string {
+	return fmt.Sprintf("vault secret %v", provider.secretPath)
+}
+
+func (provider *vaultCredentialProvider) Credentials() (BigIPCredentials, time.Duration, error) {
+	if err := provider.loadToken(); err != nil {
+		return BigIPCredentials{}, 0, err
+	}
+	provider.renewToken()
+
+	// Renew the secret lease while it is valid, otherwise read the secret again
+	if provider.leaseID != "" && provider.leaseRenewable && time.Now().Before(provider.leaseExpiry) {
+		lease, err := provider.renewLease()
+		if err == nil {
+			return provider.creds, lease, nil
+		}
+		log.Debugf("Unable to renew lease of %v, reading it again: %v", provider.Name(), err)
+	}
+	return provider.readSecret()
+}
+
+func (provider *vaultCredentialProvider) loadToken() error {
+	tokenBytes, err := ioutil.ReadFile(provider.tokenFile)
+	if err != nil {
+		return fmt.Errorf("unable to read vault token file: %v", err)
+	}
+	token := strings.TrimSpace(string(tokenBytes))
+	if token != provider.token {
+		provider.token = token
+		provider.tokenRenewable = true
+		provider.tokenExpiry = time.Time{}
+	}
+	return nil
+}
+
+// renewToken renews the access token once half of its TTL has elapsed
+func (provider *vaultCredentialProvider) renewToken() {
+	if !provider.tokenRenewable {
+		return
+	}
+	if !provider.tokenExpiry.IsZero() && time.Until(provider.tokenExpiry) > provider.tokenTTL/2 {
+		return
+	}
+	resp, err := provider.request(http.MethodPost, "auth/token/renew-self", nil)
+	if err != nil || resp.Auth == nil {
+		// Tokens without a TTL such as root tokens can not be renewed
+		log.Debugf("Vault token is not renewable: %v", err)
+		provider.tokenRenewable = false
+		return
+	}
+	provider.tokenRenewable = resp.Auth.Renewable
+	provider.tokenTTL = time.Duration(resp.Auth.LeaseDuration) * time.Second
+	provider.tokenExpiry = time.Now().Add(provider.tokenTTL)
+}
+
+func (provider *vaultCredentialProvider) renewLease() (time.Duration, error) {
+	resp, err := provider.request(http.MethodPut, "sys/leases/renew",
+		map[string]string{"lease_id": provider.leaseID})
+	if err != nil {
+		return 0, err
+	}
+	lease := time.Duration(resp.LeaseDuration) * time.Second
+	provider.leaseRenewable = resp.Renewable
+	provider.leaseExpiry = time.Now().Add(lease)
+	return lease, nil
+}
+
+func (provider *vaultCredentialProvider) readSecret() (BigIPCredentials, time.Duration, error) {
+	resp, err := provider.request(http.MethodGet, provider.secretPath, nil)
+	if err != nil {
+		return BigIPCredentials{}, 0, err
+	}
+	data := resp.Data
+	// KV version 2 nests the secret under data along with its metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	getField := func(key string) string {
+		value, _ := data[key].(string)
+		return strings.TrimSpace(value)
+	}
+	lease := time.Duration(resp.LeaseDuration) * time.Second
+	provider.creds = BigIPCredentials{
+		Username: getField("username"),
+		Password: getField("password"),
+		URL:      getField("url"),
+	}
+	provider.leaseID = resp.LeaseID
+	provider.leaseRenewable = resp.Renewable
+	provider.leaseExpiry = time.Now().Add(lease)
+	return provider.creds, lease, nil
+}
+
+func (provider *vaultCredentialProvider) request(method, path string, body interface{}) (vaultResponse, error) {
+	var vaultResp vaultResponse
+	var reqBody []byte
+	if body != nil {
+		reqBody, _ = json.Marshal(body)
+	}
+	req, err := http.NewRequest(method, provider.address+"/v1/"+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return vaultResp, err
+	}
+	req.Header.Set("X-Vault-Token", provider.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := provider.httpClient.Do(req)
+	if err != nil {
+		return vaultResp, err
+	}
+	defer httpResp.Body.Close()
+	respBody, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return vaultResp, err
+	}
+	if len(respBody) > 0 {
+		if err = json.Unmarshal(respBody, &vaultResp); err != nil {
+			return vaultResp, fmt.Errorf("unable to parse response from %v: %v", path, err)
+		}
+	}
+	if httpResp.StatusCode != http.StatusOK && httpResp.StatusCode != http.StatusNoContent {
+		return vaultResp, fmt.Errorf("request to %v failed with status %v: %v",
+			path, httpResp.StatusCode, strings.Join(vaultResp.Errors, ", "))
+	}
+	return vaultResp, nil
+}
@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeVault is a minimal stand-in for the Vault HTTP API
+type fakeVault struct {
+	sync.Mutex
+	token        string
+	kvVersion    int
+	leaseID      string
+	renewable    bool
+	password     string
+	reads        int
+	leaseRenews  int
+	tokenRenews  int
+	renewFailure bool
+}
+
+func (vault *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vault.Lock()
+	defer vault.Unlock()
+	if r.Header.Get("X-Vault-Token") != vault.token {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors":["permission denied"]}`)
+		return
+	}
+	var resp map[string]interface{}
+	switch r.URL.Path {
+	case "/v1/auth/token/renew-self":
+		vault.tokenRenews++
+		resp = map[string]interface{}{"auth": map[string]interface{}{"lease_duration": 3600, "renewable": true}}
+	case "/v1/sys/leases/renew":
+		vault.leaseRenews++
+		if vault.renewFailure {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors":["lease not found"]}`)
+			return
+		}
+		resp = map[string]interface{}{"lease_id": vault.leaseID, "lease_duration": 60, "renewable": true}
+	case "/v1/secret/bigip", "/v1/secret/data/bigip":
+		vault.reads++
+		secret := map[string]interface{}{"username": "admin", "password": vault.password, "url": "192.168.1.2"}
+		data := secret
+		if vault.kvVersion == 2 {
+			data = map[string]interface{}{"data": secret, "metadata": map[string]interface{}{"version": 1}}
+		}
+		resp = map[string]interface{}{"lease_id": vault.leaseID, "lease_duration": 60,
+			"renewable": vault.renewable, "data": data}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors":[]}`)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+var _ = Describe("Credential Provider Tests", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cis-creds")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("Reads credentials from a directory", func() {
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "password"), []byte("pass1"), 0600)).To(Succeed())
+		provider := NewFileCredentialProvider(tmpDir)
+		creds, lease, err := provider.Credentials()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lease).To(BeZero())
+		Expect(creds).To(Equal(BigIPCredentials{Password: "pass1"}))
+	})
+
+	It("Reads credentials from a secret", func() {
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "bigip-login", Namespace: "kube-system"},
+			Data: map[string][]byte{
+				"username": []byte("admin"),
+				"password": []byte("pass1\n"),
+			},
+		}
+		provider := NewSecretCredentialProvider(k8sfake.NewSimpleClientset(secret), "kube-system", "bigip-login")
+		creds, _, err := provider.Credentials()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(creds).To(Equal(BigIPCredentials{Username: "admin", Password: "pass1"}))
+
+		provider = NewSecretCredentialProvider(k8sfake.NewSimpleClientset(), "kube-system", "bigip-login")
+		_, _, err = provider.Credentials()
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("Vault", func() {
+		var vault *fakeVault
+		var server *httptest.Server
+		var tokenFile string
+
+		BeforeEach(func() {
+			vault = &fakeVault{token: "s.token1", kvVersion: 2, password: "pass1"}
+			server = httptest.NewServer(vault)
+			tokenFile = filepath.Join(tmpDir, "token")
+			Expect(ioutil.WriteFile(tokenFile, []byte("s.token1\n"), 0600)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Validates provider parameters", func() {
+			_, err := NewVaultCredentialProvider(server.URL, "", tokenFile, "")
+			Expect(err).To(HaveOccurred())
+			_, err = NewVaultCredentialProvider(server.URL, "secret/bigip", tokenFile, filepath.Join(tmpDir, "ca.crt"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Reads KV version 2 secrets and renews the token", func() {
+			provider, err := NewVaultCredentialProvider(server.URL, "/secret/data/bigip", tokenFile, "")
+			Expect(err).ToNot(HaveOccurred())
+			creds, lease, err := provider.Credentials()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lease).To(Equal(60 * time.Second))
+			Expect(creds).To(Equal(BigIPCredentials{Username: "admin", Password: "pass1", URL: "192.168.1.2"}))
+			Expect(vault.tokenRenews).To(Equal(1))
+
+			// Token is renewed only after half of its TTL has elapsed
+			vault.password = "pass2"
+			creds, _, err = provider.Credentials()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(creds.Password).To(Equal("pass2"))
+			Expect(vault.tokenRenews).To(Equal(1))
+		})
+
+		It("Reads KV version 1 secrets", func() {
+			vault.kvVersion = 1
+			provider, err := NewVaultCredentialProvider(server.URL, "secret/bigip", tokenFile, "")
+			Expect(err).ToNot(HaveOccurred())
+			creds, _, err := provider.Credentials()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(creds.Username).To(Equal("admin"))
+		})
+
+		It("Renews leased secrets", func() {
+			vault.leaseID = "secret/bigip/lease1"
+			vault.renewable = true
+			provider, err := NewVaultCredentialProvider(server.URL, "secret/data/bigip", tokenFile, "")
+			Expect(err).ToNot(HaveOccurred())
+			_, _, err = provider.Credentials()
+			Expect(err).ToNot(HaveOccurred())
+
+			creds, lease, err := provider.Credentials()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lease).To(Equal(60 * time.Second))
+			Expect(creds.Password).To(Equal("pass1"))
+			Expect(vault.reads).To(Equal(1))
+			Expect(vault.leaseRenews).To(Equal(1))
+
+			// Secret is read again when the lease can not be renewed
+			vault.renewFailure = true
+			vault.password = "pass2"
+			creds, _, err = provider.Credentials()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(creds.Password).To(Equal("pass2"))
+			Expect(vault.reads).To(Equal(2))
+		})
+
+		It("Reloads a rotated token", func() {
+			provider, err := NewVaultCredentialProvider(server.URL, "secret/data/bigip", tokenFile, "")
+			Expect(err).ToNot(HaveOccurred())
+			vault.token = "s.token2"
+			_, _, err = provider.Credentials()
+			Expect(err).To(HaveOccurred())
+
+			Expect(ioutil.WriteFile(tokenFile, []byte("s.token2"), 0600)).To(Succeed())
+			creds, _, err := provider.Credentials()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(creds.Password).To(Equal("pass1"))
+		})
+	})
+})
@@ -0,0 +1,176 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+)
+
+// WatchCredentials periodically refreshes the BIG-IP and GTM credentials from their
+// providers and rotates the credentials used by the agent whenever they change.
+// Leased credentials are refreshed at half of their lease duration when that is
+// shorter than the refresh interval.
+func (agent *Agent) WatchCredentials(bigIP, gtm CredentialProvider, interval time.Duration, stopCh <-chan struct{}) {
+	if interval <= 0 || (bigIP == nil && gtm == nil) {
+		return
+	}
+	var watches []*credentialsWatch
+	if bigIP != nil {
+		watches = append(watches, &credentialsWatch{provider: bigIP, rotate: agent.rotateBigIPCredentials})
+	}
+	if gtm != nil {
+		watches = append(watches, &credentialsWatch{provider: gtm, rotate: agent.rotateGTMCredentials})
+	}
+	log.Debugf("Watching credentials for changes every %v", interval)
+
+	for {
+		wait := interval
+		for _, watch := range watches {
+			watch.refresh()
+			if watch.lease > 0 && watch.lease/2 < wait {
+				wait = watch.lease / 2
+			}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// credentialsWatch tracks the last credentials fetched from a provider
+type credentialsWatch struct {
+	provider CredentialProvider
+	last     BigIPCredentials
+	lease    time.Duration
+	rotate   func(BigIPCredentials)
+}
+
+func (watch *credentialsWatch) refresh() {
+	creds, lease, err := watch.provider.Credentials()
+	if err != nil {
+		log.Errorf("Unable to refresh credentials from %v: %v", watch.provider.Name(), err)
+		return
+	}
+	watch.lease = lease
+	if creds != watch.last {
+		watch.last = creds
+		watch.rotate(creds)
+	}
+}
+
+func (agent *Agent) rotateBigIPCredentials(creds BigIPCredentials) {
+	agent.PostManager.credsMutex.RLock()
+	current := BigIPCredentials{
+		Username: agent.BIGIPUsername,
+		Password: agent.BIGIPPassword,
+		URL:      agent.BIGIPURL,
+	}
+	agent.PostManager.credsMutex.RUnlock()
+
+	creds = creds.withDefaults(current)
+	bigipURL, err := normalizeBIGIPURL(creds.URL)
+	if err != nil {
+		log.Errorf("Ignoring updated BIG-IP credentials: %v", err)
+		return
+	}
+	if creds.Username == current.Username && creds.Password == current.Password && bigipURL == current.URL {
+		return
+	}
+	agent.UpdateBigIPCredentials(creds.Username, creds.Password, bigipURL)
+}
+
+func (agent *Agent) rotateGTMCredentials(creds BigIPCredentials) {
+	agent.driverCfgMutex.Lock()
+	current := BigIPCredentials{
+		Username: agent.gtmBigIPCfg.GtmBigIPUsername,
+		Password: agent.gtmBigIPCfg.GtmBigIPPassword,
+		URL:      agent.gtmBigIPCfg.GtmBigIPURL,
+	}
+	agent.driverCfgMutex.Unlock()
+
+	creds = creds.withDefaults(current)
+	gtmURL, err := normalizeBIGIPURL(creds.URL)
+	if err != nil {
+		log.Errorf("Ignoring updated GTM BIG-IP credentials: %v", err)
+		return
+	}
+	if creds.Username == current.Username && creds.Password == current.Password && gtmURL == current.URL {
+		return
+	}
+	agent.UpdateGTMCredentials(creds.Username, creds.Password, gtmURL)
+}
+
+// readCredentialsDir reads username, password and url files from a credentials directory.
+// Missing files are returned as empty values.
+func readCredentialsDir(dir string) BigIPCredentials {
+	var creds BigIPCredentials
+	if dir == "" {
+		return creds
+	}
+	readField := func(name string) string {
+		fileBytes, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(fileBytes))
+	}
+	creds.Username = readField("username")
+	creds.Password = readField("password")
+	creds.URL = readField("url")
+	return creds
+}
+
+// withDefaults fills the fields missing in the credentials directory from the current credentials
+func (creds BigIPCredentials) withDefaults(current BigIPCredentials) BigIPCredentials {
+	if creds.Username == "" {
+		creds.Username = current.Username
+	}
+	if creds.Password == "" {
+		creds.Password = current.Password
+	}
+	if creds.URL == "" {
+		creds.URL = current.URL
+	}
+	return creds
+}
+
+// normalizeBIGIPURL prefixes the https scheme and verifies the URL has no path
+func normalizeBIGIPURL(bigipURL string) (string, error) {
+	if !strings.HasPrefix(bigipURL, "https://") {
+		bigipURL = "https://" + bigipURL
+	}
+	u, err := url.Parse(bigipURL)
+	if nil != err {
+		return "", fmt.Errorf("error parsing url: %s", err)
+	}
+	if len(u.Path) > 0 && u.Path != "/" {
+		return "", fmt.Errorf("BIGIP-URL path must be empty or '/'; check URL formatting and/or remove %s from path",
+			u.Path)
+	}
+	return bigipURL, nil
+}
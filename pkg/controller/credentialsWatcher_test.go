@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Credentials Watcher Tests", func() {
+	var credsDir string
+	var agent *Agent
+
+	getCreds := func() BigIPCredentials {
+		agent.PostManager.credsMutex.RLock()
+		defer agent.PostManager.credsMutex.RUnlock()
+		return BigIPCredentials{
+			Username: agent.BIGIPUsername,
+			Password: agent.BIGIPPassword,
+			URL:      agent.BIGIPURL,
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		credsDir, err = ioutil.TempDir("", "cis-creds")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(credsDir, "username"), []byte("admin\n"), 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(credsDir, "password"), []byte("pass1"), 0600)).To(Succeed())
+
+		agent = newMockAgent(nil)
+		agent.PostManager = &PostManager{
+			PostParams: PostParams{
+				BIGIPUsername: "admin",
+				BIGIPPassword: "pass1",
+				BIGIPURL:      "https://192.168.1.1",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(credsDir)
+	})
+
+	It("Reads credentials directory", func() {
+		creds := readCredentialsDir(credsDir)
+		Expect(creds.Username).To(Equal("admin"))
+		Expect(creds.Password).To(Equal("pass1"))
+		Expect(creds.URL).To(BeEmpty())
+		Expect(readCredentialsDir("")).To(Equal(BigIPCredentials{}))
+	})
+
+	It("Normalizes BIG-IP URL", func() {
+		bigipURL, err := normalizeBIGIPURL("192.168.1.2")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bigipURL).To(Equal("https://192.168.1.2"))
+		_, err = normalizeBIGIPURL("https://192.168.1.2/mgmt")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Rotates credentials when files change", func() {
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		go agent.WatchCredentials(NewFileCredentialProvider(credsDir), nil, 10*time.Millisecond, stopCh)
+		time.Sleep(30 * time.Millisecond)
+		Expect(getCreds().Password).To(Equal("pass1"))
+
+		Expect(ioutil.WriteFile(filepath.Join(credsDir, "password"), []byte("pass2"), 0600)).To(Succeed())
+		Eventually(func() string { return getCreds().Password }).Should(Equal("pass2"))
+		Expect(getCreds().Username).To(Equal("admin"))
+		Expect(getCreds().URL).To(Equal("https://192.168.1.1"))
+
+		Expect(ioutil.WriteFile(filepath.Join(credsDir, "url"), []byte("192.168.1.2"), 0600)).To(Succeed())
+		Eventually(func() string { return getCreds().URL }).Should(Equal("https://192.168.1.2"))
+		Expect(agent.getAS3APIURL([]string{"test"})).To(Equal("https://192.168.1.2/mgmt/shared/appsvcs/declare/test"))
+	})
+
+	It("Ignores invalid BIG-IP URL", func() {
+		agent.rotateBigIPCredentials(BigIPCredentials{URL: "https://192.168.1.2/path"})
+		Expect(getCreds().URL).To(Equal("https://192.168.1.1"))
+	})
+
+	It("Rotates GTM credentials", func() {
+		agent.gtmBigIPCfg = gtmBigIPSection{
+			GtmBigIPUsername: "admin",
+			GtmBigIPPassword: "pass1",
+			GtmBigIPURL:      "https://192.168.1.3",
+		}
+		agent.rotateGTMCredentials(BigIPCredentials{Password: "gtm-pass"})
+		Expect(agent.gtmBigIPCfg.GtmBigIPPassword).To(Equal("gtm-pass"))
+		Expect(agent.gtmBigIPCfg.GtmBigIPURL).To(Equal("https://192.168.1.3"))
+	})
+})
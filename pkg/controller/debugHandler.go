@@ -0,0 +1,337 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	ficV1 "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/apis/fic/v1"
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+)
+
+const redactedValue = "*****"
+
+// debugRedactedKeys are JSON keys whose values are never exposed by the debug API
+var debugRedactedKeys = map[string]bool{
+	"privateKey": true,
+	"passphrase": true,
+	"password":   true,
+	"ciphertext": true,
+}
+
+type (
+	// debugFilter restricts the debug API output to a partition and/or namespace
+	debugFilter struct {
+		partition string
+		namespace string
+	}
+
+	// controllerDebugState is the controller state as of the last config posted to the agent
+	controllerDebugState struct {
+		ltmConfig   LTMConfig
+		gtmConfig   GTMConfig
+		ipamContext map[string]ficV1.IPSpec
+		nodes       []Node
+		updated     time.Time
+	}
+
+	// agentDebugState is the agent state as of the last declaration posted to BIG-IP
+	agentDebugState struct {
+		cachedTenantDeclMap   map[string]as3Tenant
+		incomingTenantDeclMap map[string]as3Tenant
+		retryTenantDeclMap    map[string]tenantParams
+		updated               time.Time
+	}
+
+	debugResourceConfig struct {
+		Namespace      string                           `json:"namespace,omitempty"`
+		ResourceType   string                           `json:"resourceType,omitempty"`
+		Virtual        Virtual                          `json:"virtual"`
+		Pools          Pools                            `json:"pools,omitempty"`
+		Policies       Policies                         `json:"policies,omitempty"`
+		Monitors       []Monitor                        `json:"monitors,omitempty"`
+		ServiceAddress []ServiceAddress                 `json:"serviceAddress,omitempty"`
+		IRules         map[string]*IRule                `json:"iRules,omitempty"`
+		DataGroups     map[string]DataGroupNamespaceMap `json:"dataGroups,omitempty"`
+	}
+
+	debugRetry struct {
+		ResponseCode int         `json:"responseCode"`
+		TaskID       string      `json:"taskId,omitempty"`
+		Declaration  interface{} `json:"declaration,omitempty"`
+	}
+)
+
+// registerDebugHandlers registers the read-only debug API exposing the controller state
+func (ctlr *Controller) registerDebugHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/resources", debugHandler(ctlr.debugResources))
+	mux.HandleFunc("/debug/declarations", debugHandler(ctlr.Agent.debugDeclarations))
+	mux.HandleFunc("/debug/retries", debugHandler(ctlr.Agent.debugRetries))
+	mux.HandleFunc("/debug/ipam", debugHandler(ctlr.debugIPAM))
+	mux.HandleFunc("/debug/hostpaths", debugHandler(ctlr.debugHostPaths))
+	mux.HandleFunc("/debug/nodes", debugHandler(ctlr.debugNodes))
+}
+
+func debugHandler(getState func(debugFilter) interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		filter := debugFilter{
+			partition: r.URL.Query().Get("partition"),
+			namespace: r.URL.Query().Get("namespace"),
+		}
+		body, err := redactJSON(getState(filter))
+		if err != nil {
+			log.Errorf("Unable to serve debug state %v: %v", r.URL.Path, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+}
+
+// redactJSON marshals the state replacing the values of sensitive keys
+func redactJSON(state interface{}) ([]byte, error) {
+	body, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	if err = json.Unmarshal(body, &obj); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(redact(obj), "", "  ")
+}
+
+func redact(obj interface{}) interface{} {
+	switch value := obj.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			if debugRedactedKeys[k] {
+				value[k] = redactedValue
+			} else {
+				value[k] = redact(v)
+			}
+		}
+	case []interface{}:
+		for i, v := range value {
+			value[i] = redact(v)
+		}
+	}
+	return obj
+}
+
+func (filter debugFilter) matchPartition(partition string) bool {
+	return filter.partition == "" || filter.partition == partition
+}
+
+func (filter debugFilter) matchNamespace(namespace string) bool {
+	return filter.namespace == "" || filter.namespace == namespace
+}
+
+// updateDebugState saves the config posted to the agent for the debug API
+func (ctlr *Controller) updateDebugState(config ResourceConfigRequest) {
+	if !ctlr.debugAPI {
+		return
+	}
+	ipamContext := make(map[string]ficV1.IPSpec, len(ctlr.resources.ipamContext))
+	for k, v := range ctlr.resources.ipamContext {
+		ipamContext[k] = v
+	}
+
+	ctlr.debugMutex.Lock()
+	defer ctlr.debugMutex.Unlock()
+	ctlr.debugState.ltmConfig = config.ltmConfig
+	ctlr.debugState.gtmConfig = config.gtmConfig
+	ctlr.debugState.ipamContext = ipamContext
+	ctlr.debugState.updated = time.Now()
+}
+
+// updateDebugNodes saves the node cache for the debug API
+func (ctlr *Controller) updateDebugNodes() {
+	if !ctlr.debugAPI {
+		return
+	}
+	nodes := ctlr.getNodesFromCache()
+	ctlr.debugMutex.Lock()
+	defer ctlr.debugMutex.Unlock()
+	ctlr.debugState.nodes = nodes
+}
+
+func (ctlr *Controller) debugResources(filter debugFilter) interface{} {
+	ctlr.debugMutex.Lock()
+	defer ctlr.debugMutex.Unlock()
+	ltm := make(map[string]map[string]debugResourceConfig)
+	for partition, partitionConfig := range ctlr.debugState.ltmConfig {
+		if !filter.matchPartition(partition) {
+			continue
+		}
+		resources := make(map[string]debugResourceConfig)
+		for name, rsCfg := range partitionConfig.ResourceMap {
+			if !filter.matchNamespace(rsCfg.MetaData.namespace) {
+				continue
+			}
+			resources[name] = newDebugResourceConfig(rsCfg)
+		}
+		ltm[partition] = resources
+	}
+	gtm := make(GTMConfig)
+	for partition, gtmPartitionConfig := range ctlr.debugState.gtmConfig {
+		if filter.matchPartition(partition) {
+			gtm[partition] = gtmPartitionConfig
+		}
+	}
+	return map[string]interface{}{
+		"updated": ctlr.debugState.updated,
+		"ltm":     ltm,
+		"gtm":     gtm,
+	}
+}
+
+func newDebugResourceConfig(rsCfg *ResourceConfig) debugResourceConfig {
+	debugCfg := debugResourceConfig{
+		Namespace:      rsCfg.MetaData.namespace,
+		ResourceType:   rsCfg.MetaData.ResourceType,
+		Virtual:        rsCfg.Virtual,
+		Pools:          rsCfg.Pools,
+		Policies:       rsCfg.Policies,
+		Monitors:       rsCfg.Monitors,
+		ServiceAddress: rsCfg.ServiceAddress,
+		IRules:         make(map[string]*IRule),
+		DataGroups:     make(map[string]DataGroupNamespaceMap),
+	}
+	for ref, iRule := range rsCfg.IRulesMap {
+		debugCfg.IRules[ref.Partition+"/"+ref.Name] = iRule
+	}
+	for ref, dgMap := range rsCfg.IntDgMap {
+		debugCfg.DataGroups[ref.Partition+"/"+ref.Name] = dgMap
+	}
+	return debugCfg
+}
+
+func (ctlr *Controller) debugIPAM(filter debugFilter) interface{} {
+	var requests []*ficV1.HostSpec
+	if ctlr.ipamCli != nil {
+		if ipamCR := ctlr.getIPAMCR(); ipamCR != nil {
+			for _, hostSpec := range ipamCR.Spec.HostSpecs {
+				if filter.matchNamespace(strings.Split(hostSpec.Key, "/")[0]) {
+					requests = append(requests, hostSpec)
+				}
+			}
+		}
+	}
+	ctlr.debugMutex.Lock()
+	defer ctlr.debugMutex.Unlock()
+	allocated := make(map[string]ficV1.IPSpec)
+	for key, ipSpec := range ctlr.debugState.ipamContext {
+		if filter.matchNamespace(strings.Split(key, "/")[0]) {
+			allocated[key] = ipSpec
+		}
+	}
+	return map[string]interface{}{
+		"requests":  requests,
+		"allocated": allocated,
+	}
+}
+
+func (ctlr *Controller) debugHostPaths(_ debugFilter) interface{} {
+	hostPaths := make(map[string]time.Time)
+	if ctlr.processedHostPath == nil {
+		return hostPaths
+	}
+	ctlr.processedHostPath.Lock()
+	defer ctlr.processedHostPath.Unlock()
+	for hostPath, created := range ctlr.processedHostPath.processedHostPathMap {
+		hostPaths[hostPath] = created.Time
+	}
+	return hostPaths
+}
+
+func (ctlr *Controller) debugNodes(_ debugFilter) interface{} {
+	ctlr.debugMutex.Lock()
+	defer ctlr.debugMutex.Unlock()
+	return ctlr.debugState.nodes
+}
+
+// updateDebugState saves the tenant declarations for the debug API
+func (agent *Agent) updateDebugState() {
+	if !agent.debugAPI {
+		return
+	}
+	cached := make(map[string]as3Tenant, len(agent.cachedTenantDeclMap))
+	for tenant, decl := range agent.cachedTenantDeclMap {
+		cached[tenant] = decl
+	}
+	incoming := make(map[string]as3Tenant, len(agent.incomingTenantDeclMap))
+	for tenant, decl := range agent.incomingTenantDeclMap {
+		incoming[tenant] = decl
+	}
+	retry := make(map[string]tenantParams, len(agent.retryTenantDeclMap))
+	for tenant, params := range agent.retryTenantDeclMap {
+		retry[tenant] = *params
+	}
+
+	agent.debugMutex.Lock()
+	defer agent.debugMutex.Unlock()
+	agent.debugState = agentDebugState{
+		cachedTenantDeclMap:   cached,
+		incomingTenantDeclMap: incoming,
+		retryTenantDeclMap:    retry,
+		updated:               time.Now(),
+	}
+}
+
+func (agent *Agent) debugDeclarations(filter debugFilter) interface{} {
+	agent.debugMutex.Lock()
+	defer agent.debugMutex.Unlock()
+	filterTenants := func(declMap map[string]as3Tenant) map[string]as3Tenant {
+		filtered := make(map[string]as3Tenant)
+		for tenant, decl := range declMap {
+			if filter.matchPartition(tenant) {
+				filtered[tenant] = decl
+			}
+		}
+		return filtered
+	}
+	return map[string]interface{}{
+		"updated":  agent.debugState.updated,
+		"cached":   filterTenants(agent.debugState.cachedTenantDeclMap),
+		"incoming": filterTenants(agent.debugState.incomingTenantDeclMap),
+	}
+}
+
+func (agent *Agent) debugRetries(filter debugFilter) interface{} {
+	agent.debugMutex.Lock()
+	defer agent.debugMutex.Unlock()
+	retries := make(map[string]debugRetry)
+	for tenant, params := range agent.debugState.retryTenantDeclMap {
+		if filter.matchPartition(tenant) {
+			retries[tenant] = debugRetry{
+				ResponseCode: params.agentResponseCode,
+				TaskID:       params.taskId,
+				Declaration:  params.as3Decl,
+			}
+		}
+	}
+	return retries
+}
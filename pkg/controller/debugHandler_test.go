@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	ficV1 "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/apis/fic/v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Debug API Tests", func() {
+	var mockCtlr *mockController
+	var mux *http.ServeMux
+
+	get := func(url string) map[string]interface{} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+		var body map[string]interface{}
+		Expect(json.Unmarshal(rec.Body.Bytes(), &body)).To(Succeed())
+		return body
+	}
+
+	BeforeEach(func() {
+		mockCtlr = newMockController()
+		mockCtlr.Agent = newMockAgent(nil)
+		mockCtlr.debugAPI = true
+		mockCtlr.Agent.debugAPI = true
+		mockCtlr.resources = NewResourceStore()
+		mux = http.NewServeMux()
+		mockCtlr.registerDebugHandlers(mux)
+	})
+
+	It("Allows only GET requests", func() {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/resources", nil))
+		Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("Serves resources filtered by partition and namespace", func() {
+		rsCfg := &ResourceConfig{}
+		rsCfg.MetaData.namespace = "default"
+		rsCfg.Virtual.Name = "vs1"
+		rsCfg.IRulesMap = IRulesMap{NameRef{Name: "rule", Partition: "test"}: &IRule{Name: "rule"}}
+		rsCfg2 := &ResourceConfig{}
+		rsCfg2.MetaData.namespace = "kube-system"
+		mockCtlr.resources.ipamContext["default/vs1_host"] = ficV1.IPSpec{IP: "10.1.1.1"}
+		mockCtlr.resources.ipamContext["kube-system/vs2_host"] = ficV1.IPSpec{IP: "10.1.1.2"}
+		mockCtlr.updateDebugState(ResourceConfigRequest{
+			ltmConfig: LTMConfig{
+				"test":  &PartitionConfig{ResourceMap: ResourceMap{"vs1": rsCfg, "vs2": rsCfg2}},
+				"test2": &PartitionConfig{ResourceMap: ResourceMap{}},
+			},
+		})
+
+		ltm := get("/debug/resources?partition=test&namespace=default")["ltm"].(map[string]interface{})
+		Expect(ltm).To(HaveLen(1))
+		resources := ltm["test"].(map[string]interface{})
+		Expect(resources).To(HaveKey("vs1"))
+		Expect(resources).NotTo(HaveKey("vs2"))
+		Expect(resources["vs1"].(map[string]interface{})["iRules"]).To(HaveKey("test/rule"))
+
+		allocated := get("/debug/ipam?namespace=default")["allocated"].(map[string]interface{})
+		Expect(allocated).To(HaveLen(1))
+		Expect(allocated).To(HaveKey("default/vs1_host"))
+	})
+
+	It("Serves declarations and retries with secrets redacted", func() {
+		agent := mockCtlr.Agent
+		agent.cachedTenantDeclMap = make(map[string]as3Tenant)
+		agent.incomingTenantDeclMap = make(map[string]as3Tenant)
+		agent.retryTenantDeclMap = make(map[string]*tenantParams)
+		agent.cachedTenantDeclMap["test"] = as3Tenant{
+			"class": "Tenant",
+			"app": map[string]interface{}{
+				"cert": map[string]interface{}{"certificate": "cert", "privateKey": "secret"},
+			},
+		}
+		agent.incomingTenantDeclMap["test2"] = as3Tenant{"class": "Tenant"}
+		agent.retryTenantDeclMap["test2"] = &tenantParams{tenantResponse: tenantResponse{agentResponseCode: 422, taskId: "task1"}}
+		agent.updateDebugState()
+
+		body := get("/debug/declarations?partition=test")
+		Expect(body["incoming"]).To(BeEmpty())
+		cert := body["cached"].(map[string]interface{})["test"].(map[string]interface{})["app"].(map[string]interface{})["cert"]
+		Expect(cert).To(HaveKeyWithValue("privateKey", redactedValue))
+		Expect(cert).To(HaveKeyWithValue("certificate", "cert"))
+
+		retries := get("/debug/retries")
+		Expect(retries["test2"]).To(HaveKeyWithValue("responseCode", BeEquivalentTo(422)))
+		Expect(retries["test2"]).To(HaveKeyWithValue("taskId", "task1"))
+	})
+
+	It("Skips snapshots when debug API is disabled", func() {
+		mockCtlr.debugAPI = false
+		mockCtlr.updateDebugState(ResourceConfigRequest{ltmConfig: LTMConfig{"test": &PartitionConfig{}}})
+		Expect(mockCtlr.debugState.ltmConfig).To(BeNil())
+	})
+})
@@ -0,0 +1,288 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	bigIPPrometheus "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/prometheus"
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// AllowMassDeletionAnnotation on the deletion guard ConfigMap allows held deletions to proceed.
+	// Its value is either "true" or an RFC3339 timestamp until which deletions are allowed.
+	AllowMassDeletionAnnotation = "cis.f5.com/allow-mass-deletion"
+
+	deletionGuardInterval = 30 * time.Second
+)
+
+func (guard *deletionGuard) enabled() bool {
+	return guard.MaxDeletePercent > 0 || guard.MaxDeleteCount > 0
+}
+
+// exceeds reports whether removing deleted of total virtual servers crosses the configured thresholds
+func (guard *deletionGuard) exceeds(deleted, total int) bool {
+	if guard.MaxDeleteCount > 0 && deleted > guard.MaxDeleteCount {
+		return true
+	}
+	return guard.MaxDeletePercent > 0 && deleted*100 > guard.MaxDeletePercent*total
+}
+
+// SetDeletionGuardHandlers sets the functions used by the deletion guard to check for an
+// operator override and to report held tenant declarations
+func (agent *Agent) SetDeletionGuardHandlers(overrideActive func() bool, notifyHeld func(string, int, int)) {
+	agent.declUpdate.Lock()
+	defer agent.declUpdate.Unlock()
+	agent.deletionGuard.overrideActive = overrideActive
+	agent.deletionGuard.notifyHeld = notifyHeld
+}
+
+// holdMassDeletions removes from incomingTenantDeclMap the tenants whose declaration would remove
+// more virtual servers than allowed compared to cachedTenantDeclMap, unless an override is active
+func (agent *Agent) holdMassDeletions(config ResourceConfigRequest) {
+	guard := &agent.deletionGuard
+	if !guard.enabled() {
+		return
+	}
+	heldTenants := make(map[string]struct{})
+	overrideChecked, override := false, false
+	for tenant, decl := range agent.incomingTenantDeclMap {
+		baseline, ok := agent.cachedTenantDeclMap[tenant]
+		if !ok {
+			// After a restart the declaration applied before the restart is the baseline,
+			// or the declaration on BIG-IP without a snapshot
+			if baseline, ok = agent.snapshot.decls[tenant]; !ok {
+				baseline = guard.bigipDecls[tenant]
+			}
+		}
+		total := countVirtualServers(baseline)
+		deleted := total - countVirtualServers(decl)
+		if deleted <= 0 || !guard.exceeds(deleted, total) {
+			continue
+		}
+		if !overrideChecked {
+			overrideChecked = true
+			override = guard.overrideActive != nil && guard.overrideActive()
+		}
+		if override {
+			tenantLogger(tenant, config.reqId).Warningf("[AS3] Removing %v of %v virtual servers from tenant %v as mass deletion is allowed",
+				deleted, total, tenant)
+			continue
+		}
+		tenantLogger(tenant, config.reqId).Errorf("[AS3] Holding declaration of tenant %v as it removes %v of %v virtual servers. "+
+			"Set annotation %v on ConfigMap %v to allow it", tenant, deleted, total,
+			AllowMassDeletionAnnotation, guard.OverrideCfgMap)
+		delete(agent.incomingTenantDeclMap, tenant)
+		delete(agent.tenantPriorityMap, tenant)
+		heldTenants[tenant] = struct{}{}
+		bigIPPrometheus.HeldTenantDeclarations.WithLabelValues(tenant).Set(float64(deleted))
+		if _, ok := guard.heldTenants[tenant]; !ok && guard.notifyHeld != nil {
+			guard.notifyHeld(tenant, deleted, total)
+		}
+	}
+	for tenant := range guard.heldTenants {
+		if _, ok := heldTenants[tenant]; !ok {
+			tenantLogger(tenant, config.reqId).Infof("[AS3] Declaration of tenant %v is no longer held", tenant)
+			bigIPPrometheus.HeldTenantDeclarations.DeleteLabelValues(tenant)
+		}
+	}
+	guard.heldTenants = heldTenants
+	guard.heldConfig = nil
+	if len(heldTenants) > 0 {
+		guard.heldConfig = &config
+	}
+}
+
+// seedDeletionGuard fetches the declaration on BIG-IP until it succeeds, so that the tenants which
+// are not posted since the start are guarded without a snapshot
+func (agent *Agent) seedDeletionGuard() {
+	if !agent.deletionGuard.enabled() || agent.PostManager == nil {
+		return
+	}
+	agent.declUpdate.Lock()
+	seeded := agent.deletionGuard.bigipDecls != nil
+	agent.declUpdate.Unlock()
+	if seeded {
+		return
+	}
+	// The request is sent without the declaration lock
+	tenants, err := agent.GetBigipAS3Tenants()
+	if err != nil {
+		log.Warningf("[AS3] Unable to fetch the declaration on BIG-IP for the deletion guard: %v", err)
+		return
+	}
+	agent.declUpdate.Lock()
+	agent.deletionGuard.bigipDecls = tenants
+	agent.declUpdate.Unlock()
+}
+
+// deletionGuardWorker periodically reposts the held config once an override is active
+func (agent *Agent) deletionGuardWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		agent.declUpdate.Lock()
+		guard := &agent.deletionGuard
+		var heldConfig *ResourceConfigRequest
+		if guard.heldConfig != nil && guard.overrideActive != nil && guard.overrideActive() {
+			heldConfig = guard.heldConfig
+		}
+		agent.declUpdate.Unlock()
+
+		if heldConfig != nil {
+			// A pending config request is newer than the held one and is evaluated again anyway
+			select {
+			case agent.postChan <- *heldConfig:
+				log.Infof("[AS3] Mass deletion allowed, posting held tenant declarations")
+			default:
+			}
+		}
+	}
+}
+
+// countVirtualServers returns the number of virtual servers in an AS3 tenant
+func countVirtualServers(tenant as3Tenant) int {
+	count := 0
+	for _, obj := range tenant {
+		switch app := obj.(type) {
+		case as3Application:
+			for _, appObj := range app {
+				if _, ok := appObj.(*as3Service); ok {
+					count++
+				}
+			}
+		case map[string]interface{}:
+			// Declarations restored from the snapshot are decoded from JSON
+			for _, appObj := range app {
+				if appObj, ok := appObj.(map[string]interface{}); ok {
+					if class, _ := appObj["class"].(string); strings.HasPrefix(class, "Service_") {
+						count++
+					}
+				}
+			}
+		}
+	}
+	return count
+}
+
+// setupDeletionGuard watches the deletion guard ConfigMap so that the agent reads the override from the
+// informer cache while holding its declaration lock
+func (ctlr *Controller) setupDeletionGuard() {
+	namespaceCfgmapSlice := strings.Split(ctlr.Agent.deletionGuard.OverrideCfgMap, "/")
+	if len(namespaceCfgmapSlice) != 2 || ctlr.kubeClient == nil {
+		log.Errorf("Unable to watch deletion guard ConfigMap %v", ctlr.Agent.deletionGuard.OverrideCfgMap)
+		return
+	}
+	ctlr.deletionGuardInformer = cache.NewSharedIndexInformer(
+		cache.NewFilteredListWatchFromClient(
+			ctlr.kubeClient.CoreV1().RESTClient(),
+			"configmaps",
+			namespaceCfgmapSlice[0],
+			func(options *metav1.ListOptions) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", namespaceCfgmapSlice[1]).String()
+			},
+		),
+		&v1.ConfigMap{},
+		0*time.Second,
+		cache.Indexers{},
+	)
+	ctlr.Agent.SetDeletionGuardHandlers(ctlr.isMassDeletionAllowed, func(tenant string, deleted, total int) {
+		// The event is created without the declaration lock of the agent
+		go ctlr.recordHeldDeletion(tenant, deleted, total)
+	})
+}
+
+// isMassDeletionAllowed checks the deletion guard ConfigMap for the override annotation
+func (ctlr *Controller) isMassDeletionAllowed() bool {
+	cm, err := ctlr.getDeletionGuardCfgMap()
+	if err != nil {
+		log.Debugf("Unable to fetch deletion guard ConfigMap: %v", err)
+		return false
+	}
+	value, ok := cm.Annotations[AllowMassDeletionAnnotation]
+	if !ok {
+		return false
+	}
+	if value == "true" {
+		return true
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Errorf("Invalid value %v for annotation %v on ConfigMap %v/%v", value,
+			AllowMassDeletionAnnotation, cm.Namespace, cm.Name)
+		return false
+	}
+	return time.Now().Before(until)
+}
+
+// recordHeldDeletion raises a warning event on the deletion guard ConfigMap for a held tenant declaration
+func (ctlr *Controller) recordHeldDeletion(tenant string, deleted, total int) {
+	cm, err := ctlr.getDeletionGuardCfgMap()
+	if err != nil {
+		return
+	}
+	now := metav1.Now()
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: cm.Name + ".",
+			Namespace:    cm.Namespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			Kind:            "ConfigMap",
+			APIVersion:      "v1",
+			Namespace:       cm.Namespace,
+			Name:            cm.Name,
+			UID:             cm.UID,
+			ResourceVersion: cm.ResourceVersion,
+		},
+		Reason: "MassDeletionHeld",
+		Message: fmt.Sprintf("Declaration of tenant %v is held as it removes %v of %v virtual servers. "+
+			"Set annotation %v to allow it", tenant, deleted, total, AllowMassDeletionAnnotation),
+		Type:           v1.EventTypeWarning,
+		Source:         v1.EventSource{Component: "k8s-bigip-ctlr"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err = ctlr.kubeClient.CoreV1().Events(cm.Namespace).Create(context.TODO(), event, metav1.CreateOptions{})
+	if err != nil {
+		log.Debugf("Unable to create event for held tenant %v: %v", tenant, err)
+	}
+}
+
+// getDeletionGuardCfgMap returns the deletion guard ConfigMap from the informer cache
+func (ctlr *Controller) getDeletionGuardCfgMap() (*v1.ConfigMap, error) {
+	if ctlr.deletionGuardInformer == nil {
+		return nil, fmt.Errorf("deletion guard ConfigMap %v is not watched", ctlr.Agent.deletionGuard.OverrideCfgMap)
+	}
+	obj, found, err := ctlr.deletionGuardInformer.GetIndexer().GetByKey(ctlr.Agent.deletionGuard.OverrideCfgMap)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("deletion guard ConfigMap %v not found", ctlr.Agent.deletionGuard.OverrideCfgMap)
+	}
+	return obj.(*v1.ConfigMap), nil
+}
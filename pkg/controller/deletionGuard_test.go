@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Deletion Guard Tests", func() {
+	var agent *Agent
+	var notified []string
+
+	newTenant := func(vsCount int) as3Tenant {
+		sharedApp := as3Application{
+			"class":    "Application",
+			"template": "shared",
+		}
+		for i := 0; i < vsCount; i++ {
+			sharedApp[fmt.Sprintf("vs_%d", i)] = &as3Service{Class: "Service_HTTP"}
+			sharedApp[fmt.Sprintf("pool_%d", i)] = &as3Pool{Class: "Pool"}
+		}
+		return as3Tenant{
+			"class":              "Tenant",
+			as3SharedApplication: sharedApp,
+		}
+	}
+
+	BeforeEach(func() {
+		notified = nil
+		agent = newMockAgent(nil)
+		agent.cachedTenantDeclMap = map[string]as3Tenant{
+			"test":  newTenant(10),
+			"test2": newTenant(2),
+		}
+		agent.tenantPriorityMap = make(map[string]int)
+		agent.deletionGuard = deletionGuard{
+			DeletionGuardParams: DeletionGuardParams{MaxDeletePercent: 50, MaxDeleteCount: 5},
+			heldTenants:         make(map[string]struct{}),
+			overrideActive:      func() bool { return false },
+			notifyHeld: func(tenant string, deleted, total int) {
+				notified = append(notified, tenant)
+			},
+		}
+	})
+
+	It("Counts virtual servers", func() {
+		Expect(countVirtualServers(newTenant(3))).To(Equal(3))
+		Expect(countVirtualServers(as3Tenant{"class": "Tenant"})).To(BeZero())
+		Expect(countVirtualServers(nil)).To(BeZero())
+	})
+
+	It("Checks thresholds", func() {
+		guard := &deletionGuard{}
+		Expect(guard.enabled()).To(BeFalse())
+		guard.MaxDeletePercent = 50
+		Expect(guard.exceeds(5, 10)).To(BeFalse())
+		Expect(guard.exceeds(6, 10)).To(BeTrue())
+		guard.MaxDeleteCount = 3
+		Expect(guard.exceeds(4, 100)).To(BeTrue())
+	})
+
+	It("Holds mass deletion", func() {
+		agent.incomingTenantDeclMap = map[string]as3Tenant{
+			"test":  {"class": "Tenant"},
+			"test2": newTenant(1),
+		}
+		agent.tenantPriorityMap["test"] = 1
+		agent.holdMassDeletions(ResourceConfigRequest{reqId: 5})
+		Expect(agent.incomingTenantDeclMap).NotTo(HaveKey("test"))
+		Expect(agent.incomingTenantDeclMap).To(HaveKey("test2"))
+		Expect(agent.tenantPriorityMap).NotTo(HaveKey("test"))
+		Expect(agent.deletionGuard.heldTenants).To(HaveKey("test"))
+		Expect(agent.deletionGuard.heldConfig.reqId).To(Equal(5))
+		Expect(notified).To(Equal([]string{"test"}))
+
+		// Held tenant is notified only once
+		agent.incomingTenantDeclMap = map[string]as3Tenant{"test": newTenant(4)}
+		agent.holdMassDeletions(ResourceConfigRequest{reqId: 6})
+		Expect(agent.incomingTenantDeclMap).To(BeEmpty())
+		Expect(agent.deletionGuard.heldConfig.reqId).To(Equal(6))
+		Expect(notified).To(HaveLen(1))
+
+		// Tenant is released when the deletion is within thresholds
+		agent.incomingTenantDeclMap = map[string]as3Tenant{"test": newTenant(6)}
+		agent.holdMassDeletions(ResourceConfigRequest{reqId: 7})
+		Expect(agent.incomingTenantDeclMap).To(HaveKey("test"))
+		Expect(agent.deletionGuard.heldTenants).To(BeEmpty())
+		Expect(agent.deletionGuard.heldConfig).To(BeNil())
+	})
+
+	It("Uses the declaration on BIG-IP as baseline without cache and snapshot", func() {
+		mockPM := newMockPostManger()
+		mockPM.BIGIPURL = "bigip.com"
+		mockPM.setResponses([]responceCtx{{
+			tenant: "test3",
+			status: http.StatusOK,
+			body: `{"class": "ADC", "test3": {"class": "Tenant", "Shared": {"class": "Application",
+				"vs_1": {"class": "Service_HTTP"}, "vs_2": {"class": "Service_HTTP"}}}}`,
+		}}, http.MethodGet)
+		agent.PostManager = mockPM.PostManager
+		agent.seedDeletionGuard()
+		Expect(agent.deletionGuard.bigipDecls).To(HaveKey("test3"))
+
+		agent.incomingTenantDeclMap = map[string]as3Tenant{"test3": {"class": "Tenant"}}
+		agent.holdMassDeletions(ResourceConfigRequest{reqId: 9})
+		Expect(agent.incomingTenantDeclMap).To(BeEmpty())
+		Expect(agent.deletionGuard.heldTenants).To(HaveKey("test3"))
+	})
+
+	It("Allows mass deletion with override", func() {
+		agent.deletionGuard.overrideActive = func() bool { return true }
+		agent.incomingTenantDeclMap = map[string]as3Tenant{"test": {"class": "Tenant"}}
+		agent.holdMassDeletions(ResourceConfigRequest{})
+		Expect(agent.incomingTenantDeclMap).To(HaveKey("test"))
+		Expect(agent.deletionGuard.heldConfig).To(BeNil())
+		Expect(notified).To(BeEmpty())
+	})
+
+	It("Reposts held config once override is active", func() {
+		override := false
+		agent.SetDeletionGuardHandlers(func() bool { return override }, nil)
+		agent.deletionGuard.heldConfig = &ResourceConfigRequest{reqId: 8}
+		go agent.deletionGuardWorker(10 * time.Millisecond)
+		Consistently(agent.postChan, 50*time.Millisecond).ShouldNot(Receive())
+
+		agent.declUpdate.Lock()
+		override = true
+		agent.declUpdate.Unlock()
+		var rsConfig ResourceConfigRequest
+		Eventually(agent.postChan).Should(Receive(&rsConfig))
+		Expect(rsConfig.reqId).To(Equal(8))
+	})
+
+	Describe("Override ConfigMap", func() {
+		var mockCtlr *mockController
+		var cm *v1.ConfigMap
+
+		BeforeEach(func() {
+			mockCtlr = newMockController()
+			mockCtlr.Agent = agent
+			agent.deletionGuard.OverrideCfgMap = "kube-system/cis-deletion-guard"
+			cm = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "cis-deletion-guard",
+					Namespace:   "kube-system",
+					Annotations: map[string]string{},
+				},
+			}
+		})
+
+		It("Checks override annotation", func() {
+			mockCtlr.kubeClient = k8sfake.NewSimpleClientset()
+			mockCtlr.setupDeletionGuard()
+			Expect(mockCtlr.deletionGuardInformer).NotTo(BeNil())
+			store := mockCtlr.deletionGuardInformer.GetStore()
+			Expect(mockCtlr.isMassDeletionAllowed()).To(BeFalse())
+
+			Expect(store.Add(cm)).To(Succeed())
+			Expect(mockCtlr.isMassDeletionAllowed()).To(BeFalse())
+
+			setOverride := func(value string) {
+				cm = cm.DeepCopy()
+				cm.Annotations[AllowMassDeletionAnnotation] = value
+				Expect(store.Update(cm)).To(Succeed())
+			}
+			setOverride("true")
+			Expect(mockCtlr.isMassDeletionAllowed()).To(BeTrue())
+			Expect(agent.deletionGuard.overrideActive()).To(BeTrue())
+
+			setOverride(time.Now().Add(time.Hour).Format(time.RFC3339))
+			Expect(mockCtlr.isMassDeletionAllowed()).To(BeTrue())
+
+			setOverride(time.Now().Add(-time.Hour).Format(time.RFC3339))
+			Expect(mockCtlr.isMassDeletionAllowed()).To(BeFalse())
+
+			setOverride("yes")
+			Expect(mockCtlr.isMassDeletionAllowed()).To(BeFalse())
+		})
+
+		It("Records event for held tenant", func() {
+			mockCtlr.kubeClient = k8sfake.NewSimpleClientset(cm)
+			mockCtlr.setupDeletionGuard()
+			Expect(mockCtlr.deletionGuardInformer.GetStore().Add(cm)).To(Succeed())
+			mockCtlr.recordHeldDeletion("test", 10, 10)
+			events, err := mockCtlr.kubeClient.CoreV1().Events("kube-system").List(context.TODO(), metav1.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(events.Items).To(HaveLen(1))
+			Expect(events.Items[0].Reason).To(Equal("MassDeletionHeld"))
+			Expect(events.Items[0].InvolvedObject.Name).To(Equal("cis-deletion-guard"))
+		})
+	})
+})
@@ -630,7 +630,7 @@ func (ctlr *Controller) enqueueIPAM(obj interface{}) {
 		event:     Create,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueUpdatedIPAM(oldObj, newObj interface{}) {
@@ -654,7 +654,7 @@ func (ctlr *Controller) enqueueUpdatedIPAM(oldObj, newObj interface{}) {
 		event:     Update,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueDeletedIPAM(obj interface{}) {
@@ -673,7 +673,7 @@ func (ctlr *Controller) enqueueDeletedIPAM(obj interface{}) {
 		event:     Delete,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueVirtualServer(obj interface{}) {
@@ -687,20 +687,21 @@ func (ctlr *Controller) enqueueVirtualServer(obj interface{}) {
 		event:     Create,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueUpdatedVirtualServer(oldObj, newObj interface{}) {
 	oldVS := oldObj.(*cisapiv1.VirtualServer)
 	newVS := newObj.(*cisapiv1.VirtualServer)
 	updateEvent := true
-	oldVSPartition := ctlr.getCRPartition(oldVS.Spec.Partition)
-	newVSPartition := ctlr.getCRPartition(newVS.Spec.Partition)
+	oldVSPartition := ctlr.getCRPartition(oldVS.Spec.Partition, oldVS.Spec.BigIPRef)
+	newVSPartition := ctlr.getCRPartition(newVS.Spec.Partition, newVS.Spec.BigIPRef)
 	if oldVS.Spec.VirtualServerAddress != newVS.Spec.VirtualServerAddress ||
 		oldVS.Spec.VirtualServerHTTPPort != newVS.Spec.VirtualServerHTTPPort ||
 		oldVS.Spec.VirtualServerHTTPSPort != newVS.Spec.VirtualServerHTTPSPort ||
 		oldVS.Spec.VirtualServerName != newVS.Spec.VirtualServerName ||
 		oldVS.Spec.Host != newVS.Spec.Host ||
+		!reflect.DeepEqual(oldVS.Spec.Hosts, newVS.Spec.Hosts) ||
 		oldVS.Spec.IPAMLabel != newVS.Spec.IPAMLabel ||
 		oldVS.Spec.HostGroup != newVS.Spec.HostGroup ||
 		oldVSPartition != newVSPartition {
@@ -719,7 +720,7 @@ func (ctlr *Controller) enqueueUpdatedVirtualServer(oldObj, newObj interface{})
 			event:     Delete,
 		}
 		updateEvent = false
-		ctlr.resourceQueue.Add(key)
+		ctlr.enqueueKey(key)
 	}
 
 	log.Debugf("Enqueueing VirtualServer: %v", newVS)
@@ -733,7 +734,7 @@ func (ctlr *Controller) enqueueUpdatedVirtualServer(oldObj, newObj interface{})
 	if updateEvent {
 		key.event = Update
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueDeletedVirtualServer(obj interface{}) {
@@ -747,7 +748,7 @@ func (ctlr *Controller) enqueueDeletedVirtualServer(obj interface{}) {
 		event:     Delete,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueTLSProfile(obj interface{}, event string) {
@@ -761,7 +762,7 @@ func (ctlr *Controller) enqueueTLSProfile(obj interface{}, event string) {
 		event:     event,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueTransportServer(obj interface{}) {
@@ -775,15 +776,15 @@ func (ctlr *Controller) enqueueTransportServer(obj interface{}) {
 		event:     Create,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueUpdatedTransportServer(oldObj, newObj interface{}) {
 	oldVS := oldObj.(*cisapiv1.TransportServer)
 	newVS := newObj.(*cisapiv1.TransportServer)
 
-	oldVSPartition := ctlr.getCRPartition(oldVS.Spec.Partition)
-	newVSPartition := ctlr.getCRPartition(newVS.Spec.Partition)
+	oldVSPartition := ctlr.getCRPartition(oldVS.Spec.Partition, oldVS.Spec.BigIPRef)
+	newVSPartition := ctlr.getCRPartition(newVS.Spec.Partition, newVS.Spec.BigIPRef)
 	if oldVS.Spec.VirtualServerAddress != newVS.Spec.VirtualServerAddress ||
 		oldVS.Spec.VirtualServerPort != newVS.Spec.VirtualServerPort ||
 		oldVS.Spec.VirtualServerName != newVS.Spec.VirtualServerName ||
@@ -804,7 +805,7 @@ func (ctlr *Controller) enqueueUpdatedTransportServer(oldObj, newObj interface{}
 			rsc:       oldObj,
 			event:     Delete,
 		}
-		ctlr.resourceQueue.Add(key)
+		ctlr.enqueueKey(key)
 	}
 
 	log.Debugf("Enqueueing TransportServer: %v", newVS)
@@ -816,7 +817,7 @@ func (ctlr *Controller) enqueueUpdatedTransportServer(oldObj, newObj interface{}
 		event:     Create,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueDeletedTransportServer(obj interface{}) {
@@ -830,7 +831,7 @@ func (ctlr *Controller) enqueueDeletedTransportServer(obj interface{}) {
 		event:     Delete,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueuePolicy(obj interface{}, event string) {
@@ -844,7 +845,7 @@ func (ctlr *Controller) enqueuePolicy(obj interface{}, event string) {
 		event:     event,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueDeletedPolicy(obj interface{}) {
@@ -858,7 +859,7 @@ func (ctlr *Controller) enqueueDeletedPolicy(obj interface{}) {
 		event:     Delete,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueIngressLink(obj interface{}) {
@@ -872,7 +873,7 @@ func (ctlr *Controller) enqueueIngressLink(obj interface{}) {
 		event:     Create,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueDeletedIngressLink(obj interface{}) {
@@ -886,15 +887,15 @@ func (ctlr *Controller) enqueueDeletedIngressLink(obj interface{}) {
 		event:     Delete,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueUpdatedIngressLink(oldObj, newObj interface{}) {
 	oldIngLink := oldObj.(*cisapiv1.IngressLink)
 	newIngLink := newObj.(*cisapiv1.IngressLink)
 
-	oldILPartition := ctlr.getCRPartition(oldIngLink.Spec.Partition)
-	newILPartition := ctlr.getCRPartition(newIngLink.Spec.Partition)
+	oldILPartition := ctlr.getCRPartition(oldIngLink.Spec.Partition, "")
+	newILPartition := ctlr.getCRPartition(newIngLink.Spec.Partition, "")
 	if oldIngLink.Spec.VirtualServerAddress != newIngLink.Spec.VirtualServerAddress ||
 		oldIngLink.Spec.IPAMLabel != newIngLink.Spec.IPAMLabel ||
 		oldILPartition != newILPartition {
@@ -912,7 +913,7 @@ func (ctlr *Controller) enqueueUpdatedIngressLink(oldObj, newObj interface{}) {
 			event:     Delete,
 		}
 
-		ctlr.resourceQueue.Add(key)
+		ctlr.enqueueKey(key)
 	}
 
 	log.Infof("Enqueueing IngressLink: %v on Update", newIngLink)
@@ -924,7 +925,7 @@ func (ctlr *Controller) enqueueUpdatedIngressLink(oldObj, newObj interface{}) {
 		event:     Create,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueExternalDNS(obj interface{}) {
@@ -938,7 +939,7 @@ func (ctlr *Controller) enqueueExternalDNS(obj interface{}) {
 		event:     Create,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueUpdatedExternalDNS(oldObj, newObj interface{}) {
@@ -954,7 +955,7 @@ func (ctlr *Controller) enqueueUpdatedExternalDNS(oldObj, newObj interface{}) {
 			event:     Delete,
 		}
 
-		ctlr.resourceQueue.Add(key)
+		ctlr.enqueueKey(key)
 	}
 
 	log.Infof("Enqueueing Updated ExternalDNS: %v", edns)
@@ -966,7 +967,7 @@ func (ctlr *Controller) enqueueUpdatedExternalDNS(oldObj, newObj interface{}) {
 		event:     Create,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueDeletedExternalDNS(obj interface{}) {
@@ -980,7 +981,7 @@ func (ctlr *Controller) enqueueDeletedExternalDNS(obj interface{}) {
 		event:     Delete,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueService(obj interface{}) {
@@ -998,7 +999,7 @@ func (ctlr *Controller) enqueueService(obj interface{}) {
 		rsc:       obj,
 		event:     Create,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueUpdatedService(obj, cur interface{}) {
@@ -1020,7 +1021,7 @@ func (ctlr *Controller) enqueueUpdatedService(obj, cur interface{}) {
 			rsc:       obj,
 			event:     Delete,
 		}
-		ctlr.resourceQueue.Add(key)
+		ctlr.enqueueKey(key)
 	}
 
 	log.Debugf("Enqueueing Updated Service: %v", curSvc)
@@ -1031,7 +1032,7 @@ func (ctlr *Controller) enqueueUpdatedService(obj, cur interface{}) {
 		rsc:       cur,
 		event:     Create,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueDeletedService(obj interface{}) {
@@ -1048,7 +1049,7 @@ func (ctlr *Controller) enqueueDeletedService(obj interface{}) {
 		rsc:       obj,
 		event:     Delete,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueEndpoints(obj interface{}, event string) {
@@ -1065,7 +1066,7 @@ func (ctlr *Controller) enqueueEndpoints(obj interface{}, event string) {
 		rsc:       obj,
 		event:     event,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueSecret(obj interface{}, event string) {
@@ -1078,7 +1079,7 @@ func (ctlr *Controller) enqueueSecret(obj interface{}, event string) {
 		rsc:       obj,
 		event:     event,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 
 }
 
@@ -1092,7 +1093,7 @@ func (ctlr *Controller) enqueueRoute(obj interface{}, event string) {
 		rsc:       obj,
 		event:     event,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueUpdatedRoute(old, cur interface{}) {
@@ -1110,7 +1111,7 @@ func (ctlr *Controller) enqueueUpdatedRoute(old, cur interface{}) {
 		event:     Update,
 		rsc:       cur,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueConfigmap(obj interface{}, event string) {
@@ -1131,7 +1132,7 @@ func (ctlr *Controller) enqueueConfigmap(obj interface{}, event string) {
 		rsc:       obj,
 		event:     event,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueDeletedConfigmap(obj interface{}) {
@@ -1145,7 +1146,7 @@ func (ctlr *Controller) enqueueDeletedConfigmap(obj interface{}) {
 		rsc:       obj,
 		event:     Delete,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueDeletedRoute(obj interface{}) {
@@ -1159,7 +1160,7 @@ func (ctlr *Controller) enqueueDeletedRoute(obj interface{}) {
 		rsc:       obj,
 		event:     Delete,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueuePod(obj interface{}) {
@@ -1176,7 +1177,7 @@ func (ctlr *Controller) enqueuePod(obj interface{}) {
 		rsc:       obj,
 	}
 
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueDeletedPod(obj interface{}) {
@@ -1193,7 +1194,7 @@ func (ctlr *Controller) enqueueDeletedPod(obj interface{}) {
 		rsc:       obj,
 		event:     Delete,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (nsInfr *NSInformer) start() {
@@ -1260,7 +1261,7 @@ func (ctlr *Controller) enqueueNamespace(obj interface{}) {
 		rsc:       obj,
 		event:     Create,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) enqueueDeletedNamespace(obj interface{}) {
@@ -1273,7 +1274,7 @@ func (ctlr *Controller) enqueueDeletedNamespace(obj interface{}) {
 		rsc:       obj,
 		event:     Delete,
 	}
-	ctlr.resourceQueue.Add(key)
+	ctlr.enqueueKey(key)
 }
 
 func (ctlr *Controller) checkCoreserviceLabels(labels map[string]string) bool {
@@ -88,13 +88,13 @@ func (ctlr *Controller) ProcessNodeUpdate(
 						for _, virtual := range virtuals {
 							vs := virtual.(*cisapiv1.VirtualServer)
 							qKey := &rqKey{
-								vs.ObjectMeta.Namespace,
-								VirtualServer,
-								vs.ObjectMeta.Name,
-								vs,
-								Update,
+								namespace: vs.ObjectMeta.Namespace,
+								kind:      VirtualServer,
+								rscName:   vs.ObjectMeta.Name,
+								rsc:       vs,
+								event:     Update,
 							}
-							ctlr.resourceQueue.Add(qKey)
+							ctlr.enqueueKey(qKey)
 						}
 					}
 					transportVirtuals := crInf.tsInformer.GetIndexer().List()
@@ -102,13 +102,13 @@ func (ctlr *Controller) ProcessNodeUpdate(
 						for _, virtual := range transportVirtuals {
 							vs := virtual.(*cisapiv1.TransportServer)
 							qKey := &rqKey{
-								vs.ObjectMeta.Namespace,
-								TransportServer,
-								vs.ObjectMeta.Name,
-								vs,
-								Update,
+								namespace: vs.ObjectMeta.Namespace,
+								kind:      TransportServer,
+								rscName:   vs.ObjectMeta.Name,
+								rsc:       vs,
+								event:     Update,
 							}
-							ctlr.resourceQueue.Add(qKey)
+							ctlr.enqueueKey(qKey)
 						}
 					}
 					ingressLinks := crInf.ilInformer.GetIndexer().List()
@@ -116,13 +116,13 @@ func (ctlr *Controller) ProcessNodeUpdate(
 						for _, ingressLink := range ingressLinks {
 							il := ingressLink.(*cisapiv1.IngressLink)
 							qKey := &rqKey{
-								il.ObjectMeta.Namespace,
-								IngressLink,
-								il.ObjectMeta.Name,
-								il,
-								Update,
+								namespace: il.ObjectMeta.Namespace,
+								kind:      IngressLink,
+								rscName:   il.ObjectMeta.Name,
+								rsc:       il,
+								event:     Update,
 							}
-							ctlr.resourceQueue.Add(qKey)
+							ctlr.enqueueKey(qKey)
 						}
 					}
 
@@ -135,33 +135,33 @@ func (ctlr *Controller) ProcessNodeUpdate(
 						ingressLinks := ctlr.getAllIngressLinks(ns)
 						for _, virtual := range virtuals {
 							qKey := &rqKey{
-								ns,
-								VirtualServer,
-								virtual.ObjectMeta.Name,
-								virtual,
-								Update,
+								namespace: ns,
+								kind:      VirtualServer,
+								rscName:   virtual.ObjectMeta.Name,
+								rsc:       virtual,
+								event:     Update,
 							}
-							ctlr.resourceQueue.Add(qKey)
+							ctlr.enqueueKey(qKey)
 						}
 						for _, virtual := range transportVirtuals {
 							qKey := &rqKey{
-								ns,
-								TransportServer,
-								virtual.ObjectMeta.Name,
-								virtual,
-								Update,
+								namespace: ns,
+								kind:      TransportServer,
+								rscName:   virtual.ObjectMeta.Name,
+								rsc:       virtual,
+								event:     Update,
 							}
-							ctlr.resourceQueue.Add(qKey)
+							ctlr.enqueueKey(qKey)
 						}
 						for _, ingressLink := range ingressLinks {
 							qKey := &rqKey{
-								ns,
-								IngressLink,
-								ingressLink.ObjectMeta.Name,
-								ingressLink,
-								Update,
+								namespace: ns,
+								kind:      IngressLink,
+								rscName:   ingressLink.ObjectMeta.Name,
+								rsc:       ingressLink,
+								event:     Update,
 							}
-							ctlr.resourceQueue.Add(qKey)
+							ctlr.enqueueKey(qKey)
 						}
 					}
 				}
@@ -173,6 +173,7 @@ func (ctlr *Controller) ProcessNodeUpdate(
 		// Initialize controller nodes on our first pass through
 		ctlr.oldNodes = newNodes
 	}
+	ctlr.updateDebugNodes()
 }
 
 // Return a copy of the node cache
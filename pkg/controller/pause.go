@@ -0,0 +1,358 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// PausedAnnotation on a resource or a namespace pauses its reconciliation
+	PausedAnnotation = "cis.f5.com/paused"
+
+	// Keys of the pause ConfigMap
+	pauseAllKey        = "paused"
+	pausePartitionsKey = "partitions"
+	pauseNamespacesKey = "namespaces"
+)
+
+// SetPausedTenants sets the tenants whose declarations are not posted to BIG-IP.
+// The latest config is posted once tenants with skipped declarations are unpaused.
+func (agent *Agent) SetPausedTenants(all bool, partitions map[string]bool) {
+	agent.declUpdate.Lock()
+	pause := &agent.tenantPause
+	unpaused := pause.all && !all
+	for tenant := range pause.partitions {
+		if !all && !partitions[tenant] {
+			unpaused = true
+		}
+	}
+	pause.all = all
+	pause.partitions = partitions
+	// Failed declarations of paused tenants are posted with the catch-up declaration
+	for tenant := range agent.retryTenantDeclMap {
+		if pause.isPaused(tenant) {
+			delete(agent.retryTenantDeclMap, tenant)
+			pause.catchUpPending = true
+		}
+	}
+	// Declarations of paused tenants which are not posted yet are dropped by the tenant workers
+	for tenant := range agent.pendingTenantDeclMap {
+		if pause.isPaused(tenant) {
+			delete(agent.pendingTenantDeclMap, tenant)
+			pause.catchUpPending = true
+		}
+	}
+	var catchUpConfig *ResourceConfigRequest
+	if unpaused && pause.catchUpPending && pause.lastConfig != nil {
+		catchUpConfig = pause.lastConfig
+		pause.catchUpPending = false
+	}
+	agent.declUpdate.Unlock()
+
+	if catchUpConfig != nil {
+		log.Infof("[AS3] Reconciliation resumed, posting catch-up declaration")
+		// A pending config request is newer than the catch-up config and posts the same changes
+		select {
+		case agent.postChan <- *catchUpConfig:
+		default:
+		}
+	}
+}
+
+func (pause *tenantPause) isPaused(tenant string) bool {
+	return pause.all || pause.partitions[tenant]
+}
+
+// holdPausedTenants removes the paused tenants from incomingTenantDeclMap
+func (agent *Agent) holdPausedTenants(config ResourceConfigRequest) {
+	pause := &agent.tenantPause
+	pause.lastConfig = &config
+	for tenant := range agent.incomingTenantDeclMap {
+		if pause.isPaused(tenant) {
+			tenantLogger(tenant, config.reqId).Infof("[AS3] Reconciliation paused, skipping declaration of tenant %v", tenant)
+			delete(agent.incomingTenantDeclMap, tenant)
+			delete(agent.tenantPriorityMap, tenant)
+			pause.catchUpPending = true
+		}
+	}
+}
+
+// setupPauseControl creates the informers of the pause ConfigMap and of the namespaces paused with the
+// paused annotation
+func (ctlr *Controller) setupPauseControl() {
+	if ctlr.kubeClient == nil {
+		return
+	}
+	restClient := ctlr.kubeClient.CoreV1().RESTClient()
+	if ctlr.pauseCfgMap != "" {
+		namespaceCfgmapSlice := strings.Split(ctlr.pauseCfgMap, "/")
+		if len(namespaceCfgmapSlice) != 2 {
+			log.Errorf("Unable to watch pause ConfigMap %v", ctlr.pauseCfgMap)
+		} else {
+			ctlr.pauseInformer = cache.NewSharedIndexInformer(
+				cache.NewFilteredListWatchFromClient(
+					restClient,
+					"configmaps",
+					namespaceCfgmapSlice[0],
+					func(options *metav1.ListOptions) {
+						options.FieldSelector = fields.OneTermEqualSelector("metadata.name", namespaceCfgmapSlice[1]).String()
+					},
+				),
+				&v1.ConfigMap{},
+				0*time.Second,
+				cache.Indexers{},
+			)
+			ctlr.pauseInformer.AddEventHandler(&cache.ResourceEventHandlerFuncs{
+				AddFunc:    ctlr.updatePauseConfigMap,
+				UpdateFunc: func(old, cur interface{}) { ctlr.updatePauseConfigMap(cur) },
+				// Deleting the ConfigMap resumes reconciliation
+				DeleteFunc: func(obj interface{}) { ctlr.updatePauseState(nil) },
+			})
+		}
+	}
+	ctlr.pauseNsInformer = cache.NewSharedIndexInformer(
+		cache.NewListWatchFromClient(restClient, "namespaces", "", fields.Everything()),
+		&v1.Namespace{},
+		0*time.Second,
+		cache.Indexers{},
+	)
+	ctlr.pauseNsInformer.AddEventHandler(&cache.ResourceEventHandlerFuncs{
+		UpdateFunc: ctlr.updatePausedNamespace,
+	})
+}
+
+// updatePauseConfigMap applies the pause state of the pause ConfigMap
+func (ctlr *Controller) updatePauseConfigMap(obj interface{}) {
+	if cm, ok := obj.(*v1.ConfigMap); ok {
+		ctlr.updatePauseState(cm.Data)
+	}
+}
+
+// updatePausedNamespace releases the partitions held for the resources of a namespace which is no longer paused
+func (ctlr *Controller) updatePausedNamespace(old, cur interface{}) {
+	oldNs, ok := old.(*v1.Namespace)
+	if !ok {
+		return
+	}
+	ns, ok := cur.(*v1.Namespace)
+	if !ok || oldNs.Annotations[PausedAnnotation] == ns.Annotations[PausedAnnotation] {
+		return
+	}
+	log.Infof("Reconciliation pause of namespace %v updated, paused: %v", ns.Name, ns.Annotations[PausedAnnotation] == "true")
+	ctlr.pause.Lock()
+	ctlr.releaseResumedKeysLocked()
+	all := ctlr.pause.all
+	pausedPartitions := ctlr.pausedPartitionsLocked()
+	ctlr.pause.Unlock()
+
+	ctlr.Agent.SetPausedTenants(all, pausedPartitions)
+}
+
+// isNamespacePaused checks the paused annotation of the namespace in the namespace informer cache
+func (ctlr *Controller) isNamespacePaused(namespace string) bool {
+	if ctlr.pauseNsInformer == nil {
+		return false
+	}
+	obj, found, err := ctlr.pauseNsInformer.GetIndexer().GetByKey(namespace)
+	if err != nil || !found {
+		return false
+	}
+	return obj.(*v1.Namespace).Annotations[PausedAnnotation] == "true"
+}
+
+// updatePauseState applies the pause state from the pause ConfigMap data and
+// releases the partitions held for the resources in the namespaces which are no longer paused
+func (ctlr *Controller) updatePauseState(data map[string]string) {
+	all := strings.TrimSpace(data[pauseAllKey]) == "true"
+	partitions := parsePauseList(data[pausePartitionsKey])
+	namespaces := parsePauseList(data[pauseNamespacesKey])
+
+	ctlr.pause.Lock()
+	if all != ctlr.pause.all || !reflect.DeepEqual(partitions, ctlr.pause.partitions) ||
+		!reflect.DeepEqual(namespaces, ctlr.pause.namespaces) {
+		log.Infof("Reconciliation pause updated, controller: %v, partitions: %v, namespaces: %v",
+			all, data[pausePartitionsKey], data[pauseNamespacesKey])
+	}
+	ctlr.pause.all = all
+	ctlr.pause.partitions = partitions
+	ctlr.pause.namespaces = namespaces
+	ctlr.releaseResumedKeysLocked()
+	pausedPartitions := ctlr.pausedPartitionsLocked()
+	ctlr.pause.Unlock()
+
+	ctlr.Agent.SetPausedTenants(all, pausedPartitions)
+}
+
+// releaseResumedKeysLocked releases the partitions held for the resources which are no longer paused
+func (ctlr *Controller) releaseResumedKeysLocked() {
+	for keyName, paused := range ctlr.pause.pausedKeys {
+		if !ctlr.isKeyPausedLocked(paused.key) {
+			delete(ctlr.pause.pausedKeys, keyName)
+		}
+	}
+}
+
+// pausedPartitionsLocked returns the paused partitions and the partitions holding the changes of paused resources
+func (ctlr *Controller) pausedPartitionsLocked() map[string]bool {
+	partitions := make(map[string]bool)
+	for partition := range ctlr.pause.partitions {
+		partitions[partition] = true
+	}
+	for _, paused := range ctlr.pause.pausedKeys {
+		for partition := range paused.partitions {
+			partitions[partition] = true
+		}
+	}
+	return partitions
+}
+
+// holdPausedPartitions holds the partitions with changes of the paused resource so that they are not
+// posted until the resource is resumed
+func (ctlr *Controller) holdPausedPartitions(rKey *rqKey, partitions []string) {
+	ctlr.pause.Lock()
+	paused, ok := ctlr.pause.pausedKeys[rKey.pauseKeyName()]
+	if !ok {
+		ctlr.pause.Unlock()
+		return
+	}
+	held := false
+	for _, partition := range partitions {
+		if !paused.partitions[partition] {
+			paused.partitions[partition] = true
+			held = true
+		}
+	}
+	all := ctlr.pause.all
+	pausedPartitions := ctlr.pausedPartitionsLocked()
+	ctlr.pause.Unlock()
+
+	if held {
+		rKey.logger().Infof("Reconciliation paused, holding partitions %v", partitions)
+		ctlr.Agent.SetPausedTenants(all, pausedPartitions)
+	}
+}
+
+// holdChangedPartitions holds the partitions changed by processing the paused resource
+func (ctlr *Controller) holdChangedPartitions(rKey *rqKey, ltmConfig LTMConfig, gtmConfig GTMConfig) {
+	var partitions []string
+	for partition, partitionConfig := range ctlr.resources.ltmConfig {
+		if prevConfig, ok := ltmConfig[partition]; !ok ||
+			!reflect.DeepEqual(prevConfig.ResourceMap, partitionConfig.ResourceMap) {
+			partitions = append(partitions, partition)
+		}
+	}
+	for partition := range ltmConfig {
+		if _, ok := ctlr.resources.ltmConfig[partition]; !ok {
+			partitions = append(partitions, partition)
+		}
+	}
+	for partition, gtmPartitionConfig := range ctlr.resources.gtmConfig {
+		if !reflect.DeepEqual(gtmConfig[partition], gtmPartitionConfig) {
+			partitions = append(partitions, partition)
+		}
+	}
+	for partition := range gtmConfig {
+		if _, ok := ctlr.resources.gtmConfig[partition]; !ok {
+			partitions = append(partitions, partition)
+		}
+	}
+	if len(partitions) > 0 {
+		ctlr.holdPausedPartitions(rKey, partitions)
+	}
+}
+
+func parsePauseList(value string) map[string]bool {
+	list := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list[item] = true
+		}
+	}
+	return list
+}
+
+// isKeyPaused reports whether the resource event belongs to a paused namespace or resource.
+// The changes of a paused resource are held in their partitions until the resource is resumed.
+func (ctlr *Controller) isKeyPaused(rKey *rqKey) bool {
+	// Namespace events manage the informers and are never paused
+	if rKey.kind == Namespace {
+		return false
+	}
+	ctlr.pause.Lock()
+	keyName := rKey.pauseKeyName()
+	if !ctlr.isKeyPausedLocked(rKey) {
+		_, held := ctlr.pause.pausedKeys[keyName]
+		delete(ctlr.pause.pausedKeys, keyName)
+		all := ctlr.pause.all
+		pausedPartitions := ctlr.pausedPartitionsLocked()
+		ctlr.pause.Unlock()
+		if held {
+			ctlr.Agent.SetPausedTenants(all, pausedPartitions)
+		}
+		return false
+	}
+	if ctlr.pause.pausedKeys == nil {
+		ctlr.pause.pausedKeys = make(map[string]*pausedResource)
+	}
+	if paused, ok := ctlr.pause.pausedKeys[keyName]; ok {
+		paused.key = rKey
+	} else {
+		ctlr.pause.pausedKeys[keyName] = &pausedResource{key: rKey, partitions: make(map[string]bool)}
+	}
+	ctlr.pause.Unlock()
+	return true
+}
+
+// holdPausedResource holds the partition of a paused resource processed on behalf of another resource event,
+// so that the changes computed for the paused resource are not posted until it is resumed
+func (ctlr *Controller) holdPausedResource(kind string, obj metav1.Object, partition string) {
+	rKey := &rqKey{
+		namespace: obj.GetNamespace(),
+		kind:      kind,
+		rscName:   obj.GetName(),
+		rsc:       obj,
+		event:     Update,
+	}
+	if ctlr.isKeyPaused(rKey) {
+		ctlr.holdPausedPartitions(rKey, []string{partition})
+	}
+}
+
+func (rKey *rqKey) pauseKeyName() string {
+	return rKey.kind + "/" + rKey.namespace + "/" + rKey.rscName
+}
+
+func (ctlr *Controller) isKeyPausedLocked(rKey *rqKey) bool {
+	if ctlr.pause.namespaces[rKey.namespace] || ctlr.isNamespacePaused(rKey.namespace) {
+		return true
+	}
+	obj, err := meta.Accessor(rKey.rsc)
+	if err != nil {
+		return false
+	}
+	return obj.GetAnnotations()[PausedAnnotation] == "true"
+}
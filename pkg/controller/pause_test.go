@@ -0,0 +1,174 @@
+package controller
+
+import (
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v2/config/apis/cis/v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var _ = Describe("Reconciliation Pause Tests", func() {
+	var agent *Agent
+
+	BeforeEach(func() {
+		agent = newMockAgent(nil)
+		agent.tenantPriorityMap = make(map[string]int)
+		agent.retryTenantDeclMap = make(map[string]*tenantParams)
+	})
+
+	It("Skips paused tenants and posts catch-up config", func() {
+		agent.SetPausedTenants(false, map[string]bool{"test": true})
+		agent.incomingTenantDeclMap = map[string]as3Tenant{
+			"test":  {"class": "Tenant"},
+			"test2": {"class": "Tenant"},
+		}
+		agent.holdPausedTenants(ResourceConfigRequest{reqId: 1})
+		Expect(agent.incomingTenantDeclMap).NotTo(HaveKey("test"))
+		Expect(agent.incomingTenantDeclMap).To(HaveKey("test2"))
+		Expect(agent.tenantPause.catchUpPending).To(BeTrue())
+
+		// Pausing another partition does not resume the paused one
+		agent.SetPausedTenants(false, map[string]bool{"test": true, "test2": true})
+		Expect(agent.postChan).NotTo(Receive())
+
+		agent.SetPausedTenants(false, map[string]bool{"test2": true})
+		var rsConfig ResourceConfigRequest
+		Expect(agent.postChan).To(Receive(&rsConfig))
+		Expect(rsConfig.reqId).To(Equal(1))
+		Expect(agent.tenantPause.catchUpPending).To(BeFalse())
+	})
+
+	It("Pauses all tenants and drops their retries", func() {
+		agent.retryTenantDeclMap["test"] = &tenantParams{}
+		agent.SetPausedTenants(true, map[string]bool{})
+		Expect(agent.retryTenantDeclMap).To(BeEmpty())
+		agent.incomingTenantDeclMap = map[string]as3Tenant{"test2": {"class": "Tenant"}}
+		agent.holdPausedTenants(ResourceConfigRequest{reqId: 2})
+		Expect(agent.incomingTenantDeclMap).To(BeEmpty())
+
+		agent.SetPausedTenants(false, map[string]bool{})
+		Expect(agent.postChan).To(Receive())
+		Expect(agent.tenantPause.isPaused("test")).To(BeFalse())
+	})
+
+	Describe("Resource pause", func() {
+		var mockCtlr *mockController
+		var vs *cisapiv1.VirtualServer
+
+		BeforeEach(func() {
+			mockCtlr = newMockController()
+			mockCtlr.Agent = agent
+			mockCtlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
+				workqueue.DefaultControllerRateLimiter(), "custom-resource-controller")
+			vs = &cisapiv1.VirtualServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "vs1", Namespace: "default"},
+			}
+		})
+
+		It("Pauses annotated resources", func() {
+			key := &rqKey{namespace: "default", kind: VirtualServer, rscName: "vs1", rsc: vs, event: Create}
+			Expect(mockCtlr.isKeyPaused(key)).To(BeFalse())
+
+			vs.Annotations = map[string]string{PausedAnnotation: "true"}
+			Expect(mockCtlr.isKeyPaused(key)).To(BeTrue())
+			mockCtlr.holdPausedResource(VirtualServer, vs, "test")
+			Expect(mockCtlr.pause.pausedKeys).To(HaveLen(1))
+			// The partition of the paused resource is held
+			Expect(agent.tenantPause.isPaused("test")).To(BeTrue())
+			Expect(agent.tenantPause.isPaused("test2")).To(BeFalse())
+			agent.incomingTenantDeclMap = map[string]as3Tenant{"test": {"class": "Tenant"}}
+			agent.holdPausedTenants(ResourceConfigRequest{reqId: 3})
+			Expect(agent.incomingTenantDeclMap).To(BeEmpty())
+
+			// Update removing the annotation releases the partition and posts the catch-up config
+			unpausedVS := vs.DeepCopy()
+			unpausedVS.Annotations = nil
+			key = &rqKey{namespace: "default", kind: VirtualServer, rscName: "vs1", rsc: unpausedVS, event: Update}
+			Expect(mockCtlr.isKeyPaused(key)).To(BeFalse())
+			Expect(mockCtlr.pause.pausedKeys).To(BeEmpty())
+			Expect(agent.tenantPause.isPaused("test")).To(BeFalse())
+			var rsConfig ResourceConfigRequest
+			Expect(agent.postChan).To(Receive(&rsConfig))
+			Expect(rsConfig.reqId).To(Equal(3))
+		})
+
+		It("Holds the partitions changed by paused resources", func() {
+			mockCtlr.resources = NewResourceStore()
+			key := &rqKey{namespace: "default", kind: VirtualServer, rscName: "vs1", rsc: vs, event: Create}
+			mockCtlr.updatePauseState(map[string]string{pauseNamespacesKey: "default"})
+			Expect(mockCtlr.isKeyPaused(key)).To(BeTrue())
+			ltmConfig := mockCtlr.resources.getLTMConfigDeepCopy()
+			gtmConfig := mockCtlr.resources.getGTMConfigCopy()
+			rsCfg := &ResourceConfig{}
+			rsCfg.Virtual.Name = "vs1_80"
+			mockCtlr.resources.getPartitionResourceMap("test")["vs1_80"] = rsCfg
+			mockCtlr.holdChangedPartitions(key, ltmConfig, gtmConfig)
+			Expect(mockCtlr.pause.pausedKeys[key.pauseKeyName()].partitions).To(Equal(map[string]bool{"test": true}))
+			Expect(agent.tenantPause.isPaused("test")).To(BeTrue())
+		})
+
+		It("Pauses namespaces and releases their partitions when resumed", func() {
+			mockCtlr.updatePauseState(map[string]string{pauseNamespacesKey: "default, test"})
+			Expect(mockCtlr.pause.namespaces).To(HaveKey("test"))
+
+			nsKey := &rqKey{namespace: "default", kind: Namespace, rscName: "default", rsc: &v1.Namespace{}}
+			Expect(mockCtlr.isKeyPaused(nsKey)).To(BeFalse())
+			key := &rqKey{namespace: "default", kind: VirtualServer, rscName: "vs1", rsc: vs, event: Create}
+			Expect(mockCtlr.isKeyPaused(key)).To(BeTrue())
+			deleteKey := &rqKey{namespace: "default", kind: VirtualServer, rscName: "vs1", rsc: vs, event: Delete}
+			Expect(mockCtlr.isKeyPaused(deleteKey)).To(BeTrue())
+			mockCtlr.holdPausedPartitions(deleteKey, []string{"test"})
+			Expect(agent.tenantPause.isPaused("test")).To(BeTrue())
+
+			mockCtlr.updatePauseState(map[string]string{pauseNamespacesKey: "test"})
+			Expect(mockCtlr.pause.pausedKeys).To(BeEmpty())
+			Expect(agent.tenantPause.isPaused("test")).To(BeFalse())
+		})
+
+		It("Watches pause ConfigMap", func() {
+			mockCtlr.pauseCfgMap = "kube-system/cis-pause"
+			mockCtlr.kubeClient = k8sfake.NewSimpleClientset()
+			mockCtlr.setupPauseControl()
+			Expect(mockCtlr.pauseInformer).NotTo(BeNil())
+			mockCtlr.updatePauseConfigMap(&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "cis-pause", Namespace: "kube-system"},
+				Data:       map[string]string{pauseAllKey: "true", pausePartitionsKey: "test"},
+			})
+			Expect(mockCtlr.pause.all).To(BeTrue())
+			Expect(agent.tenantPause.all).To(BeTrue())
+			Expect(agent.tenantPause.partitions).To(HaveKey("test"))
+
+			// Deleted ConfigMap resumes reconciliation
+			mockCtlr.updatePauseState(nil)
+			Expect(agent.tenantPause.all).To(BeFalse())
+		})
+
+		It("Pauses annotated namespaces", func() {
+			mockCtlr.kubeClient = k8sfake.NewSimpleClientset()
+			mockCtlr.setupPauseControl()
+			Expect(mockCtlr.pauseInformer).To(BeNil())
+			ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: map[string]string{PausedAnnotation: "true"},
+			}}
+			store := mockCtlr.pauseNsInformer.GetStore()
+			Expect(store.Add(ns)).To(Succeed())
+
+			key := &rqKey{namespace: "default", kind: VirtualServer, rscName: "vs1", rsc: vs, event: Create}
+			Expect(mockCtlr.isKeyPaused(key)).To(BeTrue())
+			mockCtlr.holdPausedPartitions(key, []string{"test"})
+			Expect(agent.tenantPause.isPaused("test")).To(BeTrue())
+
+			// Removing the annotation releases the partitions of the namespace
+			resumed := ns.DeepCopy()
+			resumed.Annotations = nil
+			Expect(store.Update(resumed)).To(Succeed())
+			mockCtlr.updatePausedNamespace(ns, resumed)
+			Expect(mockCtlr.pause.pausedKeys).To(BeEmpty())
+			Expect(agent.tenantPause.isPaused("test")).To(BeFalse())
+		})
+	})
+})
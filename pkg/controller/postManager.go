@@ -18,8 +18,6 @@ package controller
 
 import (
 	"bytes"
-	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -27,7 +25,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/bigiptls"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -41,30 +43,30 @@ func NewPostManager(params PostParams) *PostManager {
 		PostParams: params,
 		firstPost:  true,
 	}
+	if params.PostConcurrency > 0 {
+		pm.postSlots = make(chan struct{}, params.PostConcurrency)
+	}
+	pm.breaker = newCircuitBreaker(params.BIGIPURL, params.CircuitBreakerThreshold, params.CircuitBreakerInterval)
 	pm.setupBIGIPRESTClient()
 
 	return pm
 }
 
 func (postMgr *PostManager) setupBIGIPRESTClient() {
-	// Get the SystemCertPool, continue with an empty pool on error
-	rootCAs, _ := x509.SystemCertPool()
-	if rootCAs == nil {
-		rootCAs = x509.NewCertPool()
-	}
 	// TODO: Make sure appMgr sets certificates in bigipInfo
-	certs := []byte(postMgr.TrustedCerts)
-
-	// Append our certs to the system pool
-	if ok := rootCAs.AppendCertsFromPEM(certs); !ok {
-		log.Debug("[AS3] No certs appended, using only system certs")
+	tlsConfig, err := bigiptls.NewConfig(bigiptls.Params{
+		TrustedCerts: postMgr.TrustedCerts,
+		SSLInsecure:  postMgr.SSLInsecure,
+		ServerName:   postMgr.ServerName,
+		ClientCert:   postMgr.ClientCert,
+		ClientKey:    postMgr.ClientKey,
+	})
+	if err != nil {
+		log.Errorf("[AS3] %v", err)
 	}
 
 	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: postMgr.SSLInsecure,
-			RootCAs:            rootCAs,
-		},
+		TLSClientConfig: tlsConfig,
 	}
 
 	postMgr.httpClient = &http.Client{
@@ -73,211 +75,394 @@ func (postMgr *PostManager) setupBIGIPRESTClient() {
 	}
 }
 
+// UpdateCredentials swaps the BIG-IP credentials used for subsequent requests.
+// Requests that are already in flight complete with the credentials they started with.
+func (postMgr *PostManager) UpdateCredentials(username, password, url string) {
+	postMgr.credsMutex.Lock()
+	defer postMgr.credsMutex.Unlock()
+	postMgr.BIGIPUsername = username
+	postMgr.BIGIPPassword = password
+	postMgr.BIGIPURL = url
+}
+
+func (postMgr *PostManager) getBIGIPURL() string {
+	postMgr.credsMutex.RLock()
+	defer postMgr.credsMutex.RUnlock()
+	return postMgr.BIGIPURL
+}
+
+func (postMgr *PostManager) setBasicAuth(req *http.Request) {
+	postMgr.credsMutex.RLock()
+	defer postMgr.credsMutex.RUnlock()
+	req.SetBasicAuth(postMgr.BIGIPUsername, postMgr.BIGIPPassword)
+}
+
+// SetLogResponse enables or disables logging of AS3 responses from BIG-IP
+func (postMgr *PostManager) SetLogResponse(enabled bool) {
+	postMgr.settingsMutex.Lock()
+	defer postMgr.settingsMutex.Unlock()
+	postMgr.LogResponse = enabled
+}
+
+func (postMgr *PostManager) isLogResponseEnabled() bool {
+	postMgr.settingsMutex.RLock()
+	defer postMgr.settingsMutex.RUnlock()
+	return postMgr.LogResponse
+}
+
+// SetPostDelay sets the time (in seconds) waited before posting a declaration to BIG-IP
+func (postMgr *PostManager) SetPostDelay(seconds int) {
+	postMgr.settingsMutex.Lock()
+	defer postMgr.settingsMutex.Unlock()
+	postMgr.AS3PostDelay = seconds
+}
+
+func (postMgr *PostManager) getPostDelay() int {
+	postMgr.settingsMutex.RLock()
+	defer postMgr.settingsMutex.RUnlock()
+	return postMgr.AS3PostDelay
+}
+
 func (postMgr *PostManager) getAS3APIURL(tenants []string) string {
-	apiURL := postMgr.BIGIPURL + "/mgmt/shared/appsvcs/declare/" + strings.Join(tenants, ",")
+	apiURL := postMgr.getBIGIPURL() + "/mgmt/shared/appsvcs/declare/" + strings.Join(tenants, ",")
 	return apiURL
 }
 
 func (postMgr *PostManager) getAS3TaskIdURL(taskId string) string {
-	apiURL := postMgr.BIGIPURL + "/mgmt/shared/appsvcs/task/" + taskId
+	apiURL := postMgr.getBIGIPURL() + "/mgmt/shared/appsvcs/task/" + taskId
 	return apiURL
 }
 
+// logger returns a logger recording the request id and tenants of the declaration with each message
+func (cfg *agentConfig) logger() *log.Entry {
+	return log.WithFields(log.Fields{
+		log.FieldComponent: "postmanager",
+		log.FieldRequestID: cfg.id,
+		log.FieldTenant:    strings.Join(cfg.tenants, ","),
+	})
+}
+
+// isFirstPost checks that no declaration is posted to BIG-IP yet
+func (postMgr *PostManager) isFirstPost() bool {
+	postMgr.settingsMutex.RLock()
+	defer postMgr.settingsMutex.RUnlock()
+	return postMgr.firstPost
+}
+
+// acquirePostSlot blocks while PostConcurrency declarations are being posted
+func (postMgr *PostManager) acquirePostSlot() {
+	if postMgr.postSlots != nil {
+		postMgr.postSlots <- struct{}{}
+	}
+}
+
+func (postMgr *PostManager) releasePostSlot() {
+	if postMgr.postSlots != nil {
+		<-postMgr.postSlots
+	}
+}
+
 // publishConfig posts incoming configuration to BIG-IP
-func (postMgr *PostManager) publishConfig(cfg agentConfig) {
+func (postMgr *PostManager) publishConfig(cfg *agentConfig) {
 	// For the very first post after starting controller, need not wait to post
-	if !postMgr.firstPost && postMgr.AS3PostDelay != 0 {
+	if postDelay := postMgr.getPostDelay(); !postMgr.isFirstPost() && postDelay != 0 {
 		// Time (in seconds) that CIS waits to post the AS3 declaration to BIG-IP.
-		log.Debugf("[AS3] Delaying post to BIG-IP for %v seconds", postMgr.AS3PostDelay)
-		_ = <-time.After(time.Duration(postMgr.AS3PostDelay) * time.Second)
+		log.Debugf("[AS3] Delaying post to BIG-IP for %v seconds", postDelay)
+		_ = <-time.After(time.Duration(postDelay) * time.Second)
 	}
 
-	log.Debug("[AS3] PostManager Accepted the configuration")
+	cfg.logger().Debug("[AS3] PostManager Accepted the configuration")
 
-	// postConfig updates the tenantResponseMap with response codes
-	postMgr.postConfig(&cfg)
+	// postConfig updates the tenantResponseMap of cfg with response codes
+	postMgr.postConfig(cfg)
 }
 
 func (postMgr *PostManager) postConfig(cfg *agentConfig) {
+	cfgLog := cfg.logger()
+	ctx, span := startSpan(cfg.spanCtx, "postConfig",
+		attrRequestID.Int(cfg.id),
+		tenantsAttribute(cfg.tenants),
+	)
+	defer span.End()
 	httpReqBody := bytes.NewBuffer([]byte(cfg.data))
-	req, err := http.NewRequest("POST", cfg.as3APIURL, httpReqBody)
+	req, err := http.NewRequestWithContext(ctx, "POST", cfg.as3APIURL, httpReqBody)
 	if err != nil {
-		log.Errorf("[AS3] Creating new HTTP request error: %v ", err)
+		cfgLog.Errorf("[AS3] Creating new HTTP request error: %v ", err)
+		endSpan(span, err)
 		return
 	}
-	log.Debugf("[AS3] posting request to %v", cfg.as3APIURL)
-	req.SetBasicAuth(postMgr.BIGIPUsername, postMgr.BIGIPPassword)
+	if !postMgr.breaker.allow() {
+		// The declaration is posted again once BIG-IP is available
+		cfgLog.Debugf("[AS3] BIG-IP is unavailable, skipping post to %v", cfg.as3APIURL)
+		cfg.tenantResponseMap.update(http.StatusServiceUnavailable, "", "")
+		span.SetStatus(codes.Error, "BIG-IP unavailable")
+		return
+	}
+	cfgLog.Debugf("[AS3] posting request to %v", cfg.as3APIURL)
+	postMgr.setBasicAuth(req)
 
-	httpResp, responseMap := postMgr.httpPOST(req)
+	postMgr.acquirePostSlot()
+	httpResp, responseMap := postMgr.httpPOST(req, cfgLog)
+	postMgr.releasePostSlot()
 	if httpResp == nil || responseMap == nil {
+		postMgr.breaker.failure()
+		span.SetStatus(codes.Error, "AS3 post failed")
 		return
 	}
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(httpResp.StatusCode))
+	postMgr.recordBreakerResponse(httpResp.StatusCode, responseMap)
 
-	if postMgr.firstPost {
-		postMgr.firstPost = false
-	}
+	postMgr.settingsMutex.Lock()
+	postMgr.firstPost = false
+	postMgr.settingsMutex.Unlock()
 
 	switch httpResp.StatusCode {
 	case http.StatusOK:
-		postMgr.handleResponseStatusOK(responseMap)
+		postMgr.handleResponseStatusOK(responseMap, cfg, cfgLog)
 	case http.StatusCreated, http.StatusAccepted:
-		postMgr.handleResponseAccepted(responseMap)
+		postMgr.handleResponseAccepted(responseMap, cfg, cfgLog)
 	case http.StatusMultiStatus:
-		postMgr.handleMultiStatus(responseMap)
+		postMgr.handleMultiStatus(responseMap, cfg, cfgLog)
 	case http.StatusServiceUnavailable:
-		postMgr.handleResponseStatusServiceUnavailable(responseMap)
+		postMgr.handleResponseStatusServiceUnavailable(responseMap, cfg, cfgLog)
 	case http.StatusNotFound:
-		postMgr.handleResponseStatusNotFound(responseMap)
+		postMgr.handleResponseStatusNotFound(responseMap, cfg, cfgLog)
 	default:
-		postMgr.handleResponseOthers(responseMap, cfg)
+		postMgr.handleResponseOthers(responseMap, cfg, cfgLog)
 	}
 
 }
 
-func (postMgr *PostManager) httpPOST(request *http.Request) (*http.Response, map[string]interface{}) {
+// recordBreakerResponse records a response of BIG-IP in the circuit breaker. A 503 counts as a failure only if
+// the BIG-IP is unavailable, not if AS3 is busy with another declaration, which is retried with backoff.
+func (postMgr *PostManager) recordBreakerResponse(statusCode int, responseMap map[string]interface{}) {
+	if statusCode == http.StatusServiceUnavailable && !isAS3Busy(responseMap) {
+		postMgr.breaker.failure()
+	} else {
+		postMgr.breaker.success()
+	}
+}
+
+// isAS3Busy checks that a 503 response reports a declaration in progress on BIG-IP
+func isAS3Busy(responseMap map[string]interface{}) bool {
+	messages := []interface{}{responseMap["message"]}
+	if err, ok := (responseMap["error"]).(map[string]interface{}); ok {
+		messages = append(messages, err["message"])
+	}
+	for _, message := range messages {
+		msg, ok := message.(string)
+		if !ok {
+			continue
+		}
+		msg = strings.ToLower(msg)
+		if strings.Contains(msg, "in progress") || strings.Contains(msg, "please try again") {
+			return true
+		}
+	}
+	return false
+}
+
+func (postMgr *PostManager) httpPOST(request *http.Request, reqLog *log.Entry) (*http.Response, map[string]interface{}) {
 	httpResp, err := postMgr.httpClient.Do(request)
 	if err != nil {
-		log.Errorf("[AS3] REST call error: %v ", err)
+		reqLog.Errorf("[AS3] REST call error: %v ", err)
 		return nil, nil
 	}
 	defer httpResp.Body.Close()
 
 	body, err := ioutil.ReadAll(httpResp.Body)
 	if err != nil {
-		log.Errorf("[AS3] REST call response error: %v ", err)
+		reqLog.Errorf("[AS3] REST call response error: %v ", err)
 		return nil, nil
 	}
 	var response map[string]interface{}
 	err = json.Unmarshal(body, &response)
 	if err != nil {
-		log.Errorf("[AS3] Response body unmarshal failed: %v\n", err)
-		if postMgr.LogResponse {
-			log.Errorf("[AS3] Raw response from Big-IP: %v", string(body))
+		reqLog.Errorf("[AS3] Response body unmarshal failed: %v\n", err)
+		if postMgr.isLogResponseEnabled() {
+			reqLog.Errorf("[AS3] Raw response from Big-IP: %v", string(body))
 		}
 		return nil, nil
 	}
 	return httpResp, response
 }
 
-func (postMgr *PostManager) updateTenantResponse(code int, id string, tenant string) {
+func (responses tenantResponses) update(code int, id string, tenant string) {
 	// Update status for a specific tenant if mentioned, else update the response for all tenants
 	if tenant != "" {
-		postMgr.tenantResponseMap[tenant] = tenantResponse{code, id}
+		responses[tenant] = tenantResponse{code, id}
 	} else {
-		for tenant := range postMgr.tenantResponseMap {
-			postMgr.tenantResponseMap[tenant] = tenantResponse{code, id}
+		for tenant := range responses {
+			responses[tenant] = tenantResponse{code, id}
 		}
 	}
 }
 
-func (postMgr *PostManager) handleResponseStatusOK(responseMap map[string]interface{}) {
+func (postMgr *PostManager) handleResponseStatusOK(responseMap map[string]interface{}, cfg *agentConfig, cfgLog *log.Entry) {
 	//traverse all response results
 	results := (responseMap["results"]).([]interface{})
 	for _, value := range results {
 		v := value.(map[string]interface{})
-		log.Debugf("[AS3] Response from BIG-IP: code: %v --- tenant:%v --- message: %v", v["code"], v["tenant"], v["message"])
-		postMgr.updateTenantResponse(int(v["code"].(float64)), "", v["tenant"].(string))
+		cfgLog.WithField(log.FieldTenant, v["tenant"]).Debugf("[AS3] Response from BIG-IP: code: %v --- tenant:%v --- message: %v", v["code"], v["tenant"], v["message"])
+		cfg.tenantResponseMap.update(int(v["code"].(float64)), "", v["tenant"].(string))
 	}
 }
 
-func (postMgr *PostManager) getTenantConfigStatus(id string) {
-
-	req, err := http.NewRequest("GET", postMgr.getAS3TaskIdURL(id), nil)
+func (postMgr *PostManager) getTenantConfigStatus(id string, responses tenantResponses) {
+	taskLog := log.WithFields(log.Fields{log.FieldComponent: "postmanager", log.FieldTaskID: id})
+	ctx, span := startSpan(trace.SpanContext{}, "getTenantConfigStatus", attrTaskID.String(id))
+	defer span.End()
+	req, err := http.NewRequestWithContext(ctx, "GET", postMgr.getAS3TaskIdURL(id), nil)
 	if err != nil {
-		log.Errorf("[AS3] Creating new HTTP request error: %v ", err)
+		taskLog.Errorf("[AS3] Creating new HTTP request error: %v ", err)
+		endSpan(span, err)
 		return
 	}
-	log.Debugf("[AS3] posting request with taskId to %v", postMgr.getAS3TaskIdURL(id))
-	req.SetBasicAuth(postMgr.BIGIPUsername, postMgr.BIGIPPassword)
+	if !postMgr.breaker.allow() {
+		taskLog.Debugf("[AS3] BIG-IP is unavailable, skipping task status request")
+		span.SetStatus(codes.Error, "BIG-IP unavailable")
+		return
+	}
+	taskLog.Debugf("[AS3] posting request with taskId to %v", postMgr.getAS3TaskIdURL(id))
+	postMgr.setBasicAuth(req)
 
-	httpResp, responseMap := postMgr.httpPOST(req)
+	httpResp, responseMap := postMgr.httpPOST(req, taskLog)
 	if httpResp == nil || responseMap == nil {
+		postMgr.breaker.failure()
+		span.SetStatus(codes.Error, "AS3 task status request failed")
 		return
 	}
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(httpResp.StatusCode))
+	postMgr.recordBreakerResponse(httpResp.StatusCode, responseMap)
 
 	if httpResp.StatusCode == http.StatusOK {
 		results := (responseMap["results"]).([]interface{})
+		var tenants []string
+		for _, value := range results {
+			if tenant, ok := value.(map[string]interface{})["tenant"].(string); ok {
+				tenants = append(tenants, tenant)
+			}
+		}
+		span.SetAttributes(tenantsAttribute(tenants))
 		for _, value := range results {
 			v := value.(map[string]interface{})
 			if msg, ok := v["message"]; ok && msg.(string) == "in progress" {
 				return
 			} else {
 				// reset task id, so that any failed tenants will go to post call in the next retry
-				postMgr.updateTenantResponse(int(v["code"].(float64)), "", v["tenant"].(string))
+				responses.update(int(v["code"].(float64)), "", v["tenant"].(string))
 				if _, ok := v["response"]; ok {
-					log.Debugf("[AS3] Response from BIG-IP: code: %v --- tenant:%v --- message: %v %v", v["code"], v["tenant"], v["message"], v["response"])
+					taskLog.WithField(log.FieldTenant, v["tenant"]).Debugf("[AS3] Response from BIG-IP: code: %v --- tenant:%v --- message: %v %v", v["code"], v["tenant"], v["message"], v["response"])
 				} else {
-					log.Debugf("[AS3] Response from BIG-IP: code: %v --- tenant:%v --- message: %v", v["code"], v["tenant"], v["message"])
+					taskLog.WithField(log.FieldTenant, v["tenant"]).Debugf("[AS3] Response from BIG-IP: code: %v --- tenant:%v --- message: %v", v["code"], v["tenant"], v["message"])
 				}
 			}
 		}
 	} else if httpResp.StatusCode != http.StatusServiceUnavailable {
 		// reset task id, so that any failed tenants will go to post call in the next retry
-		postMgr.updateTenantResponse(httpResp.StatusCode, "", "")
+		responses.update(httpResp.StatusCode, "", "")
 	}
 }
 
-func (postMgr *PostManager) handleMultiStatus(responseMap map[string]interface{}) {
+func (postMgr *PostManager) handleMultiStatus(responseMap map[string]interface{}, cfg *agentConfig, cfgLog *log.Entry) {
 
 	if results, ok := (responseMap["results"]).([]interface{}); ok {
 		for _, value := range results {
 			v := value.(map[string]interface{})
-			postMgr.updateTenantResponse(int(v["code"].(float64)), "", v["tenant"].(string))
+			cfg.tenantResponseMap.update(int(v["code"].(float64)), "", v["tenant"].(string))
 
 			if v["code"].(float64) != 200 {
-				log.Errorf("[AS3] Error response from BIG-IP: code: %v --- tenant:%v --- message: %v", v["code"], v["tenant"], v["message"])
+				cfgLog.WithField(log.FieldTenant, v["tenant"]).Errorf("[AS3] Error response from BIG-IP: code: %v --- tenant:%v --- message: %v", v["code"], v["tenant"], v["message"])
 			} else {
-				log.Debugf("[AS3] Response from BIG-IP: code: %v --- tenant:%v --- message: %v", v["code"], v["tenant"], v["message"])
+				cfgLog.WithField(log.FieldTenant, v["tenant"]).Debugf("[AS3] Response from BIG-IP: code: %v --- tenant:%v --- message: %v", v["code"], v["tenant"], v["message"])
 			}
 		}
 	}
 }
 
-func (postMgr *PostManager) handleResponseAccepted(responseMap map[string]interface{}) {
+func (postMgr *PostManager) handleResponseAccepted(responseMap map[string]interface{}, cfg *agentConfig, cfgLog *log.Entry) {
 	//traverse all response results
 	if respId, ok := (responseMap["id"]).(string); ok {
-		postMgr.updateTenantResponse(http.StatusAccepted, respId, "")
-		log.Debugf("[AS3] Response from BIG-IP: code 201 id %v, waiting %v seconds to poll response", respId, timeoutMedium)
+		cfg.tenantResponseMap.update(http.StatusAccepted, respId, "")
+		cfgLog.Debugf("[AS3] Response from BIG-IP: code 201 id %v, polling for the task status", respId)
 	}
 }
 
-func (postMgr *PostManager) handleResponseStatusServiceUnavailable(responseMap map[string]interface{}) {
+func (postMgr *PostManager) handleResponseStatusServiceUnavailable(responseMap map[string]interface{}, cfg *agentConfig, cfgLog *log.Entry) {
 	if err, ok := (responseMap["error"]).(map[string]interface{}); ok {
-		log.Errorf("[AS3] Big-IP Responded with error code: %v", err["code"])
+		cfgLog.Errorf("[AS3] Big-IP Responded with error code: %v", err["code"])
 	}
-	log.Debugf("[AS3] Response from BIG-IP: BIG-IP is busy, waiting %v seconds and re-posting the declaration", timeoutMedium)
-	postMgr.updateTenantResponse(http.StatusServiceUnavailable, "", "")
+	cfgLog.Debugf("[AS3] Response from BIG-IP: BIG-IP is busy, re-posting the declaration with backoff")
+	cfg.tenantResponseMap.update(http.StatusServiceUnavailable, "", "")
 }
 
-func (postMgr *PostManager) handleResponseStatusNotFound(responseMap map[string]interface{}) {
+func (postMgr *PostManager) handleResponseStatusNotFound(responseMap map[string]interface{}, cfg *agentConfig, cfgLog *log.Entry) {
 	if err, ok := (responseMap["error"]).(map[string]interface{}); ok {
-		log.Errorf("[AS3] Big-IP Responded with error code: %v", err["code"])
+		cfgLog.Errorf("[AS3] Big-IP Responded with error code: %v", err["code"])
 	} else {
-		log.Errorf("[AS3] Big-IP Responded with error code: %v", http.StatusNotFound)
+		cfgLog.Errorf("[AS3] Big-IP Responded with error code: %v", http.StatusNotFound)
 	}
-	if postMgr.LogResponse {
-		log.Errorf("[AS3] Raw response from Big-IP: %v ", responseMap)
+	if postMgr.isLogResponseEnabled() {
+		cfgLog.Errorf("[AS3] Raw response from Big-IP: %v ", responseMap)
 	}
-	postMgr.updateTenantResponse(http.StatusNotFound, "", "")
+	cfg.tenantResponseMap.update(http.StatusNotFound, "", "")
 }
 
-func (postMgr *PostManager) handleResponseOthers(responseMap map[string]interface{}, cfg *agentConfig) {
-	if postMgr.LogResponse {
-		log.Errorf("[AS3] Raw response from Big-IP: %v %v", responseMap, cfg.data)
+func (postMgr *PostManager) handleResponseOthers(responseMap map[string]interface{}, cfg *agentConfig, cfgLog *log.Entry) {
+	if postMgr.isLogResponseEnabled() {
+		cfgLog.Errorf("[AS3] Raw response from Big-IP: %v %v", responseMap, cfg.data)
 	}
 	if results, ok := (responseMap["results"]).([]interface{}); ok {
 		for _, value := range results {
 			v := value.(map[string]interface{})
-			log.Errorf("[AS3] Response from BIG-IP: code: %v --- tenant:%v --- message: %v", v["code"], v["tenant"], v["message"])
-			postMgr.updateTenantResponse(int(v["code"].(float64)), "", v["tenant"].(string))
+			cfgLog.WithField(log.FieldTenant, v["tenant"]).Errorf("[AS3] Response from BIG-IP: code: %v --- tenant:%v --- message: %v", v["code"], v["tenant"], v["message"])
+			cfg.tenantResponseMap.update(int(v["code"].(float64)), "", v["tenant"].(string))
 		}
 	} else if err, ok := (responseMap["error"]).(map[string]interface{}); ok {
-		log.Errorf("[AS3] Big-IP Responded with error code: %v", err["code"])
-		postMgr.updateTenantResponse(int(err["code"].(float64)), "", "")
+		cfgLog.Errorf("[AS3] Big-IP Responded with error code: %v", err["code"])
+		cfg.tenantResponseMap.update(int(err["code"].(float64)), "", "")
 	} else {
-		log.Errorf("[AS3] Big-IP Responded with code: %v", responseMap["code"])
-		postMgr.updateTenantResponse(int(responseMap["code"].(float64)), "", "")
+		cfgLog.Errorf("[AS3] Big-IP Responded with code: %v", responseMap["code"])
+		cfg.tenantResponseMap.update(int(responseMap["code"].(float64)), "", "")
+	}
+}
+
+// GetBigipAS3Tenants returns the tenants of the AS3 declaration on BIG-IP
+func (postMgr *PostManager) GetBigipAS3Tenants() (map[string]as3Tenant, error) {
+	url := postMgr.getBIGIPURL() + "/mgmt/shared/appsvcs/declare"
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		log.Errorf("[AS3] Creating new HTTP request error: %v ", err)
+		return nil, err
+	}
+
+	log.Debugf("[AS3] posting GET BIGIP AS3 declaration request on %v", url)
+	postMgr.setBasicAuth(req)
+
+	tenants := make(map[string]as3Tenant)
+	httpResp, err := postMgr.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	switch httpResp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		// No declaration on BIG-IP
+		return tenants, nil
+	default:
+		return nil, fmt.Errorf("Error response from BIGIP with status code %v", httpResp.StatusCode)
+	}
+	var decl map[string]interface{}
+	if err = json.NewDecoder(httpResp.Body).Decode(&decl); err != nil {
+		return nil, err
 	}
+	for name, obj := range decl {
+		if tenant, ok := obj.(map[string]interface{}); ok && tenant["class"] == "Tenant" {
+			tenants[name] = tenant
+		}
+	}
+	return tenants, nil
 }
 
 func (postMgr *PostManager) GetBigipAS3Version() (string, string, string, error) {
@@ -289,7 +474,7 @@ func (postMgr *PostManager) GetBigipAS3Version() (string, string, string, error)
 	}
 
 	log.Debugf("[AS3] posting GET BIGIP AS3 Version request on %v", url)
-	req.SetBasicAuth(postMgr.BIGIPUsername, postMgr.BIGIPPassword)
+	postMgr.setBasicAuth(req)
 
 	httpResp, responseMap := postMgr.httpReq(req)
 	if httpResp == nil || responseMap == nil {
@@ -326,7 +511,7 @@ func (postMgr *PostManager) GetBigipRegKey() (string, error) {
 	}
 
 	log.Debugf("Posting GET BIGIP Reg Key request on %v", url)
-	req.SetBasicAuth(postMgr.BIGIPUsername, postMgr.BIGIPPassword)
+	postMgr.setBasicAuth(req)
 
 	httpResp, responseMap := postMgr.httpReq(req)
 	if httpResp == nil || responseMap == nil {
@@ -365,7 +550,7 @@ func (postMgr *PostManager) httpReq(request *http.Request) (*http.Response, map[
 	err = json.Unmarshal(body, &response)
 	if err != nil {
 		log.Errorf("Response body unmarshal failed: %v\n", err)
-		if postMgr.LogResponse {
+		if postMgr.isLogResponseEnabled() {
 			log.Errorf("Raw response from Big-IP: %v", string(body))
 		}
 		return nil, nil
@@ -374,13 +559,13 @@ func (postMgr *PostManager) httpReq(request *http.Request) (*http.Response, map[
 }
 
 func (postMgr *PostManager) getAS3VersionURL() string {
-	apiURL := postMgr.BIGIPURL + "/mgmt/shared/appsvcs/info"
+	apiURL := postMgr.getBIGIPURL() + "/mgmt/shared/appsvcs/info"
 	return apiURL
 
 }
 
 func (postMgr *PostManager) getBigipRegKeyURL() string {
-	apiURL := postMgr.BIGIPURL + "/mgmt/tm/shared/licensing/registration"
+	apiURL := postMgr.getBIGIPURL() + "/mgmt/tm/shared/licensing/registration"
 	return apiURL
 
 }
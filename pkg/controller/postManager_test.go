@@ -1,17 +1,57 @@
 package controller
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	"net/http"
 )
 
+// newTestCert returns a PEM encoded certificate and key signed by the parent, self-signed when parent is nil
+func newTestCert(cn string, dnsNames []string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (
+	*x509.Certificate, *ecdsa.PrivateKey, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		DNSNames:              dnsNames,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	Expect(err).ToNot(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).ToNot(HaveOccurred())
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	Expect(err).ToNot(HaveOccurred())
+	return cert, key,
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
 var _ = Describe("PostManager Tests", func() {
 	var mockPM *mockPostManager
 	BeforeEach(func() {
 		mockPM = newMockPostManger()
-		mockPM.tenantResponseMap = make(map[string]tenantResponse)
 		mockPM.LogResponse = true
 		mockPM.AS3PostDelay = 2
 	})
@@ -20,6 +60,74 @@ var _ = Describe("PostManager Tests", func() {
 		mockPM.setupBIGIPRESTClient()
 	})
 
+	Describe("Mutual TLS", func() {
+		var server *httptest.Server
+		var caPEM, clientCert, clientKey string
+
+		BeforeEach(func() {
+			ca, caKey, caCert, _ := newTestCert("ca", nil, true, nil, nil)
+			caPEM = caCert
+			_, _, serverCert, serverKey := newTestCert("bigip", []string{"bigip.mgmt.local"}, false, ca, caKey)
+			_, _, clientCert, clientKey = newTestCert("cis", nil, false, ca, caKey)
+
+			serverPair, err := tls.X509KeyPair([]byte(serverCert), []byte(serverKey))
+			Expect(err).ToNot(HaveOccurred())
+			clientCAs := x509.NewCertPool()
+			clientCAs.AddCert(ca)
+			server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"registrationKey": "sfiifhanji"}`))
+			}))
+			server.TLS = &tls.Config{
+				Certificates: []tls.Certificate{serverPair},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    clientCAs,
+			}
+			server.StartTLS()
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Authenticates with client certificate and verifies server name", func() {
+			pm := NewPostManager(PostParams{
+				BIGIPURL:     server.URL,
+				TrustedCerts: caPEM,
+				SSLInsecure:  true,
+				ClientCert:   clientCert,
+				ClientKey:    clientKey,
+				ServerName:   "bigip.mgmt.local",
+			})
+			key, err := pm.GetBigipRegKey()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key).To(Equal("sfiifhanji"))
+		})
+
+		It("Rejects server with unexpected name", func() {
+			pm := NewPostManager(PostParams{
+				BIGIPURL:     server.URL,
+				TrustedCerts: caPEM,
+				SSLInsecure:  true,
+				ClientCert:   clientCert,
+				ClientKey:    clientKey,
+				ServerName:   "other.mgmt.local",
+			})
+			_, err := pm.GetBigipRegKey()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Fails without client certificate", func() {
+			pm := NewPostManager(PostParams{
+				BIGIPURL:    server.URL,
+				SSLInsecure: true,
+				ClientCert:  "invalid",
+				ClientKey:   "invalid",
+			})
+			_, err := pm.GetBigipRegKey()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Describe("Post Config and Handle Response", func() {
 		var agentCfg agentConfig
 		BeforeEach(func() {
@@ -27,11 +135,12 @@ var _ = Describe("PostManager Tests", func() {
 			mockPM.BIGIPUsername = "user"
 			mockPM.BIGIPPassword = "pswd"
 			agentCfg = agentConfig{
-				data:      "{}",
-				as3APIURL: mockPM.getAS3APIURL([]string{"test"}),
-				id:        0,
+				data:              "{}",
+				as3APIURL:         mockPM.getAS3APIURL([]string{"test"}),
+				id:                0,
+				tenants:           []string{"test"},
+				tenantResponseMap: make(tenantResponses),
 			}
-			mockPM.tenantResponseMap = make(map[string]tenantResponse)
 		})
 
 		It("Handle First Post", func() {
@@ -42,8 +151,8 @@ var _ = Describe("PostManager Tests", func() {
 				body:   "",
 			}}, http.MethodPost)
 			mockPM.firstPost = false
-			mockPM.publishConfig(agentCfg)
-			Expect(mockPM.tenantResponseMap[tnt].agentResponseCode).To(BeEquivalentTo(http.StatusOK), "Posting Failed")
+			mockPM.publishConfig(&agentCfg)
+			Expect(agentCfg.tenantResponseMap[tnt].agentResponseCode).To(BeEquivalentTo(http.StatusOK), "Posting Failed")
 		})
 
 		It("Handle HTTP StatusOK", func() {
@@ -53,8 +162,8 @@ var _ = Describe("PostManager Tests", func() {
 				status: http.StatusOK,
 				body:   "",
 			}}, http.MethodPost)
-			mockPM.publishConfig(agentCfg)
-			Expect(mockPM.tenantResponseMap[tnt].agentResponseCode).To(BeEquivalentTo(http.StatusOK), "Posting Failed")
+			mockPM.publishConfig(&agentCfg)
+			Expect(agentCfg.tenantResponseMap[tnt].agentResponseCode).To(BeEquivalentTo(http.StatusOK), "Posting Failed")
 		})
 
 		It("Handle HTTP Status Accepted", func() {
@@ -70,9 +179,9 @@ var _ = Describe("PostManager Tests", func() {
 					status: http.StatusAccepted,
 					body:   `{"id": "100", "code": 400}`,
 				}}, http.MethodPost)
-			mockPM.publishConfig(agentCfg)
-			Expect(mockPM.tenantResponseMap[tnt].agentResponseCode).To(BeEquivalentTo(http.StatusOK), "Posting Failed")
-			mockPM.publishConfig(agentCfg)
+			mockPM.publishConfig(&agentCfg)
+			Expect(agentCfg.tenantResponseMap[tnt].agentResponseCode).To(BeEquivalentTo(http.StatusOK), "Posting Failed")
+			mockPM.publishConfig(&agentCfg)
 		})
 
 		It("Handle Expected HTTP Response Errors", func() {
@@ -89,10 +198,10 @@ var _ = Describe("PostManager Tests", func() {
 					body:   "",
 				},
 			}, http.MethodPost)
-			mockPM.publishConfig(agentCfg)
-			Expect(len(mockPM.tenantResponseMap)).To(BeZero(), "Posting Failed")
-			mockPM.publishConfig(agentCfg)
-			Expect(len(mockPM.tenantResponseMap)).To(BeZero(), "Posting Failed")
+			mockPM.publishConfig(&agentCfg)
+			Expect(len(agentCfg.tenantResponseMap)).To(BeZero(), "Posting Failed")
+			mockPM.publishConfig(&agentCfg)
+			Expect(len(agentCfg.tenantResponseMap)).To(BeZero(), "Posting Failed")
 		})
 
 		It("Handle Unexpected HTTP Response Errors", func() {
@@ -115,17 +224,17 @@ var _ = Describe("PostManager Tests", func() {
 				},
 			}, http.MethodPost)
 
-			mockPM.publishConfig(agentCfg)
-			Expect(len(mockPM.tenantResponseMap)).To(Equal(1), "Posting Failed")
-			Expect(mockPM.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusRequestTimeout))
+			mockPM.publishConfig(&agentCfg)
+			Expect(len(agentCfg.tenantResponseMap)).To(Equal(1), "Posting Failed")
+			Expect(agentCfg.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusRequestTimeout))
 
-			mockPM.publishConfig(agentCfg)
-			Expect(len(mockPM.tenantResponseMap)).To(Equal(1), "Posting Failed")
-			Expect(mockPM.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusRequestTimeout))
+			mockPM.publishConfig(&agentCfg)
+			Expect(len(agentCfg.tenantResponseMap)).To(Equal(1), "Posting Failed")
+			Expect(agentCfg.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusRequestTimeout))
 
-			mockPM.publishConfig(agentCfg)
-			Expect(len(mockPM.tenantResponseMap)).To(Equal(1), "Posting Failed")
-			Expect(mockPM.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusAlreadyReported))
+			mockPM.publishConfig(&agentCfg)
+			Expect(len(agentCfg.tenantResponseMap)).To(Equal(1), "Posting Failed")
+			Expect(agentCfg.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusAlreadyReported))
 		})
 
 		It("Handle Multiple HTTP Responses", func() {
@@ -136,14 +245,15 @@ var _ = Describe("PostManager Tests", func() {
 				body:   "",
 			},
 			}, http.MethodPost)
-			mockPM.publishConfig(agentCfg)
-			Expect(len(mockPM.tenantResponseMap)).To(Equal(1), "Posting Failed")
+			mockPM.publishConfig(&agentCfg)
+			Expect(len(agentCfg.tenantResponseMap)).To(Equal(1), "Posting Failed")
 		})
 	})
 
 	Describe("BIGIP Queries", func() {
 		It("Get Tenant Configuration Status", func() {
 			tnt := "test"
+			responses := make(tenantResponses)
 			mockPM.setResponses([]responceCtx{
 				{
 					tenant: tnt,
@@ -161,14 +271,14 @@ var _ = Describe("PostManager Tests", func() {
 					body:   fmt.Sprintf(`{"results":[{"code":%d,"message":"none", "tenant": "%s"}]}`, http.StatusUnprocessableEntity, tnt),
 				},
 			}, http.MethodGet)
-			mockPM.getTenantConfigStatus("100")
-			Expect(len(mockPM.tenantResponseMap)).To(BeZero(), "Posting Failed")
-			mockPM.getTenantConfigStatus("100")
-			Expect(len(mockPM.tenantResponseMap)).To(Equal(1), "Posting Failed")
-			Expect(mockPM.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusOK))
-			mockPM.getTenantConfigStatus("100")
-			Expect(len(mockPM.tenantResponseMap)).To(Equal(1), "Posting Failed")
-			Expect(mockPM.tenantResponseMap[tnt].agentResponseCode).To(Equal(http.StatusUnprocessableEntity))
+			mockPM.getTenantConfigStatus("100", responses)
+			Expect(len(responses)).To(BeZero(), "Posting Failed")
+			mockPM.getTenantConfigStatus("100", responses)
+			Expect(len(responses)).To(Equal(1), "Posting Failed")
+			Expect(responses[tnt].agentResponseCode).To(Equal(http.StatusOK))
+			mockPM.getTenantConfigStatus("100", responses)
+			Expect(len(responses)).To(Equal(1), "Posting Failed")
+			Expect(responses[tnt].agentResponseCode).To(Equal(http.StatusUnprocessableEntity))
 		})
 	})
 
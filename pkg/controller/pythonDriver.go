@@ -176,8 +176,6 @@ func (agent *Agent) startPythonDriver(
 
 	subPid := <-subPidCh
 	agent.PythonDriverPID = subPid
-	//Enable "/health" and "/metrics" endpoint with controller
-	go agent.healthCheckPythonDriver()
 
 	return
 }
@@ -196,14 +194,20 @@ func (agent *Agent) stopPythonDriver() {
 	}
 }
 
-func (agent *Agent) healthCheckPythonDriver() {
+// healthCheck serves the "/health", "/ready" and "/metrics" endpoints, pythonDriver is unset when
+// the agent runs without the python driver
+func (agent *Agent) healthCheck(pythonDriver bool) {
 	// Expose Prometheus metrics
 	http.Handle("/metrics", promhttp.Handler())
-	// Add health check to track whether Python process still alive
+	// Add health check to track whether Python process still alive,
+	// readiness additionally reports whether requests to the BIG-IPs are paused
 	hc := &health.HealthChecker{
-		SubPID: agent.PythonDriverPID,
+		SubPID:       agent.PythonDriverPID,
+		NoSubProcess: !pythonDriver,
+		BigIPCheck:   agent.checkBigIPs,
 	}
 	http.Handle("/health", hc.HealthCheckHandler())
+	http.Handle("/ready", hc.ReadinessHandler())
 	bigIPPrometheus.RegisterMetrics()
 	log.Fatal(http.ListenAndServe(agent.HttpAddress, nil).Error())
 }
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/resource"
 
+	"hash/fnv"
 	"net"
 	"reflect"
 	"sort"
@@ -89,9 +90,19 @@ const (
 	// Constants
 	HttpRedirectNoHostIRuleName = "http_redirect_irule_nohost"
 	// Internal data group for https redirect
-	HttpsRedirectDgName = "https_redirect_dg"
-	TLSIRuleName        = "tls_irule"
-	ABPathIRuleName     = "ab_deployment_path_irule"
+	HttpsRedirectDgName     = "https_redirect_dg"
+	TLSIRuleName            = "tls_irule"
+	ABPathIRuleName         = "ab_deployment_path_irule"
+	DirectResponseIRuleName = "direct_response_irule"
+	RegexPathIRuleName      = "regex_path_irule"
+	RegexMatchIRuleName     = "regex_match_irule"
+	// HTTP/2 profile of the virtuals with gRPC pools
+	GRPCHTTP2ProfileName = "grpc_http2_profile"
+	// BIG-IP WebSocket profile of the VirtualServers with websocket
+	DefaultWebSocketProfile = "/Common/websocket"
+	// TCP and HTTP profiles generated from the tcpSettings and httpSettings of the virtuals
+	TCPProfileName  = "tcp_profile"
+	HTTPProfileName = "http_profile"
 )
 
 // constants for TLS references
@@ -290,6 +301,174 @@ func (ctlr *Controller) framePoolName(ns string, pool cisapiv1.Pool, host string
 	return poolName
 }
 
+// isServicelessPool checks that the requests to the path of the pool are answered by BIG-IP
+// with a redirect or a direct response
+func isServicelessPool(pool cisapiv1.Pool) bool {
+	return pool.Redirect != nil || pool.DirectResponse != nil
+}
+
+// getAlternateBackendPools returns the pools of the alternateBackends of a VirtualServer pool,
+// which share the path, port and monitors of the pool
+func getAlternateBackendPools(pool cisapiv1.Pool) []cisapiv1.Pool {
+	var altPools []cisapiv1.Pool
+	for _, altBackend := range pool.AlternateBackends {
+		altPool := pool
+		altPool.Name = ""
+		altPool.Service = altBackend.Service
+		if altBackend.ServiceNamespace != "" {
+			altPool.ServiceNamespace = altBackend.ServiceNamespace
+		}
+		altPool.Weight = altBackend.Weight
+		altPool.AlternateBackends = nil
+		altPools = append(altPools, altPool)
+	}
+	return altPools
+}
+
+// getVirtualServerHosts returns the host and hosts of a VirtualServer, which is a single empty host
+// for a VirtualServer without host
+func getVirtualServerHosts(vs *cisapiv1.VirtualServer) []string {
+	if len(vs.Spec.Hosts) == 0 {
+		return []string{vs.Spec.Host}
+	}
+	var hosts []string
+	uniqueHosts := make(map[string]struct{})
+	for _, host := range append([]string{vs.Spec.Host}, vs.Spec.Hosts...) {
+		if _, ok := uniqueHosts[host]; ok || host == "" {
+			continue
+		}
+		uniqueHosts[host] = struct{}{}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// getVirtualServersForHosts returns a copy of the VirtualServer for each of its hosts,
+// so that a VirtualServer with multiple hosts is processed like a group of VirtualServers
+func getVirtualServersForHosts(virtuals []*cisapiv1.VirtualServer) []*cisapiv1.VirtualServer {
+	var hostVirtuals []*cisapiv1.VirtualServer
+	for _, vs := range virtuals {
+		if len(vs.Spec.Hosts) == 0 {
+			hostVirtuals = append(hostVirtuals, vs)
+			continue
+		}
+		for _, host := range getVirtualServerHosts(vs) {
+			hostVS := vs.DeepCopy()
+			hostVS.Spec.Host = host
+			hostVS.Spec.Hosts = nil
+			hostVirtuals = append(hostVirtuals, hostVS)
+		}
+	}
+	return hostVirtuals
+}
+
+// hasGRPCPool checks that a VirtualServer has pools with the gRPC protocol
+func hasGRPCPool(vs *cisapiv1.VirtualServer) bool {
+	for _, pl := range vs.Spec.Pools {
+		if pl.Protocol == PoolProtocolGRPC {
+			return true
+		}
+	}
+	return false
+}
+
+// getPoolMonitorType returns the type of a pool monitor, http and https monitors of gRPC pools
+// are sent over HTTP/2 as gRPC backends do not serve HTTP/1.1
+func getPoolMonitorType(pool cisapiv1.Pool, monitorType string) string {
+	if pool.Protocol == PoolProtocolGRPC && (monitorType == HTTP || monitorType == HTTPS) {
+		return "http2"
+	}
+	return monitorType
+}
+
+// getPoolMonitorSend returns the send and receive strings of a pool monitor. grpc monitors call the
+// grpc.health.v1.Health/Check method with an empty request, which checks the health of the whole server,
+// and expect the OK status in the grpc-status trailer
+func getPoolMonitorSend(host string, monitor cisapiv1.Monitor) (string, string) {
+	if monitor.Type != GRPCMonitor {
+		return monitor.Send, monitor.Recv
+	}
+	send := "POST /grpc.health.v1.Health/Check HTTP/2.0\r\n"
+	if host != "" {
+		send += "Host: " + host + "\r\n"
+	}
+	// The request message is a gRPC frame of an empty HealthCheckRequest
+	send += "Content-Type: application/grpc\r\nTE: trailers\r\nContent-Length: 5\r\n\r\n\\x00\\x00\\x00\\x00\\x00"
+	return send, "grpc-status: 0"
+}
+
+// enableGRPC attaches an HTTP/2 profile to the virtual, unless one is set by a Policy, with the
+// HTTP message routing framework, and preserves the gRPC trailers in the HTTP profile of the virtual
+func (rsCfg *ResourceConfig) enableGRPC() {
+	hasHTTP2Profile := false
+	for _, prof := range rsCfg.Virtual.Profiles {
+		if prof.Context == "http2" {
+			hasHTTP2Profile = true
+		}
+	}
+	rsCfg.Virtual.GRPC = true
+	rsCfg.Virtual.HttpMrfRoutingEnabled = true
+	if !hasHTTP2Profile {
+		rsCfg.Virtual.AddOrUpdateProfile(ProfileRef{
+			Name:      getRSCfgResName(rsCfg.Virtual.Name, GRPCHTTP2ProfileName),
+			Partition: rsCfg.Virtual.Partition,
+			Context:   "http2",
+		})
+	}
+}
+
+// getTCPSettings returns a copy of the tcpSettings of a Policy or a VirtualServer
+func getTCPSettings(settings *cisapiv1.TCPSettings) *ProfileTCPSettings {
+	if settings == nil {
+		return nil
+	}
+	return &ProfileTCPSettings{
+		IdleTimeout:       settings.IdleTimeout,
+		Nagle:             settings.Nagle,
+		KeepAliveInterval: settings.KeepAliveInterval,
+	}
+}
+
+// getHTTPSettings returns a copy of the httpSettings of a Policy or a VirtualServer
+func getHTTPSettings(settings *cisapiv1.HTTPSettings) *ProfileHTTPSettings {
+	if settings == nil {
+		return nil
+	}
+	httpSettings := &ProfileHTTPSettings{
+		ServerHeader:  settings.ServerHeader,
+		MaxHeaderSize: settings.MaxHeaderSize,
+		ProxyType:     settings.ProxyType,
+	}
+	if settings.XForwardedFor != nil {
+		xForwardedFor := *settings.XForwardedFor
+		httpSettings.XForwardedFor = &xForwardedFor
+	}
+	return httpSettings
+}
+
+// getDefaultPool returns the defaultPool of a VirtualServer as a pool without path
+func getDefaultPool(defaultPool *cisapiv1.DefaultPool) cisapiv1.Pool {
+	return cisapiv1.Pool{
+		Service:           defaultPool.Service,
+		ServicePort:       defaultPool.ServicePort,
+		ServiceNamespace:  defaultPool.ServiceNamespace,
+		NodeMemberLabel:   defaultPool.NodeMemberLabel,
+		Monitor:           defaultPool.Monitor,
+		Monitors:          defaultPool.Monitors,
+		Balance:           defaultPool.Balance,
+		ReselectTries:     defaultPool.ReselectTries,
+		ServiceDownAction: defaultPool.ServiceDownAction,
+	}
+}
+
+// getPoolWeight returns the weight of a pool with alternateBackends, 100 if not specified
+func getPoolWeight(weight *int32) int {
+	if weight == nil {
+		return 100
+	}
+	return int(*weight)
+}
+
 // format the pool name for an VirtualServer
 func formatPoolName(namespace, svc string, port intstr.IntOrString, nodeMemberLabel string, host string) string {
 	servicePort := fetchPortString(port)
@@ -326,6 +505,17 @@ func formatMonitorName(namespace, svc string, monitorType string, port intstr.In
 	return AS3NameFormatter(monitorName)
 }
 
+// getMonitorPath returns the path of the pool used in the monitor name. Regex paths are replaced by their
+// hash as their special characters are not allowed in BIG-IP names
+func getMonitorPath(pl cisapiv1.Pool) string {
+	if pl.PathType != PathTypeRegex {
+		return pl.Path
+	}
+	h := fnv.New32a()
+	h.Write([]byte(pl.Path))
+	return fmt.Sprintf("regex_%08x", h.Sum32())
+}
+
 // format the policy name for VirtualServer
 func formatPolicyName(hostname, hostGroup, name string) string {
 	host := hostname
@@ -410,7 +600,12 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 	rsCfg *ResourceConfig,
 	vs *cisapiv1.VirtualServer,
 	passthroughVS bool,
-) error {
+) (err error) {
+	_, span := startSpan(ctlr.processSpanCtx, "prepareRSConfigFromVirtualServer",
+		attrResourceKey.String(vs.Namespace+"/"+VirtualServer+"/"+vs.Name),
+		attrTenant.String(rsCfg.Virtual.Partition),
+	)
+	defer func() { endSpan(span, err) }()
 
 	var httpPort int32
 	httpPort = DEFAULT_HTTP_PORT
@@ -420,8 +615,22 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 	var rules *Rules
 	var monitors []Monitor
 
-	framedPools := make(map[string]struct{})
+	var vsPools []cisapiv1.Pool
 	for _, pl := range vs.Spec.Pools {
+		vsPools = append(vsPools, pl)
+		vsPools = append(vsPools, getAlternateBackendPools(pl)...)
+	}
+	// The copies of a VirtualServer with hosts use the default pool built for its first host
+	vsKey := vs.Namespace + "/" + vs.Name
+	if vs.Spec.DefaultPool != nil && rsCfg.MetaData.defaultPoolVS != vsKey {
+		vsPools = append(vsPools, getDefaultPool(vs.Spec.DefaultPool))
+	}
+
+	framedPools := make(map[string]struct{})
+	for _, pl := range vsPools {
+		if isServicelessPool(pl) {
+			continue
+		}
 
 		poolName := ctlr.framePoolName(vs.Namespace, pl, vs.Spec.Host)
 		//check for custom monitor
@@ -459,18 +668,20 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 		}
 		if pl.Monitor.Name != "" && pl.Monitor.Reference == "bigip" {
 			pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: pl.Monitor.Name, Reference: pl.Monitor.Reference})
-		} else if pl.Monitor.Send != "" && pl.Monitor.Type != "" {
+		} else if pl.Monitor.Type != "" && (pl.Monitor.Send != "" || pl.Monitor.Type == GRPCMonitor) {
+			monitorType := getPoolMonitorType(pl, pl.Monitor.Type)
+			send, recv := getPoolMonitorSend(vs.Spec.Host, pl.Monitor)
 			if pl.Name == "" {
-				monitorName = formatMonitorName(vs.ObjectMeta.Namespace, pl.Service, pl.Monitor.Type, pl.ServicePort, vs.Spec.Host, pl.Path)
+				monitorName = formatMonitorName(vs.ObjectMeta.Namespace, pl.Service, monitorType, pl.ServicePort, vs.Spec.Host, getMonitorPath(pl))
 			}
 			pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: JoinBigipPath(rsCfg.Virtual.Partition, monitorName)})
 			monitor := Monitor{
 				Name:       monitorName,
 				Partition:  rsCfg.Virtual.Partition,
-				Type:       pl.Monitor.Type,
+				Type:       monitorType,
 				Interval:   pl.Monitor.Interval,
-				Send:       pl.Monitor.Send,
-				Recv:       pl.Monitor.Recv,
+				Send:       send,
+				Recv:       recv,
 				Timeout:    pl.Monitor.Timeout,
 				TargetPort: pl.Monitor.TargetPort,
 			}
@@ -486,17 +697,19 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 					} else {
 						formatPort = pl.ServicePort
 					}
+					monitorType := getPoolMonitorType(pl, monitor.Type)
+					send, recv := getPoolMonitorSend(vs.Spec.Host, monitor)
 					if monitor.Name == "" {
-						monitorName = formatMonitorName(vs.ObjectMeta.Namespace, pl.Service, monitor.Type, formatPort, vs.Spec.Host, pl.Path)
+						monitorName = formatMonitorName(vs.ObjectMeta.Namespace, pl.Service, monitorType, formatPort, vs.Spec.Host, getMonitorPath(pl))
 					}
 					pool.MonitorNames = append(pool.MonitorNames, MonitorName{Name: JoinBigipPath(rsCfg.Virtual.Partition, monitorName)})
 					monitor := Monitor{
 						Name:       monitorName,
 						Partition:  rsCfg.Virtual.Partition,
-						Type:       monitor.Type,
+						Type:       monitorType,
 						Interval:   monitor.Interval,
-						Send:       monitor.Send,
-						Recv:       monitor.Recv,
+						Send:       send,
+						Recv:       recv,
 						Timeout:    monitor.Timeout,
 						TargetPort: monitor.TargetPort,
 					}
@@ -542,6 +755,14 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 		rsCfg.Virtual.TCP.Server = vs.Spec.Profiles.TCP.Server
 	}
 
+	if vs.Spec.Profiles.TCPSettings != nil {
+		rsCfg.Virtual.TCPSettings = getTCPSettings(vs.Spec.Profiles.TCPSettings)
+	}
+
+	if vs.Spec.Profiles.HTTPSettings != nil {
+		rsCfg.Virtual.HTTPSettings = getHTTPSettings(vs.Spec.Profiles.HTTPSettings)
+	}
+
 	if vs.Spec.DOS != "" {
 		rsCfg.Virtual.ProfileDOS = vs.Spec.DOS
 	}
@@ -558,6 +779,51 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 		rsCfg.Virtual.ProfileMultiplex = vs.Spec.ProfileMultiplex
 	}
 
+	if vs.Spec.WebSocket != nil {
+		rsCfg.Virtual.ProfileWebSocket = DefaultWebSocketProfile
+		if vs.Spec.WebSocket.Profile != "" {
+			rsCfg.Virtual.ProfileWebSocket = vs.Spec.WebSocket.Profile
+		}
+	}
+
+	if vs.Spec.TCPIdleTimeout > 0 {
+		if rsCfg.Virtual.TCPSettings == nil {
+			rsCfg.Virtual.TCPSettings = &ProfileTCPSettings{}
+		}
+		rsCfg.Virtual.TCPSettings.IdleTimeout = vs.Spec.TCPIdleTimeout
+	}
+
+	// The WebSocket profile is attached to the HTTP profile generated by CIS, which is not created for a virtual
+	// with the BIG-IP HTTP profile of a Policy
+	if httpProfile := rsCfg.Virtual.getBigIPHTTPProfile(); httpProfile != "" && rsCfg.Virtual.ProfileWebSocket != "" {
+		err = fmt.Errorf("websocket of VirtualServer %s/%s cannot be used with the HTTP profile %s of its Policy, "+
+			"attach the WebSocket profile to that HTTP profile instead", vs.Namespace, vs.Name, httpProfile)
+		log.Errorf("%v", err)
+		ctlr.recordVirtualServerWarning(vs, "HTTPProfileConflict", err.Error())
+		return err
+	}
+	// The httpSettings generate an HTTP profile, which would replace the BIG-IP HTTP profile of the Policy
+	if httpProfile := rsCfg.Virtual.getBigIPHTTPProfile(); httpProfile != "" && rsCfg.Virtual.HTTPSettings != nil {
+		err = fmt.Errorf("httpSettings of VirtualServer %s/%s or its Policy cannot be used with the HTTP profile %s "+
+			"of the Policy, configure the settings in that HTTP profile instead", vs.Namespace, vs.Name, httpProfile)
+		log.Errorf("%v", err)
+		ctlr.recordVirtualServerWarning(vs, "HTTPProfileConflict", err.Error())
+		return err
+	}
+
+	// gRPC needs HTTP/2, which is negotiated with ALPN over TLS
+	if hasGRPCPool(vs) {
+		if !isTLSVirtualServer(vs) || passthroughVS {
+			err = fmt.Errorf("gRPC pools of VirtualServer %s/%s require a TLSProfile with edge or reencrypt termination",
+				vs.Namespace, vs.Name)
+			log.Errorf("%v", err)
+			return err
+		}
+		if rsCfg.MetaData.Protocol == HTTPS {
+			rsCfg.enableGRPC()
+		}
+	}
+
 	// Do not Create Virtual Server L7 Forwarding policies if HTTPTraffic is set to None or Redirect
 	if len(vs.Spec.TLSProfileName) > 0 &&
 		rsCfg.Virtual.VirtualAddress.Port == httpPort &&
@@ -565,6 +831,18 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 		return nil
 	}
 
+	// The default pool serves the requests for which no rule selects a pool
+	if vs.Spec.DefaultPool != nil && rsCfg.MetaData.defaultPoolVS != vsKey {
+		defaultPoolName := ctlr.framePoolName(vs.Namespace, getDefaultPool(vs.Spec.DefaultPool), vs.Spec.Host)
+		if rsCfg.Virtual.PoolName != "" && rsCfg.Virtual.PoolName != defaultPoolName {
+			log.Warningf("Virtual %v already has the default pool %v, ignoring the defaultPool of VirtualServer %v",
+				rsCfg.Virtual.Name, rsCfg.Virtual.PoolName, vsKey)
+		} else {
+			rsCfg.Virtual.PoolName = defaultPoolName
+			rsCfg.MetaData.defaultPoolVS = vsKey
+		}
+	}
+
 	// skip the policy creation for passthrough termination
 	if !passthroughVS {
 		rules = ctlr.prepareVirtualServerRules(vs, rsCfg)
@@ -575,6 +853,53 @@ func (ctlr *Controller) prepareRSConfigFromVirtualServer(
 		policyName := formatPolicyName(vs.Spec.Host, vs.Spec.HostGroup, rsCfg.Virtual.Name)
 
 		rsCfg.AddRuleToPolicy(policyName, vs.Namespace, rules)
+
+		// The A/B iRule replaces the pool of a pool with alternateBackends selected by the policy
+		// with a pool chosen by weight
+		if ctlr.updateDataGroupForABVirtualServer(vs,
+			getRSCfgResName(rsCfg.Virtual.Name, AbDeploymentDgName),
+			rsCfg.Virtual.Partition,
+			rsCfg.IntDgMap,
+		) {
+			rsCfg.addIRule(
+				getRSCfgResName(rsCfg.Virtual.Name, ABPathIRuleName), rsCfg.Virtual.Partition, ctlr.GetVirtualServerABDeployIRule(rsCfg.Virtual.Name, rsCfg.Virtual.Partition))
+			rsCfg.Virtual.AddIRule(JoinBigipPath(rsCfg.Virtual.Partition,
+				getRSCfgResName(rsCfg.Virtual.Name, ABPathIRuleName)))
+		}
+
+		if ctlr.updateDataGroupForRegexPaths(vs,
+			getRSCfgResName(rsCfg.Virtual.Name, RegexPathDgName),
+			rsCfg.Virtual.Partition,
+			rsCfg.IntDgMap,
+		) {
+			rsCfg.addIRule(
+				getRSCfgResName(rsCfg.Virtual.Name, RegexPathIRuleName), rsCfg.Virtual.Partition, ctlr.GetRegexPathIRule(rsCfg.Virtual.Name, rsCfg.Virtual.Partition))
+			rsCfg.Virtual.AddIRule(JoinBigipPath(rsCfg.Virtual.Partition,
+				getRSCfgResName(rsCfg.Virtual.Name, RegexPathIRuleName)))
+		}
+
+		if ctlr.updateDataGroupForRegexMatches(vs,
+			getRSCfgResName(rsCfg.Virtual.Name, RegexMatchDgName),
+			rsCfg.Virtual.Partition,
+			rsCfg.Virtual.AllowSourceRange,
+			rsCfg.IntDgMap,
+		) {
+			rsCfg.addIRule(
+				getRSCfgResName(rsCfg.Virtual.Name, RegexMatchIRuleName), rsCfg.Virtual.Partition, ctlr.GetRegexMatchIRule(rsCfg.Virtual.Name, rsCfg.Virtual.Partition))
+			rsCfg.Virtual.AddIRule(JoinBigipPath(rsCfg.Virtual.Partition,
+				getRSCfgResName(rsCfg.Virtual.Name, RegexMatchIRuleName)))
+		}
+
+		if ctlr.updateDataGroupForDirectResponse(vs,
+			getRSCfgResName(rsCfg.Virtual.Name, DirectResponseDgName),
+			rsCfg.Virtual.Partition,
+			rsCfg.IntDgMap,
+		) {
+			rsCfg.addIRule(
+				getRSCfgResName(rsCfg.Virtual.Name, DirectResponseIRuleName), rsCfg.Virtual.Partition, ctlr.GetDirectResponseIRule(rsCfg.Virtual.Name, rsCfg.Virtual.Partition))
+			rsCfg.Virtual.AddIRule(JoinBigipPath(rsCfg.Virtual.Partition,
+				getRSCfgResName(rsCfg.Virtual.Name, DirectResponseIRuleName)))
+		}
 	}
 
 	// Attach user specified iRules
@@ -898,7 +1223,12 @@ func (ctlr *Controller) handleVirtualServerTLS(
 		bigIPSSLProfiles.serverSSLs = append(bigIPSSLProfiles.serverSSLs, tls.Spec.TLS.ServerSSL)
 	}
 	var poolPathRefs []poolPathRef
+	var regexPoolPathRef *poolPathRef
+	rootPath := false
 	for _, pl := range vs.Spec.Pools {
+		if isServicelessPool(pl) {
+			continue
+		}
 
 		poolName := ctlr.framePoolName(
 			vs.ObjectMeta.Namespace,
@@ -906,8 +1236,23 @@ func (ctlr *Controller) handleVirtualServerTLS(
 			vs.Spec.Host,
 		)
 
+		// The TLS iRule matches the literal paths of the requests, the regex paths are selected by the regex
+		// path iRule. The pool of the first regex path is used for the host when no pool has the root path
+		// so that the server side TLS is enabled for the requests to the regex paths
+		if pl.PathType == PathTypeRegex {
+			if regexPoolPathRef == nil {
+				regexPoolPathRef = &poolPathRef{"/", poolName, tls.Spec.Hosts}
+			}
+			continue
+		}
+		if pl.Path == "" || pl.Path == "/" {
+			rootPath = true
+		}
 		poolPathRefs = append(poolPathRefs, poolPathRef{pl.Path, poolName, tls.Spec.Hosts})
 	}
+	if regexPoolPathRef != nil && !rootPath {
+		poolPathRefs = append(poolPathRefs, *regexPoolPathRef)
+	}
 	return ctlr.handleTLS(rsCfg, TLSContext{vs.ObjectMeta.Name,
 		vs.ObjectMeta.Namespace,
 		VirtualServer,
@@ -1006,6 +1351,16 @@ func (v *Virtual) AddOrUpdateProfile(prof ProfileRef) bool {
 	return true
 }
 
+// getBigIPHTTPProfile returns the BIG-IP HTTP profile of the virtual set by a Policy
+func (v *Virtual) getBigIPHTTPProfile() string {
+	for _, prof := range v.Profiles {
+		if prof.Context == "http" && prof.BigIPProfile {
+			return prof.Name
+		}
+	}
+	return ""
+}
+
 // SetVirtualAddress sets a VirtualAddress
 func (v *Virtual) SetVirtualAddress(bindAddr string, port int32) {
 	v.Destination = ""
@@ -1447,6 +1802,18 @@ const AllowSourceRangeDgName = "allowSourceRange"
 // Internal data group for ab deployment routes.
 const AbDeploymentDgName = "ab_deployment_dg"
 
+// Internal data group for the direct responses of VirtualServers.
+const DirectResponseDgName = "direct_response_dg"
+
+// DirectResponseVariable is the Tcl variable set by the policy rules of the pools with a direct response
+const DirectResponseVariable = "cis_direct_response"
+
+// Internal data group for the regex paths of VirtualServers.
+const RegexPathDgName = "regex_path_dg"
+
+// Internal data group for the pools with regex match conditions of VirtualServers.
+const RegexMatchDgName = "regex_match_dg"
+
 func (slice InternalDataGroupRecords) Less(i, j int) bool {
 	return slice[i].Name < slice[j].Name
 }
@@ -1693,6 +2060,10 @@ func (ctlr *Controller) prepareRSConfigFromTransportServer(
 		rsCfg.Virtual.TCP.Server = vs.Spec.Profiles.TCP.Server
 	}
 
+	if vs.Spec.Profiles.TCPSettings != nil {
+		rsCfg.Virtual.TCPSettings = getTCPSettings(vs.Spec.Profiles.TCPSettings)
+	}
+
 	if len(rsCfg.ServiceAddress) == 0 {
 		for _, sa := range vs.Spec.ServiceIPAddress {
 			rsCfg.ServiceAddress = append(rsCfg.ServiceAddress, ServiceAddress(sa))
@@ -1791,6 +2162,8 @@ func (ctlr *Controller) handleVSResourceConfigForPolicy(
 	rsCfg.Virtual.ProfileBotDefense = plc.Spec.L3Policies.BotDefense
 	rsCfg.Virtual.TCP.Client = plc.Spec.Profiles.TCP.Client
 	rsCfg.Virtual.TCP.Server = plc.Spec.Profiles.TCP.Server
+	rsCfg.Virtual.TCPSettings = getTCPSettings(plc.Spec.Profiles.TCPSettings)
+	rsCfg.Virtual.HTTPSettings = getHTTPSettings(plc.Spec.Profiles.HTTPSettings)
 	rsCfg.Virtual.AllowSourceRange = plc.Spec.L3Policies.AllowSourceRange
 	rsCfg.Virtual.AllowVLANs = plc.Spec.L3Policies.AllowVlans
 	rsCfg.Virtual.IpIntelligencePolicy = plc.Spec.L3Policies.IpIntelligencePolicy
@@ -1853,6 +2226,7 @@ func (ctlr *Controller) handleTSResourceConfigForPolicy(
 	rsCfg.Virtual.ProfileBotDefense = plc.Spec.L3Policies.BotDefense
 	rsCfg.Virtual.TCP.Client = plc.Spec.Profiles.TCP.Client
 	rsCfg.Virtual.TCP.Server = plc.Spec.Profiles.TCP.Server
+	rsCfg.Virtual.TCPSettings = getTCPSettings(plc.Spec.Profiles.TCPSettings)
 	rsCfg.Virtual.AllowVLANs = plc.Spec.L3Policies.AllowVlans
 	rsCfg.Virtual.IpIntelligencePolicy = plc.Spec.L3Policies.IpIntelligencePolicy
 
@@ -1,8 +1,11 @@
 package controller
 
 import (
+	"context"
+	"encoding/base64"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sort"
+	"strings"
 
 	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v2/config/apis/cis/v1"
 	crdfake "github.com/F5Networks/k8s-bigip-ctlr/v2/config/client/clientset/versioned/fake"
@@ -10,6 +13,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 )
 
@@ -319,6 +323,642 @@ var _ = Describe("Resource Config Tests", func() {
 
 		})
 
+		It("Prepare Resource Config from a VirtualServer with alternateBackends", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			weight := int32(80)
+			canaryWeight := int32(20)
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.Pool{
+						{
+							Path:        "/foo",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: 80},
+							Weight:      &weight,
+							AlternateBackends: []cisapiv1.AlternateBackend{
+								{Service: "svc1-canary", Weight: &canaryWeight},
+							},
+						},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Pools).To(HaveLen(2), "Pool of alternate backend not created")
+			Expect(rsCfg.Pools[1].ServiceName).To(Equal("svc1-canary"))
+			Expect(rsCfg.Policies[0].Rules).To(HaveLen(1), "Policy rule created for alternate backend")
+
+			dgName := getRSCfgResName(rsCfg.Virtual.Name, AbDeploymentDgName)
+			dg := rsCfg.IntDgMap[NameRef{Name: dgName, Partition: "test"}][namespace]
+			Expect(dg).NotTo(BeNil(), "A/B data group not created")
+			Expect(dg.Records).To(HaveLen(1))
+			Expect(dg.Records[0].Name).To(Equal(rsCfg.Pools[0].Name+"|test.com/foo"),
+				"A/B data group not keyed by the pool selected by the policy")
+			Expect(dg.Records[0].Data).To(Equal(rsCfg.Pools[0].Name + ",0.800;" + rsCfg.Pools[1].Name + ",1.000"))
+			Expect(rsCfg.Virtual.IRules).To(ContainElement(
+				JoinBigipPath("test", getRSCfgResName(rsCfg.Virtual.Name, ABPathIRuleName))))
+			abIRule := rsCfg.IRulesMap[NameRef{Name: getRSCfgResName(rsCfg.Virtual.Name, ABPathIRuleName), Partition: "test"}]
+			Expect(abIRule).NotTo(BeNil())
+			Expect(abIRule.Code).To(ContainSubstring("LB::server pool"), "A/B iRule overrides the pools selected by other rules")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with redirects and direct responses", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "Test.com",
+					Pools: []cisapiv1.Pool{
+						{
+							Path:    "/",
+							Service: "svc1",
+						},
+						{
+							Path: "/legacy",
+							Redirect: &cisapiv1.PoolRedirect{
+								URL:           "https://new.test.com/",
+								Code:          308,
+								PreservePath:  true,
+								PreserveQuery: true,
+							},
+						},
+						{
+							Path: "/maintenance",
+							DirectResponse: &cisapiv1.DirectResponse{
+								Status: 503,
+								Body:   "Under maintenance",
+							},
+						},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Pools).To(HaveLen(1), "Pool created without service")
+			rules := rsCfg.Policies[0].Rules
+			Expect(rules).To(HaveLen(3))
+			// The rules of the redirect and of the direct response have the same precedence
+			if rules[0].Actions[0].SetVariable {
+				rules[0], rules[1] = rules[1], rules[0]
+			}
+			Expect(rules[0].Actions).To(Equal([]*action{{
+				Name:      "0",
+				HttpReply: true,
+				Location:  "https://new.test.com[HTTP::uri]",
+				Code:      308,
+				Redirect:  true,
+				Request:   true,
+			}}))
+			drKey := namespace + "/SampleVS/2"
+			Expect(rules[1].Actions).To(Equal([]*action{{
+				Name:         "0",
+				SetVariable:  true,
+				VariableName: DirectResponseVariable,
+				Value:        drKey,
+				Request:      true,
+			}}), "Direct response not selected by the policy rule of the pool")
+
+			dgName := getRSCfgResName(rsCfg.Virtual.Name, DirectResponseDgName)
+			dg := rsCfg.IntDgMap[NameRef{Name: dgName, Partition: "test"}][namespace]
+			Expect(dg).NotTo(BeNil(), "Direct response data group not created")
+			Expect(dg.Records).To(Equal(InternalDataGroupRecords{
+				{Name: drKey, Data: "503 dGV4dC9wbGFpbg== VW5kZXIgbWFpbnRlbmFuY2U="},
+			}))
+			Expect(rsCfg.Virtual.IRules).To(ContainElement(
+				JoinBigipPath("test", getRSCfgResName(rsCfg.Virtual.Name, DirectResponseIRuleName))))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with regex match conditions", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.Pool{
+						{
+							Path:    "/foo",
+							Service: "svc1",
+							Match: &cisapiv1.PoolMatch{
+								Headers: []cisapiv1.HTTPMatch{
+									{Name: "User-Agent", Operand: MatchOperandRegex, Values: []string{"(android|iphone) [0-9]+"}},
+								},
+								Cookies: []cisapiv1.HTTPMatch{
+									{Name: "tester", Values: []string{"True"}},
+								},
+								Methods: []string{"get"},
+							},
+						},
+						{
+							Path:    "/foo",
+							Service: "svc2",
+						},
+						{
+							Path:    "/bar",
+							Service: "svc3",
+							Match: &cisapiv1.PoolMatch{
+								QueryParameters: []cisapiv1.HTTPMatch{
+									{Name: "id", Operand: MatchOperandRegex, Values: []string{`^\bv1$`}},
+								},
+							},
+						},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			rules := rsCfg.Policies[0].Rules
+			Expect(rules).To(HaveLen(1), "Policy rule created for regex match")
+			Expect(rules[0].Actions[0].Pool).To(ContainSubstring("svc2"))
+
+			dgName := getRSCfgResName(rsCfg.Virtual.Name, RegexMatchDgName)
+			dg := rsCfg.IntDgMap[NameRef{Name: dgName, Partition: "test"}][namespace]
+			Expect(dg).NotTo(BeNil(), "Regex match data group not created")
+			// The pool with an invalid regular expression is ignored
+			Expect(dg.Records).To(HaveLen(1))
+			Expect(dg.Records[0].Name).To(Equal("test.com|000"))
+			fields := strings.SplitN(dg.Records[0].Data, " ", 2)
+			Expect(fields[0]).To(ContainSubstring("svc1"))
+			conditions, err := base64.StdEncoding.DecodeString(fields[1])
+			Expect(err).To(BeNil())
+			Expect(string(conditions)).To(Equal(`prefix /foo ` +
+				`header\ User-Agent\ regex\ 1\ (android|iphone)\\\ \\\[0-9\\\]+ ` +
+				`cookie\ tester\ equals\ 1\ true ` +
+				`method\ \{\}\ equals\ 0\ GET`))
+			Expect(rsCfg.Virtual.IRules).To(ContainElement(
+				JoinBigipPath("test", getRSCfgResName(rsCfg.Virtual.Name, RegexMatchIRuleName))))
+			Expect(rsCfg.IRulesMap).To(HaveKey(NameRef{
+				Name:      getRSCfgResName(rsCfg.Virtual.Name, RegexMatchIRuleName),
+				Partition: "test",
+			}))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with exact, prefix and regex paths", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.Pool{
+						{
+							Path:    "/foo/bar",
+							Service: "svc1",
+						},
+						{
+							Path:     "/foo",
+							PathType: PathTypeExact,
+							Service:  "svc2",
+						},
+						{
+							Path:     "^/api/v[0-9]+/users$",
+							PathType: PathTypeRegex,
+							Service:  "svc3",
+						},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Pools).To(HaveLen(3))
+			rules := rsCfg.Policies[0].Rules
+			Expect(rules).To(HaveLen(2), "Policy rule created for regex path")
+			// The exact path precedes the prefix paths with more segments
+			Expect(rules[0].Actions[0].Pool).To(ContainSubstring("svc2"))
+			Expect(rules[0].Conditions).To(HaveLen(2))
+			Expect(rules[0].Conditions[1].Path && rules[0].Conditions[1].Equals).To(BeTrue())
+			Expect(rules[0].Conditions[1].Values).To(Equal([]string{"/foo"}))
+			Expect(rules[1].Actions[0].Pool).To(ContainSubstring("svc1"))
+
+			dgName := getRSCfgResName(rsCfg.Virtual.Name, RegexPathDgName)
+			dg := rsCfg.IntDgMap[NameRef{Name: dgName, Partition: "test"}][namespace]
+			Expect(dg).NotTo(BeNil(), "Regex path data group not created")
+			Expect(dg.Records).To(Equal(InternalDataGroupRecords{
+				{Name: "test.com|002", Data: rsCfg.Pools[2].Name + " ^/api/v[0-9]+/users$"},
+			}))
+			Expect(rsCfg.Virtual.IRules).To(ContainElement(
+				JoinBigipPath("test", getRSCfgResName(rsCfg.Virtual.Name, RegexPathIRuleName))))
+
+			// The root path of the appRoot redirect is not an exact path
+			rl, err := createRedirectRule("test.com/", "/home", "redirect_rule", nil)
+			Expect(err).To(BeNil())
+			Expect(isExactPathRule(rl)).To(BeFalse())
+			Expect(isExactPathRule(rules[0])).To(BeTrue())
+
+			Expect(validateRegexPath(`^/api/\bv1$`)).NotTo(BeNil(), "Word boundary allowed in regex path")
+			Expect(validateRegexPath(`^/api/(?P<v>[0-9]+)$`)).NotTo(BeNil(), "Named group allowed in regex path")
+			Expect(validateRegexPath(`(?i)^/api/(?:v1|v2)/\d+$`)).To(BeNil())
+			pl := cisapiv1.Pool{Path: "^/api/v[0-9]+$", PathType: PathTypeRegex}
+			Expect(formatMonitorName(namespace, "svc3", "http", intstr.IntOrString{IntVal: 80}, "test.com",
+				getMonitorPath(pl))).To(MatchRegexp("^[A-Za-z0-9_]+$"))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with gRPC pools", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.MetaData.Protocol = HTTPS
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 443)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.Pool{
+						{
+							Path:        "/helloworld.Greeter",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: 50051},
+							Protocol:    PoolProtocolGRPC,
+							Monitor: cisapiv1.Monitor{
+								Type:     "http",
+								Send:     "GET /",
+								Interval: 10,
+								Timeout:  31,
+							},
+						},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).NotTo(BeNil(), "gRPC pools accepted without TLS")
+
+			vs.Spec.TLSProfileName = "sampleTLS"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, true)
+			Expect(err).NotTo(BeNil(), "gRPC pools accepted with passthrough termination")
+
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Monitors[len(rsCfg.Monitors)-1].Type).To(Equal("http2"), "gRPC pool not monitored over HTTP/2")
+			Expect(rsCfg.Virtual.GRPC).To(BeTrue())
+			Expect(rsCfg.Virtual.HttpMrfRoutingEnabled).To(BeTrue())
+			Expect(rsCfg.Virtual.Profiles).To(ContainElement(ProfileRef{
+				Name:      getRSCfgResName(rsCfg.Virtual.Name, GRPCHTTP2ProfileName),
+				Partition: "test",
+				Context:   "http2",
+			}))
+
+			vs.Spec.Pools[0].Monitor = cisapiv1.Monitor{Type: GRPCMonitor, Interval: 10, Timeout: 31}
+			rsCfg.Monitors = nil
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Monitors).To(HaveLen(1), "gRPC monitor not created")
+			Expect(rsCfg.Monitors[0].Type).To(Equal(GRPCMonitor))
+			Expect(rsCfg.Monitors[0].Send).To(HavePrefix("POST /grpc.health.v1.Health/Check HTTP/2.0\r\n"))
+			Expect(rsCfg.Monitors[0].Send).To(ContainSubstring("Host: test.com\r\n"))
+			Expect(rsCfg.Monitors[0].Recv).To(Equal("grpc-status: 0"))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with websocket", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.Pool{
+						{
+							Path:        "/ws",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: 80},
+						},
+					},
+					WebSocket:      &cisapiv1.WebSocket{},
+					TCPIdleTimeout: 3600,
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileWebSocket).To(Equal(DefaultWebSocketProfile))
+			Expect(rsCfg.Virtual.TCPSettings).To(Equal(&ProfileTCPSettings{IdleTimeout: 3600}))
+
+			vs.Spec.WebSocket.Profile = "/Common/custom-websocket"
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.ProfileWebSocket).To(Equal("/Common/custom-websocket"))
+
+			// The WebSocket profile cannot be attached to the BIG-IP HTTP profile of a Policy
+			mockCtlr.kubeClient = k8sfake.NewSimpleClientset()
+			rsCfg.Virtual.Profiles = append(rsCfg.Virtual.Profiles, ProfileRef{
+				Name:         "/Common/http",
+				Context:      "http",
+				BigIPProfile: true,
+			})
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).NotTo(BeNil(), "WebSocket profile accepted with the HTTP profile of a Policy")
+			events, err := mockCtlr.kubeClient.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{})
+			Expect(err).To(BeNil())
+			Expect(events.Items).To(HaveLen(1))
+			Expect(events.Items[0].Reason).To(Equal("HTTPProfileConflict"))
+			Expect(events.Items[0].InvolvedObject.Name).To(Equal("SampleVS"))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with multiple hosts", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Hosts: []string{"test.com", "*.example.com"},
+					Pools: []cisapiv1.Pool{
+						{
+							Path:        "/foo",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: 80},
+						},
+					},
+				},
+			)
+			for _, hostVS := range getVirtualServersForHosts([]*cisapiv1.VirtualServer{vs}) {
+				err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, hostVS, false)
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			}
+			Expect(rsCfg.Pools).To(HaveLen(2), "Pools not created for each host")
+			rules := rsCfg.Policies[0].Rules
+			Expect(rules).To(HaveLen(2), "Rules not created for each host")
+			var uris []string
+			for _, rl := range rules {
+				uris = append(uris, rl.FullURI)
+			}
+			Expect(uris).To(ConsistOf("test.com/foo", "*.example.com/foo"))
+			Expect(rsCfg.MetaData.hosts).To(Equal([]string{"test.com", "*.example.com"}))
+		})
+
+		It("Prepare Resource Config from a VirtualServer with a default pool", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.Pool{
+						{
+							Path:        "/foo",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: 80},
+						},
+					},
+					DefaultPool: &cisapiv1.DefaultPool{
+						Service:     "default-backend",
+						ServicePort: intstr.IntOrString{IntVal: 8080},
+						Monitor: cisapiv1.Monitor{
+							Type:     "http",
+							Send:     "GET /healthz",
+							Interval: 10,
+							Timeout:  31,
+						},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			defaultPoolName := formatPoolName(namespace, "default-backend", intstr.IntOrString{IntVal: 8080}, "", "test.com")
+			Expect(rsCfg.Pools).To(HaveLen(2), "Default pool not created")
+			Expect(rsCfg.Pools[1].Name).To(Equal(defaultPoolName))
+			Expect(rsCfg.Pools[1].MonitorNames).To(HaveLen(1), "Monitor of the default pool not attached")
+			Expect(rsCfg.Monitors).To(HaveLen(1), "Monitor of the default pool not created")
+			Expect(rsCfg.Virtual.PoolName).To(Equal(defaultPoolName), "Default pool not attached to the virtual")
+			Expect(rsCfg.Policies[0].Rules).To(HaveLen(1), "Rule created for the default pool")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with hosts and a default pool", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.Virtual.Partition = "test"
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host:  "test.com",
+					Hosts: []string{"test2.com"},
+					Pools: []cisapiv1.Pool{
+						{
+							Path:        "/foo",
+							Service:     "svc1",
+							ServicePort: intstr.IntOrString{IntVal: 80},
+						},
+					},
+					DefaultPool: &cisapiv1.DefaultPool{
+						Service:     "default-backend",
+						ServicePort: intstr.IntOrString{IntVal: 8080},
+					},
+				},
+			)
+			for _, hostVS := range getVirtualServersForHosts([]*cisapiv1.VirtualServer{vs}) {
+				err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, hostVS, false)
+				Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			}
+			defaultPoolName := formatPoolName(namespace, "default-backend", intstr.IntOrString{IntVal: 8080}, "", "test.com")
+			Expect(rsCfg.Virtual.PoolName).To(Equal(defaultPoolName), "Default pool of the first host not attached")
+			Expect(rsCfg.Pools).To(HaveLen(3), "Default pool created for each host")
+		})
+
+		It("Prepare Resource Config from a VirtualServer with match conditions", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+			rsCfg.Virtual.AllowSourceRange = []string{"10.0.0.0/8"}
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					Pools: []cisapiv1.Pool{
+						{
+							Path:    "/foo",
+							Service: "svc1",
+						},
+						{
+							Path:    "/foo",
+							Service: "svc1-blue",
+							Match: &cisapiv1.PoolMatch{
+								Headers: []cisapiv1.HTTPMatch{{Name: "X-Tenant", Values: []string{"blue"}}},
+							},
+						},
+						{
+							Path:    "/",
+							Service: "svc2",
+						},
+						{
+							Path:    "/",
+							Service: "svc2-test",
+							Match: &cisapiv1.PoolMatch{
+								Headers: []cisapiv1.HTTPMatch{{Name: "X-Test", Values: []string{"true"}}},
+								Methods: []string{"GET"},
+							},
+						},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			rules := rsCfg.Policies[0].Rules
+			Expect(rules).To(HaveLen(4))
+			// Longer paths first, then the rules with match conditions before the rule of their path
+			Expect(rules[0].Actions[0].Pool).To(ContainSubstring("svc1_blue"))
+			Expect(rules[1].Actions[0].Pool).To(ContainSubstring("svc1_"))
+			Expect(rules[2].Actions[0].Pool).To(ContainSubstring("svc2_test"))
+			Expect(rules[3].Actions[0].Pool).To(ContainSubstring("svc2_"))
+			Expect(rules[0].Name).NotTo(Equal(rules[1].Name))
+			// The allowSourceRange condition is not a match condition
+			for _, cnd := range rules[3].Conditions {
+				Expect(isMatchCondition(cnd)).To(BeFalse())
+			}
+		})
+
+		It("Prepare Resource Config from a VirtualServer with header manipulation", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					RequestHeaders: &cisapiv1.HeaderActions{
+						Insert: []cisapiv1.HTTPHeader{{Name: "X-Forwarded-Proto", Value: "https"}},
+					},
+					Pools: []cisapiv1.Pool{
+						{
+							Path:    "/foo",
+							Service: "svc1",
+							Rewrite: "/bar",
+							ResponseHeaders: &cisapiv1.HeaderActions{
+								Remove: []string{"Server"},
+							},
+						},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			actions := rsCfg.Policies[0].Rules[0].Actions
+			Expect(actions).To(HaveLen(4))
+			Expect(actions[2].Name).To(Equal("2"))
+			Expect(actions[2].HeaderName).To(Equal("X-Forwarded-Proto"))
+			Expect(actions[2].Insert && actions[2].Request).To(BeTrue())
+			Expect(actions[3].Name).To(Equal("3"))
+			Expect(actions[3].HeaderName).To(Equal("Server"))
+			Expect(actions[3].Remove && actions[3].Response).To(BeTrue())
+
+			// The header actions of the VirtualServer apply to the requests not matched by the rules of the pools
+			rules := rsCfg.Policies[0].Rules
+			Expect(rules).To(HaveLen(2))
+			Expect(rules[1].CatchAll).To(BeTrue())
+			Expect(rules[1].Conditions).To(HaveLen(1))
+			Expect(rules[1].Conditions[0].Values).To(Equal([]string{"test.com"}))
+			Expect(rules[1].Actions).To(HaveLen(1))
+			Expect(rules[1].Actions[0].Name).To(Equal("0"))
+			Expect(rules[1].Actions[0].HeaderName).To(Equal("X-Forwarded-Proto"))
+
+			// Values evaluated as Tcl and empty values are rejected
+			for _, value := range []string{"[HTTP::host]", "$host", `a\b`, ""} {
+				rsCfg.Policies = nil
+				vs.Spec.RequestHeaders.Insert[0].Value = value
+				err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+				Expect(err).NotTo(BeNil(), value)
+			}
+			vs.Spec.RequestHeaders.Insert[0].Value = "https"
+			vs.Spec.Pools[0].Rewrite = ""
+			vs.Spec.Pools[0].Redirect = &cisapiv1.PoolRedirect{URL: "https://test.com/$path"}
+			rsCfg.Policies = nil
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("Prepare Resource Config from a VirtualServer with header manipulation and a redirect", func() {
+			rsCfg.MetaData.ResourceType = VirtualServer
+			rsCfg.Virtual.Enabled = true
+			rsCfg.Virtual.Name = formatCustomVirtualServerName("My_VS", 80)
+			rsCfg.IntDgMap = make(InternalDataGroupMap)
+			rsCfg.IRulesMap = make(IRulesMap)
+
+			vs := test.NewVirtualServer(
+				"SampleVS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Host: "test.com",
+					ResponseHeaders: &cisapiv1.HeaderActions{
+						Remove: []string{"Server"},
+					},
+					Pools: []cisapiv1.Pool{
+						{
+							Path:     "/old",
+							Redirect: &cisapiv1.PoolRedirect{URL: "https://test.com/new"},
+						},
+					},
+				},
+			)
+			err := mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			rules := rsCfg.Policies[0].Rules
+			Expect(rules).To(HaveLen(2))
+			for _, rl := range rules {
+				last := rl.Actions[len(rl.Actions)-1]
+				Expect(last.HeaderName).To(Equal("Server"))
+				Expect(last.Remove && last.Response).To(BeTrue())
+			}
+			Expect(rules[0].Actions[0].Redirect).To(BeTrue())
+		})
+
 		It("Prepare Resource Config from a TransportServer", func() {
 			ts := test.NewTransportServer(
 				"SampleTS",
@@ -1058,6 +1698,52 @@ var _ = Describe("Resource Config Tests", func() {
 
 		})
 
+		It("Verifies TCP and HTTP settings for VirtualServer", func() {
+			plc.Spec.Profiles.TCPSettings = &cisapiv1.TCPSettings{IdleTimeout: 600, Nagle: "disable"}
+			plc.Spec.Profiles.HTTPSettings = &cisapiv1.HTTPSettings{ServerHeader: "none"}
+			err := mockCtlr.handleVSResourceConfigForPolicy(rsCfg, plc)
+			Expect(err).To(BeNil(), "Failed to handle VirtualServer for policy")
+			Expect(rsCfg.Virtual.TCPSettings).To(Equal(&ProfileTCPSettings{IdleTimeout: 600, Nagle: "disable"}))
+			Expect(rsCfg.Virtual.HTTPSettings).To(Equal(&ProfileHTTPSettings{ServerHeader: "none"}))
+
+			vs := test.NewVirtualServer(
+				"SamplevS",
+				namespace,
+				cisapiv1.VirtualServerSpec{
+					Profiles: cisapiv1.ProfileSpec{
+						TCPSettings: &cisapiv1.TCPSettings{KeepAliveInterval: 60},
+					},
+					TCPIdleTimeout: 3600,
+				},
+			)
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "Failed to Prepare Resource Config from VirtualServer")
+			Expect(rsCfg.Virtual.TCPSettings).To(Equal(&ProfileTCPSettings{IdleTimeout: 3600, KeepAliveInterval: 60}),
+				"TCP settings of VirtualServer should override the policy")
+			Expect(rsCfg.Virtual.HTTPSettings).To(Equal(&ProfileHTTPSettings{ServerHeader: "none"}))
+			Expect(vs.Spec.Profiles.TCPSettings.IdleTimeout).To(BeZero(), "VirtualServer should not be modified")
+
+			// The httpSettings conflict with the BIG-IP HTTP profile of the Policy
+			mockCtlr.kubeClient = k8sfake.NewSimpleClientset()
+			plc.Spec.Profiles.HTTP = "/Common/http"
+			err = mockCtlr.handleVSResourceConfigForPolicy(rsCfg, plc)
+			Expect(err).To(BeNil(), "Failed to handle VirtualServer for policy")
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).NotTo(BeNil(), "httpSettings accepted with the HTTP profile of a Policy")
+			events, err := mockCtlr.kubeClient.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{})
+			Expect(err).To(BeNil())
+			Expect(events.Items).To(HaveLen(1))
+			Expect(events.Items[0].Reason).To(Equal("HTTPProfileConflict"))
+			Expect(events.Items[0].Message).To(ContainSubstring("httpSettings"))
+
+			plc.Spec.Profiles.HTTPSettings = nil
+			rsCfg.Virtual.Profiles = nil
+			err = mockCtlr.handleVSResourceConfigForPolicy(rsCfg, plc)
+			Expect(err).To(BeNil(), "Failed to handle VirtualServer for policy")
+			err = mockCtlr.prepareRSConfigFromVirtualServer(rsCfg, vs, false)
+			Expect(err).To(BeNil(), "HTTP profile of a Policy rejected without httpSettings")
+		})
+
 		It("Verifies SNAT whether is set properly for TransportServer", func() {
 			err := mockCtlr.handleTSResourceConfigForPolicy(rsCfg, plc)
 			Expect(err).To(BeNil(), "Failed to handle TransportServer for policy")
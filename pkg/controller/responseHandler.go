@@ -12,15 +12,25 @@ import (
 )
 
 func (ctlr *Controller) enqueueReq(config ResourceConfigRequest) int {
-	rm := requestMeta{
-		meta: make(map[string]string, len(config.ltmConfig)),
-	}
+	rm := newRequestMeta(config)
 	if ctlr.requestQueue.Len() == 0 {
 		rm.id = 1
 	} else {
 		rm.id = ctlr.requestQueue.Back().Value.(requestMeta).id + 1
 	}
+	if len(rm.meta) > 0 {
+		ctlr.requestQueue.Lock()
+		ctlr.requestQueue.PushBack(rm)
+		ctlr.requestQueue.Unlock()
+	}
+	return rm.id
+}
 
+// newRequestMeta returns the resources of the config request
+func newRequestMeta(config ResourceConfigRequest) requestMeta {
+	rm := requestMeta{
+		meta: make(map[string]string, len(config.ltmConfig)),
+	}
 	for partition, partitionConfig := range config.ltmConfig {
 		for _, cfg := range partitionConfig.ResourceMap {
 			for key, val := range cfg.MetaData.baseResources {
@@ -29,98 +39,97 @@ func (ctlr *Controller) enqueueReq(config ResourceConfigRequest) int {
 			}
 		}
 	}
-	if len(rm.meta) > 0 {
-		ctlr.requestQueue.Lock()
-		ctlr.requestQueue.PushBack(rm)
-		ctlr.requestQueue.Unlock()
-	}
-	return rm.id
+	return rm
 }
 
 func (ctlr *Controller) responseHandler(respChan chan resourceStatusMeta) {
 	// todo: update only when there is a change(success to fail or vice versa) in tenant status
 	ctlr.requestQueue = &requestQueue{sync.Mutex{}, list.New()}
 	for rscUpdateMeta := range respChan {
-
 		rm := ctlr.dequeueReq(rscUpdateMeta.id, len(rscUpdateMeta.failedTenants))
-		partition := rm.partition
-		for rscKey, kind := range rm.meta {
-			ns := strings.Split(rscKey, "/")[0]
-			switch kind {
-			case VirtualServer:
-				// update status
-				crInf, ok := ctlr.getNamespacedCRInformer(ns)
-				if !ok {
-					log.Debugf("VirtualServer Informer not found for namespace: %v", ns)
-					continue
-				}
-				obj, exist, err := crInf.vsInformer.GetIndexer().GetByKey(rscKey)
-				if err != nil {
-					log.Debugf("Could not fetch VirtualServer: %v: %v", rscKey, err)
-					continue
-				}
-				if !exist {
-					log.Debugf("VirtualServer Not Found: %v", rscKey)
-					continue
-				}
-				virtual := obj.(*cisapiv1.VirtualServer)
-				if virtual.Namespace+"/"+virtual.Name == rscKey {
-					if _, found := rscUpdateMeta.failedTenants[partition]; !found {
-						ctlr.resources.updatePartitionPriority(partition, 0)
-					}
-					ctlr.updateVirtualServerStatus(virtual, virtual.Status.VSAddress, "Ok")
-				}
-				// Update Corresponding Service Status of Type LB
-				for _, pool := range virtual.Spec.Pools {
-					var svcNamespace string
-					if pool.ServiceNamespace != "" {
-						svcNamespace = pool.ServiceNamespace
-					} else {
-						svcNamespace = virtual.Namespace
-					}
-					svc := ctlr.GetService(svcNamespace, pool.Service)
-					if svc != nil && svc.Spec.Type == v1.ServiceTypeLoadBalancer {
-						ctlr.setLBServiceIngressStatus(svc, virtual.Status.VSAddress)
-					}
-				}
-			case TransportServer:
-				// update status
-				crInf, ok := ctlr.getNamespacedCRInformer(ns)
-				if !ok {
-					log.Debugf("TransportServer Informer not found for namespace: %v", ns)
-					continue
-				}
-				obj, exist, err := crInf.tsInformer.GetIndexer().GetByKey(rscKey)
-				if err != nil {
-					log.Debugf("Could not fetch TransportServer: %v: %v", rscKey, err)
-					continue
+		ctlr.updateRequestStatus(rm, rscUpdateMeta)
+	}
+}
+
+// updateRequestStatus updates the statuses of the resources of the request with the response of the BIG-IP
+func (ctlr *Controller) updateRequestStatus(rm requestMeta, rscUpdateMeta resourceStatusMeta) {
+	partition := rm.partition
+	for rscKey, kind := range rm.meta {
+		ns := strings.Split(rscKey, "/")[0]
+		switch kind {
+		case VirtualServer:
+			// update status
+			crInf, ok := ctlr.getNamespacedCRInformer(ns)
+			if !ok {
+				log.Debugf("VirtualServer Informer not found for namespace: %v", ns)
+				continue
+			}
+			obj, exist, err := crInf.vsInformer.GetIndexer().GetByKey(rscKey)
+			if err != nil {
+				log.Debugf("Could not fetch VirtualServer: %v: %v", rscKey, err)
+				continue
+			}
+			if !exist {
+				log.Debugf("VirtualServer Not Found: %v", rscKey)
+				continue
+			}
+			virtual := obj.(*cisapiv1.VirtualServer)
+			if virtual.Namespace+"/"+virtual.Name == rscKey {
+				if _, found := rscUpdateMeta.failedTenants[partition]; !found {
+					ctlr.resources.updatePartitionPriority(partition, 0)
 				}
-				if !exist {
-					log.Debugf("TransportServer Not Found: %v", rscKey)
-					continue
+				ctlr.updateVirtualServerStatus(virtual, virtual.Status.VSAddress, "Ok")
+			}
+			// Update Corresponding Service Status of Type LB
+			for _, pool := range virtual.Spec.Pools {
+				var svcNamespace string
+				if pool.ServiceNamespace != "" {
+					svcNamespace = pool.ServiceNamespace
+				} else {
+					svcNamespace = virtual.Namespace
 				}
-				virtual := obj.(*cisapiv1.TransportServer)
-				if virtual.Namespace+"/"+virtual.Name == rscKey {
-					if _, found := rscUpdateMeta.failedTenants[partition]; !found {
-						// updating the tenant priority back to zero if it's not in failed tenants
-						ctlr.resources.updatePartitionPriority(partition, 0)
-					}
-					ctlr.updateTransportServerStatus(virtual, virtual.Status.VSAddress, "Ok")
+				svc := ctlr.GetService(svcNamespace, pool.Service)
+				if svc != nil && svc.Spec.Type == v1.ServiceTypeLoadBalancer {
+					ctlr.setLBServiceIngressStatus(svc, virtual.Status.VSAddress)
 				}
-			case Route:
-				if _, found := rscUpdateMeta.failedTenants[partition]; found {
-					// TODO : distinguish between a 503 and an actual failure
-					go ctlr.updateRouteAdmitStatus(rscKey, "Failure while updating config", "Please check logs for more information", v1.ConditionFalse)
-				} else {
+			}
+		case TransportServer:
+			// update status
+			crInf, ok := ctlr.getNamespacedCRInformer(ns)
+			if !ok {
+				log.Debugf("TransportServer Informer not found for namespace: %v", ns)
+				continue
+			}
+			obj, exist, err := crInf.tsInformer.GetIndexer().GetByKey(rscKey)
+			if err != nil {
+				log.Debugf("Could not fetch TransportServer: %v: %v", rscKey, err)
+				continue
+			}
+			if !exist {
+				log.Debugf("TransportServer Not Found: %v", rscKey)
+				continue
+			}
+			virtual := obj.(*cisapiv1.TransportServer)
+			if virtual.Namespace+"/"+virtual.Name == rscKey {
+				if _, found := rscUpdateMeta.failedTenants[partition]; !found {
 					// updating the tenant priority back to zero if it's not in failed tenants
 					ctlr.resources.updatePartitionPriority(partition, 0)
-					go ctlr.updateRouteAdmitStatus(rscKey, "", "", v1.ConditionTrue)
 				}
-			case IngressLink:
+				ctlr.updateTransportServerStatus(virtual, virtual.Status.VSAddress, "Ok")
+			}
+		case Route:
+			if _, found := rscUpdateMeta.failedTenants[partition]; found {
+				// TODO : distinguish between a 503 and an actual failure
+				go ctlr.updateRouteAdmitStatus(rscKey, "Failure while updating config", "Please check logs for more information", v1.ConditionFalse)
+			} else {
 				// updating the tenant priority back to zero if it's not in failed tenants
-				if _, found := rscUpdateMeta.failedTenants[partition]; !found {
-					ctlr.resources.updatePartitionPriority(partition, 0)
-				}
+				ctlr.resources.updatePartitionPriority(partition, 0)
+				go ctlr.updateRouteAdmitStatus(rscKey, "", "", v1.ConditionTrue)
+			}
+		case IngressLink:
+			// updating the tenant priority back to zero if it's not in failed tenants
+			if _, found := rscUpdateMeta.failedTenants[partition]; !found {
+				ctlr.resources.updatePartitionPriority(partition, 0)
 			}
 		}
 	}
@@ -17,12 +17,14 @@
 package controller
 
 import (
+	"encoding/base64"
 	"fmt"
 
 	routeapi "github.com/openshift/api/route/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"net/url"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -46,19 +48,31 @@ func (ctlr *Controller) prepareVirtualServerRules(
 	appRoot := "/"
 
 	if vs.Spec.RewriteAppRoot != "" {
+		if err := validatePolicyValue(vs.Spec.RewriteAppRoot); nil != err {
+			log.Errorf("Error configuring redirect rule: %v", err)
+			return nil
+		}
 		ruleName := formatVirtualServerRuleName(vs.Spec.Host, vs.Spec.HostGroup, "redirectto", vs.Spec.RewriteAppRoot)
 		rl, err := createRedirectRule(vs.Spec.Host+appRoot, vs.Spec.RewriteAppRoot, ruleName, rsCfg.Virtual.AllowSourceRange)
 		if nil != err {
 			log.Errorf("Error configuring redirect rule: %v", err)
 			return nil
 		}
+		if err := appendHeaderActions(rl, vs, nil); nil != err {
+			log.Errorf("Error configuring redirect rule: %v", err)
+			return nil
+		}
 		redirects = append(redirects, rl)
 
 	}
 
-	for _, pl := range vs.Spec.Pools {
-		// Service cannot be empty
-		if pl.Service == "" {
+	for plIndex, pl := range vs.Spec.Pools {
+		// Service cannot be empty, except for redirects and direct responses
+		if pl.Service == "" && pl.Redirect == nil && pl.DirectResponse == nil {
+			continue
+		}
+		// Regex paths and regex match conditions are matched by an iRule
+		if pl.PathType == PathTypeRegex || hasRegexMatch(pl) {
 			continue
 		}
 		// If not using WAF from policy CR, use Pool Based WAF from VS
@@ -84,48 +98,84 @@ func (ctlr *Controller) prepareVirtualServerRules(
 			path = vs.Spec.RewriteAppRoot
 		}
 
-		poolName := ctlr.framePoolName(
-			vs.ObjectMeta.Namespace,
-			pl,
-			vs.Spec.Host,
-		)
+		// Redirected and directly responded requests are not forwarded to a pool
+		poolName := "redirect"
+		if pl.Redirect == nil && pl.DirectResponse != nil {
+			poolName = "directresponse"
+		} else if pl.Redirect == nil {
+			poolName = ctlr.framePoolName(
+				vs.ObjectMeta.Namespace,
+				pl,
+				vs.Spec.Host,
+			)
+		}
 		ruleName := formatVirtualServerRuleName(vs.Spec.Host, vs.Spec.HostGroup, path, poolName)
+		// Rules of pools with match conditions are distinct from the rule of the path
+		ruleKey := uri
+		if pl.Match != nil {
+			ruleName = AS3NameFormatter(fmt.Sprintf("%s_match_%d", ruleName, plIndex))
+			ruleKey = fmt.Sprintf("%s#match_%d", uri, plIndex)
+		}
+		exact := pl.PathType == PathTypeExact
+		if exact {
+			ruleName += "_exact"
+			ruleKey += "#exact"
+		}
 		var err error
 		rl, err := createRule(uri, poolName, ruleName, rsCfg.Virtual.AllowSourceRange, wafPolicy)
 		if nil != err {
 			log.Errorf("Error configuring rule: %v", err)
 			return nil
 		}
-		if pl.HostRewrite != "" {
-			hostRewriteActions, err := getHostRewriteActions(
-				pl.HostRewrite,
-				len(rl.Actions),
-			)
-			if nil != err {
-				log.Errorf("Error configuring rule: %v", err)
-				return nil
-			}
-			rl.Actions = append(rl.Actions, hostRewriteActions...)
+		if exact {
+			setExactPathCondition(rl, pl.Path)
 		}
-		if pl.Rewrite != "" {
-			rewriteActions, err := getRewriteActions(
-				path,
-				pl.Rewrite,
-				len(rl.Actions),
-			)
+		rl.Conditions = append(rl.Conditions, createMatchConditions(pl.Match)...)
+		if pl.Redirect != nil {
+			redirectAction, err := getRedirectAction(pl.Redirect)
 			if nil != err {
 				log.Errorf("Error configuring rule: %v", err)
 				return nil
 			}
-			rl.Actions = append(rl.Actions, rewriteActions...)
+			rl.Actions = []*action{redirectAction}
+		} else if pl.DirectResponse != nil {
+			rl.Actions = []*action{getDirectResponseAction(vs, plIndex)}
+		} else {
+			if pl.HostRewrite != "" {
+				hostRewriteActions, err := getHostRewriteActions(
+					pl.HostRewrite,
+					len(rl.Actions),
+				)
+				if nil != err {
+					log.Errorf("Error configuring rule: %v", err)
+					return nil
+				}
+				rl.Actions = append(rl.Actions, hostRewriteActions...)
+			}
+			if pl.Rewrite != "" {
+				rewriteActions, err := getRewriteActions(
+					path,
+					pl.Rewrite,
+					len(rl.Actions),
+				)
+				if nil != err {
+					log.Errorf("Error configuring rule: %v", err)
+					return nil
+				}
+				rl.Actions = append(rl.Actions, rewriteActions...)
+			}
+		}
+		if err := appendHeaderActions(rl, vs, &vs.Spec.Pools[plIndex]); nil != err {
+			log.Errorf("Error configuring rule: %v", err)
+			return nil
 		}
 
-		if pl.Path == "/" {
+		if pl.Path == "/" && pl.Match == nil && !exact {
 			redirects = append(redirects, rl)
 		} else if true == strings.HasPrefix(uri, "*.") {
-			wildcards[uri] = rl
+			wildcards[ruleKey] = rl
 		} else {
-			rlMap[uri] = rl
+			rlMap[ruleKey] = rl
 		}
 	}
 
@@ -173,6 +223,24 @@ func (ctlr *Controller) prepareVirtualServerRules(
 
 	sort.Sort(rls)
 	rls = append(redirects, rls...)
+
+	// The header actions of the VirtualServer also apply to the requests for which no rule selects a pool,
+	// such as those served by the default pool or matched by a regex path
+	if vs.Spec.RequestHeaders != nil || vs.Spec.ResponseHeaders != nil {
+		ruleName := formatVirtualServerRuleName(vs.Spec.Host, "", "", "headers")
+		rl, err := createRule(vs.Spec.Host, "", ruleName, nil, "")
+		if nil != err {
+			log.Errorf("Error configuring rule: %v", err)
+			return nil
+		}
+		rl.Actions = nil
+		rl.CatchAll = true
+		if err := appendHeaderActions(rl, vs, nil); nil != err {
+			log.Errorf("Error configuring rule: %v", err)
+			return nil
+		}
+		rls = append(rls, rl)
+	}
 	return &rls
 }
 
@@ -277,6 +345,81 @@ func createRule(uri, poolName, ruleName string, allowSourceRange []string, wafPo
 	return &rl, nil
 }
 
+// createMatchConditions creates the header, cookie, query parameter, method and source address
+// conditions of a pool
+func createMatchConditions(match *cisapiv1.PoolMatch) []*condition {
+	if match == nil {
+		return nil
+	}
+	var conditions []*condition
+	for _, header := range match.Headers {
+		cond := newHTTPMatchCondition(header)
+		cond.HTTPHeader = true
+		conditions = append(conditions, cond)
+	}
+	for _, cookie := range match.Cookies {
+		cond := newHTTPMatchCondition(cookie)
+		cond.HTTPCookie = true
+		conditions = append(conditions, cond)
+	}
+	for _, param := range match.QueryParameters {
+		cond := newHTTPMatchCondition(param)
+		cond.HTTPURI = true
+		cond.QueryParameter = true
+		conditions = append(conditions, cond)
+	}
+	if len(match.Methods) > 0 {
+		var methods []string
+		for _, method := range match.Methods {
+			methods = append(methods, strings.ToUpper(method))
+		}
+		conditions = append(conditions, &condition{
+			Equals:        true,
+			HTTPMethod:    true,
+			CaseSensitive: true,
+			Request:       true,
+			Values:        methods,
+		})
+	}
+	if len(match.SourceAddresses) > 0 {
+		conditions = append(conditions, &condition{
+			Tcp:     true,
+			Address: true,
+			Values:  match.SourceAddresses,
+		})
+	}
+	for _, cond := range conditions {
+		cond.PoolMatch = true
+	}
+	return conditions
+}
+
+func newHTTPMatchCondition(match cisapiv1.HTTPMatch) *condition {
+	cond := &condition{
+		Name:          match.Name,
+		CaseSensitive: match.CaseSensitive,
+		Request:       true,
+		Values:        match.Values,
+	}
+	switch match.Operand {
+	case "starts-with":
+		cond.StartsWith = true
+	case "ends-with":
+		cond.EndsWith = true
+	case "contains":
+		cond.Contains = true
+	default:
+		cond.Equals = true
+	}
+	return cond
+}
+
+// isMatchCondition checks if the condition is a match condition of a pool rather than a host, path
+// or allowSourceRange condition
+func isMatchCondition(cnd *condition) bool {
+	return cnd.PoolMatch
+}
+
 func createPathSegmentConditions(u *url.URL) []*condition {
 
 	var c []*condition
@@ -304,6 +447,36 @@ func createPathSegmentConditions(u *url.URL) []*condition {
 	return c
 }
 
+// setExactPathCondition matches the whole path of the rule instead of its path segments
+func setExactPathCondition(rl *Rule, path string) {
+	var conditions []*condition
+	for _, cond := range rl.Conditions {
+		if !cond.PathSegment {
+			conditions = append(conditions, cond)
+		}
+	}
+	rl.Conditions = append(conditions, &condition{
+		Equals:    true,
+		HTTPURI:   true,
+		Path:      true,
+		Name:      "0",
+		Index:     0,
+		Request:   true,
+		Values:    []string{path},
+		ExactPath: true,
+	})
+}
+
+// isExactPathRule checks that the rule matches the whole path of the request of a pool with an exact path
+func isExactPathRule(rule *Rule) bool {
+	for _, cond := range rule.Conditions {
+		if cond.ExactPath {
+			return true
+		}
+	}
+	return false
+}
+
 func createPolicy(rls Rules, policyName, partition string) *Policy {
 	plcy := Policy{
 		Controls:  []string{PolicyControlForward},
@@ -365,6 +538,126 @@ func getHostRewriteActions(rwHost string, actionNameIndex int) ([]*action, error
 	}}, nil
 }
 
+// getDirectResponseKey returns the key of the direct response of a VirtualServer pool in the direct response data group
+func getDirectResponseKey(vs *cisapiv1.VirtualServer, plIndex int) string {
+	return fmt.Sprintf("%s/%s/%d", vs.Namespace, vs.Name, plIndex)
+}
+
+// getDirectResponseAction returns the action marking the requests of the rule of a pool with the key of its
+// direct response, which is sent by the direct response iRule
+func getDirectResponseAction(vs *cisapiv1.VirtualServer, plIndex int) *action {
+	return &action{
+		Name:         "0",
+		SetVariable:  true,
+		VariableName: DirectResponseVariable,
+		Value:        getDirectResponseKey(vs, plIndex),
+		Request:      true,
+	}
+}
+
+// getRedirectAction returns the action redirecting the requests to the URL of the redirect
+func getRedirectAction(redirect *cisapiv1.PoolRedirect) (*action, error) {
+	if err := validatePolicyValue(redirect.URL); err != nil {
+		return nil, fmt.Errorf("invalid redirect url: %v", err)
+	}
+	location := redirect.URL
+	if redirect.PreservePath {
+		location = strings.TrimSuffix(location, "/")
+	}
+	switch {
+	case redirect.PreservePath && redirect.PreserveQuery:
+		location += "[HTTP::uri]"
+	case redirect.PreservePath:
+		location += "[HTTP::path]"
+	case redirect.PreserveQuery:
+		location += `[expr {[HTTP::query] eq "" ? "" : "?[HTTP::query]"}]`
+	}
+	return &action{
+		Name:      "0",
+		HttpReply: true,
+		Location:  location,
+		Code:      redirect.Code,
+		Redirect:  true,
+		Request:   true,
+	}, nil
+}
+
+// validatePolicyValue checks that a value of a policy action has none of the characters
+// which BIG-IP evaluates as Tcl
+func validatePolicyValue(value string) error {
+	if strings.ContainsAny(value, `[$\`) {
+		return fmt.Errorf("%q contains [, $ or \\, which are not supported", value)
+	}
+	return nil
+}
+
+// appendHeaderActions appends the request and response header actions of the VirtualServer,
+// followed by those of the pool if any, to the actions of the rule
+func appendHeaderActions(rl *Rule, vs *cisapiv1.VirtualServer, pl *cisapiv1.Pool) error {
+	requestHeaders := []*cisapiv1.HeaderActions{vs.Spec.RequestHeaders}
+	responseHeaders := []*cisapiv1.HeaderActions{vs.Spec.ResponseHeaders}
+	if pl != nil {
+		requestHeaders = append(requestHeaders, pl.RequestHeaders)
+		responseHeaders = append(responseHeaders, pl.ResponseHeaders)
+	}
+	for i, headers := range append(requestHeaders, responseHeaders...) {
+		actions, err := getHeaderActions(headers, i >= len(requestHeaders), len(rl.Actions))
+		if err != nil {
+			return err
+		}
+		rl.Actions = append(rl.Actions, actions...)
+	}
+	return nil
+}
+
+// getHeaderActions returns the actions inserting, replacing and removing the request or response headers
+func getHeaderActions(headers *cisapiv1.HeaderActions, response bool, actionNameIndex int) ([]*action, error) {
+	if headers == nil {
+		return nil, nil
+	}
+	validateValue := func(header cisapiv1.HTTPHeader) error {
+		if header.Value == "" {
+			return fmt.Errorf("header %v has no value", header.Name)
+		}
+		if err := validatePolicyValue(header.Value); err != nil {
+			return fmt.Errorf("invalid value of header %v: %v", header.Name, err)
+		}
+		return nil
+	}
+	var actions []*action
+	newAction := func(name string) *action {
+		act := &action{
+			Name:       fmt.Sprintf("%d", actionNameIndex+len(actions)),
+			HTTPHeader: true,
+			HeaderName: name,
+			Request:    !response,
+			Response:   response,
+		}
+		actions = append(actions, act)
+		return act
+	}
+	for _, header := range headers.Insert {
+		if err := validateValue(header); err != nil {
+			return nil, err
+		}
+		act := newAction(header.Name)
+		act.Insert = true
+		act.Value = header.Value
+	}
+	for _, header := range headers.Replace {
+		if err := validateValue(header); err != nil {
+			return nil, err
+		}
+		act := newAction(header.Name)
+		act.Replace = true
+		act.Value = header.Value
+	}
+	for _, name := range headers.Remove {
+		newAction(name).Remove = true
+	}
+	return actions, nil
+}
+
 func createRedirectRule(source, target, ruleName string, allowSourceRange []string) (*Rule, error) {
 	_u := "scheme://" + source
 	_u = strings.TrimSuffix(_u, "/")
@@ -441,12 +734,33 @@ func (rules Rules) Len() int {
 func (rules Rules) Less(i, j int) bool {
 	ruleI := rules[i]
 	ruleJ := rules[j]
-	// Strategy 1: Rule with Highest number of conditions
-	l1 := len(ruleI.Conditions)
-	l2 := len(ruleJ.Conditions)
+	// Catch-all rules follow the rules selecting a pool
+	if ruleI.CatchAll != ruleJ.CatchAll {
+		return ruleJ.CatchAll
+	}
+	// Exact paths take precedence over the prefix paths
+	if isExactPathRule(ruleI) != isExactPathRule(ruleJ) {
+		return isExactPathRule(ruleI)
+	}
+	// Strategy 1: Rule with Highest number of host and path conditions, then with the highest number
+	// of match conditions so that the rules of pools with match conditions precede the rule of their path
+	countConditions := func(rule *Rule) (int, int) {
+		var matchCount int
+		for _, cnd := range rule.Conditions {
+			if isMatchCondition(cnd) {
+				matchCount++
+			}
+		}
+		return len(rule.Conditions) - matchCount, matchCount
+	}
+	l1, m1 := countConditions(ruleI)
+	l2, m2 := countConditions(ruleJ)
 	if l1 != l2 {
 		return l1 > l2
 	}
+	if m1 != m2 {
+		return m1 > m2
+	}
 
 	// Strategy 2: Rule with highest priority sequence of condition types
 	// TODO
@@ -458,11 +772,8 @@ func (rules Rules) Less(i, j int) bool {
 		}
 		return false
 	}
-	if pathExists(ruleI) {
-		return true
-	}
-	if pathExists(ruleJ) {
-		return true
+	if pathExists(ruleI) != pathExists(ruleJ) {
+		return pathExists(ruleI)
 	}
 
 	// Strategy 3: "equal" match type takes more priority than others
@@ -641,6 +952,181 @@ func (ctlr *Controller) GetPathBasedABDeployIRule(rsVSName string, partition str
 	return iRule
 }
 
+// GetVirtualServerABDeployIRule returns the iRule selecting a pool by weight among the alternateBackends of the
+// pool selected for the request by the policy or the regex path iRule, keyed by that pool and the longest
+// host and path of the request, so that the pools selected by the other rules are not overridden
+func (ctlr *Controller) GetVirtualServerABDeployIRule(rsVSName string, partition string) string {
+	dgPath := strings.Join([]string{partition, Shared}, "/")
+
+	iRule := fmt.Sprintf(`when HTTP_REQUEST priority 200 {
+			if {[HTTP::has_responded]} then {
+				return
+			}
+			set ab_class "/%[1]s/%[2]s_ab_deployment_dg"
+			set current_pool [lindex [split [LB::server pool] "/"] end]
+			if {$current_pool == ""} then {
+				return
+			}
+			set path [string tolower [getfield [HTTP::host] ":" 1]][HTTP::path]
+			set last_slash [string length $path]
+			while {$last_slash >= 0} {
+				if {[class match "$current_pool|$path" equals $ab_class]} then {
+					break
+				}
+				set last_slash [string last "/" $path $last_slash]
+				incr last_slash -1
+				set path [string range $path 0 $last_slash]
+			}
+			if {$last_slash < 0} then {
+				return
+			}
+			set ab_rule [class match -value "$current_pool|$path" equals $ab_class]
+			if {$ab_rule != ""} then {
+				set weight_selection [expr {rand()}]
+				foreach service_rule [split $ab_rule ";"] {
+					set fields [split $service_rule ","]
+					if {$weight_selection <= [expr {double([lindex $fields 1])}]} then {
+						pool [lindex $fields 0]
+						event disable
+						return
+					}
+				}
+			}
+			# If we had a match, but all weights were 0 then
+			# return a 503 (Service Unavailable)
+			HTTP::respond 503
+			event disable
+		}`, dgPath, rsVSName)
+
+	return iRule
+}
+
+// GetRegexPathIRule returns the iRule selecting the pool of the first regex path of the host matching the path
+// of the request
+func (ctlr *Controller) GetRegexPathIRule(rsVSName string, partition string) string {
+	dgPath := strings.Join([]string{partition, Shared}, "/")
+
+	iRule := fmt.Sprintf(`when HTTP_REQUEST priority 150 {
+			set host [string tolower [getfield [HTTP::host] ":" 1]]
+			set rp_class "/%[1]s/%[2]s_regex_path_dg"
+			foreach name [lsort [class names $rp_class "$host|*"]] {
+				set entry [class lookup $name $rp_class]
+				set separator [string first " " $entry]
+				if {[regexp -- [string range $entry [expr {$separator + 1}] end] [HTTP::path]]} then {
+					pool [string range $entry 0 [expr {$separator - 1}]]
+					return
+				}
+			}
+		}`, dgPath, rsVSName)
+
+	return iRule
+}
+
+// GetRegexMatchIRule returns the iRule selecting the pool of the first pool with regex match conditions of the host
+// whose path and conditions match the request. It runs after the policy and the regex path iRule, so that the pools
+// with match conditions take precedence over the pools of the same path without them.
+func (ctlr *Controller) GetRegexMatchIRule(rsVSName string, partition string) string {
+	dgPath := strings.Join([]string{partition, Shared}, "/")
+
+	iRule := fmt.Sprintf(`when HTTP_REQUEST priority 160 {
+			set host [string tolower [getfield [HTTP::host] ":" 1]]
+			set rm_class "/%[1]s/%[2]s_regex_match_dg"
+			foreach name [lsort [class names $rm_class "$host|*"]] {
+				set entry [class lookup $name $rm_class]
+				set separator [string first " " $entry]
+				set conditions [b64decode [string range $entry [expr {$separator + 1}] end]]
+				set path [lindex $conditions 1]
+				switch -- [lindex $conditions 0] {
+					exact { set matched [expr {[HTTP::path] eq $path}] }
+					regex { set matched [regexp -- $path [HTTP::path]] }
+					default {
+						set path [string trimright $path "/"]
+						set matched [expr {$path eq "" || [HTTP::path] eq $path || [HTTP::path] starts_with "$path/"}]
+					}
+				}
+				foreach condition [lrange $conditions 2 end] {
+					if {!$matched} then {
+						break
+					}
+					set cname [lindex $condition 1]
+					set operand [lindex $condition 2]
+					set nocase [lindex $condition 3]
+					switch -- [lindex $condition 0] {
+						header { set subjects [HTTP::header values $cname] }
+						cookie {
+							set subjects {}
+							if {[HTTP::cookie exists $cname]} then {
+								set subjects [list [HTTP::cookie value $cname]]
+							}
+						}
+						query { set subjects [list [URI::query [HTTP::uri] $cname]] }
+						method { set subjects [list [HTTP::method]] }
+						default { set subjects [list [IP::client_addr]] }
+					}
+					set matched 0
+					foreach subject $subjects {
+						foreach value [lrange $condition 4 end] {
+							switch -- $operand {
+								regex {
+									if {$nocase} then {
+										set matched [regexp -nocase -- $value $subject]
+									} else {
+										set matched [regexp -- $value $subject]
+									}
+								}
+								address { set matched [IP::addr $subject equals $value] }
+								default {
+									if {$nocase} then {
+										set subject [string tolower $subject]
+									}
+									switch -- $operand {
+										starts-with { set matched [expr {$subject starts_with $value}] }
+										ends-with { set matched [expr {$subject ends_with $value}] }
+										contains { set matched [expr {$subject contains $value}] }
+										default { set matched [expr {$subject eq $value}] }
+									}
+								}
+							}
+							if {$matched} then {
+								break
+							}
+						}
+						if {$matched} then {
+							break
+						}
+					}
+				}
+				if {$matched} then {
+					pool [string range $entry 0 [expr {$separator - 1}]]
+					return
+				}
+			}
+		}`, dgPath, rsVSName)
+
+	return iRule
+}
+
+// GetDirectResponseIRule returns the iRule sending the direct response of the policy rule which matched the request
+func (ctlr *Controller) GetDirectResponseIRule(rsVSName string, partition string) string {
+	dgPath := strings.Join([]string{partition, Shared}, "/")
+
+	iRule := fmt.Sprintf(`when HTTP_REQUEST priority 100 {
+			if {not [info exists %[3]s]} then {
+				return
+			}
+			# The variable is set by the policy for each request, it is kept by the following requests of the connection
+			set response [class match -value $%[3]s equals "/%[1]s/%[2]s_direct_response_dg"]
+			unset %[3]s
+			if {$response ne ""} then {
+				set fields [split $response " "]
+				HTTP::respond [lindex $fields 0] content [b64decode [lindex $fields 2]] \
+					"Content-Type" [b64decode [lindex $fields 1]]
+			}
+		}`, dgPath, rsVSName, DirectResponseVariable)
+
+	return iRule
+}
+
 func (ctlr *Controller) getTLSIRule(rsVSName string, partition string, allowSourceRange []string) string {
 	dgPath := strings.Join([]string{partition, Shared}, "/")
 
@@ -1080,11 +1566,7 @@ func (ctlr *Controller) updateDataGroupForABRoute(
 		return
 	}
 
-	weightTotal := 0
 	backends := GetRouteBackends(route)
-	for _, svc := range backends {
-		weightTotal = weightTotal + svc.Weight
-	}
 
 	path := route.Spec.Path
 	tls := route.Spec.TLS
@@ -1100,6 +1582,302 @@ func (ctlr *Controller) updateDataGroupForABRoute(
 	}
 	key := route.Spec.Host + path
 
+	var poolNames []string
+	var weights []int
+	for _, be := range backends {
+		poolNames = append(poolNames, formatPoolName(
+			route.Namespace,
+			be.Name,
+			port,
+			"",
+			"",
+		))
+		weights = append(weights, be.Weight)
+	}
+	updateDataGroupForAB(dgMap, dgName, partition, namespace, key, poolNames, weights)
+}
+
+// updateDataGroupForABVirtualServer updates the data group map based on alternateBackends of the
+// pools of the virtual server, and returns true if any pool has alternateBackends.
+func (ctlr *Controller) updateDataGroupForABVirtualServer(
+	vs *cisapiv1.VirtualServer,
+	dgName string,
+	partition string,
+	dgMap InternalDataGroupMap,
+) bool {
+	abDeployment := false
+	for _, pl := range vs.Spec.Pools {
+		if len(pl.AlternateBackends) == 0 {
+			continue
+		}
+		// The A/B iRule selects the pool by the pool selected for the request and the host and path of the request
+		if vs.Spec.Host == "" {
+			log.Warningf("Ignoring alternateBackends of pool %v in VirtualServer %v/%v without host",
+				pl.Service, vs.Namespace, vs.Name)
+			continue
+		}
+		if pl.PathType == PathTypeRegex || hasRegexMatch(pl) {
+			log.Warningf("Ignoring alternateBackends of pool %v with regex path or match in VirtualServer %v/%v",
+				pl.Service, vs.Namespace, vs.Name)
+			continue
+		}
+		abDeployment = true
+		path := pl.Path
+		if path == "/" {
+			path = ""
+		}
+		poolNames := []string{ctlr.framePoolName(vs.Namespace, pl, vs.Spec.Host)}
+		key := poolNames[0] + "|" + strings.ToLower(vs.Spec.Host) + path
+
+		weights := []int{getPoolWeight(pl.Weight)}
+		for _, altPool := range getAlternateBackendPools(pl) {
+			poolNames = append(poolNames, ctlr.framePoolName(vs.Namespace, altPool, vs.Spec.Host))
+			weights = append(weights, getPoolWeight(altPool.Weight))
+		}
+		updateDataGroupForAB(dgMap, dgName, partition, vs.Namespace, key, poolNames, weights)
+	}
+	return abDeployment
+}
+
+// updateDataGroupForRegexPaths updates the data group with the pools of the regex paths of the VirtualServer,
+// keyed by host and the index of the pool so that the regex paths are matched in the order of the pools
+func (ctlr *Controller) updateDataGroupForRegexPaths(
+	vs *cisapiv1.VirtualServer,
+	dgName string,
+	partition string,
+	dgMap InternalDataGroupMap,
+) bool {
+	regexPaths := false
+	for plIndex, pl := range vs.Spec.Pools {
+		// The regex paths of pools with regex match conditions are matched by the regex match iRule
+		if pl.PathType != PathTypeRegex || pl.Service == "" || hasRegexMatch(pl) {
+			continue
+		}
+		// The regex path iRule selects the pool by the host of the request
+		if vs.Spec.Host == "" || strings.HasPrefix(vs.Spec.Host, "*") {
+			log.Warningf("Ignoring regex path %v of VirtualServer %v/%v without host or with wildcard host",
+				pl.Path, vs.Namespace, vs.Name)
+			continue
+		}
+		if err := validateRegexPath(pl.Path); err != nil {
+			log.Warningf("Ignoring invalid regex path %v of VirtualServer %v/%v: %v", pl.Path, vs.Namespace, vs.Name, err)
+			continue
+		}
+		regexPaths = true
+		key := fmt.Sprintf("%s|%03d", strings.ToLower(vs.Spec.Host), plIndex)
+		poolName := ctlr.framePoolName(vs.Namespace, pl, vs.Spec.Host)
+		updateDataGroup(dgMap, dgName, partition, vs.Namespace, key, poolName+" "+pl.Path, DataGroupType)
+	}
+	return regexPaths
+}
+
+// validateRegexPath checks that the regex path is valid and has the same meaning for the Tcl regular
+// expressions (ARE) of the regex path iRule, so that it can be validated with the Go regular expressions.
+// Escapes and flags which are missing or differ in ARE are rejected, e.g. \b is a backspace in ARE.
+func validateRegexPath(path string) error {
+	if _, err := regexp.Compile(path); err != nil {
+		return err
+	}
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+			if i < len(path) && strings.IndexByte("bBzQEpPC", path[i]) != -1 {
+				return fmt.Errorf("escape \\%c is not supported by BIG-IP regular expressions", path[i])
+			}
+		case '(':
+			if strings.HasPrefix(path[i:], "(?") && !strings.HasPrefix(path[i:], "(?:") &&
+				!(i == 0 && strings.HasPrefix(path, "(?i)")) {
+				return fmt.Errorf("only (?: groups and a leading (?i) flag are supported by BIG-IP regular expressions")
+			}
+		}
+	}
+	return nil
+}
+
+// hasRegexMatch checks if a header, cookie or query parameter match of the pool has the regex operand,
+// the pools with regex match conditions are matched by the regex match iRule instead of the policy
+func hasRegexMatch(pl cisapiv1.Pool) bool {
+	if pl.Match == nil {
+		return false
+	}
+	for _, matches := range [][]cisapiv1.HTTPMatch{pl.Match.Headers, pl.Match.Cookies, pl.Match.QueryParameters} {
+		for _, match := range matches {
+			if match.Operand == MatchOperandRegex {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// updateDataGroupForRegexMatches updates the data group with the pools with regex match conditions of the
+// VirtualServer, keyed by host and the index of the pool so that they are matched in the order of the pools.
+// The value is the pool name followed by its path and conditions, encoded as a base64 Tcl list.
+func (ctlr *Controller) updateDataGroupForRegexMatches(
+	vs *cisapiv1.VirtualServer,
+	dgName string,
+	partition string,
+	allowSourceRange []string,
+	dgMap InternalDataGroupMap,
+) bool {
+	regexMatches := false
+	for plIndex, pl := range vs.Spec.Pools {
+		if !hasRegexMatch(pl) || pl.Service == "" {
+			continue
+		}
+		// The regex match iRule selects the pool by the host of the request
+		if vs.Spec.Host == "" || strings.HasPrefix(vs.Spec.Host, "*") {
+			log.Warningf("Ignoring regex match of pool %v of VirtualServer %v/%v without host or with wildcard host",
+				pl.Service, vs.Namespace, vs.Name)
+			continue
+		}
+		conditions, err := getRegexMatchConditions(pl, allowSourceRange)
+		if err != nil {
+			log.Warningf("Ignoring invalid regex match of pool %v of VirtualServer %v/%v: %v",
+				pl.Service, vs.Namespace, vs.Name, err)
+			continue
+		}
+		regexMatches = true
+		key := fmt.Sprintf("%s|%03d", strings.ToLower(vs.Spec.Host), plIndex)
+		poolName := ctlr.framePoolName(vs.Namespace, pl, vs.Spec.Host)
+		updateDataGroup(dgMap, dgName, partition, vs.Namespace, key,
+			poolName+" "+base64.StdEncoding.EncodeToString([]byte(conditions)), DataGroupType)
+	}
+	return regexMatches
+}
+
+// getRegexMatchConditions returns the Tcl list of the path type, the path and the conditions of a pool matched by
+// the regex match iRule. Each condition is a list of its kind, name, operand, case insensitivity and values.
+func getRegexMatchConditions(pl cisapiv1.Pool, allowSourceRange []string) (string, error) {
+	pathType := pl.PathType
+	if pathType == "" {
+		pathType = PathTypePrefix
+	}
+	if pathType == PathTypeRegex {
+		if err := validateRegexPath(pl.Path); err != nil {
+			return "", err
+		}
+	}
+	list := []string{tclListElement(pathType), tclListElement(pl.Path)}
+	appendCondition := func(kind, name, operand string, nocase bool, values []string) {
+		fields := []string{kind, name, operand, "0"}
+		if nocase {
+			fields[3] = "1"
+		}
+		fields = append(fields, values...)
+		for i := range fields {
+			fields[i] = tclListElement(fields[i])
+		}
+		list = append(list, tclListElement(strings.Join(fields, " ")))
+	}
+	kinds := []string{"header", "cookie", "query"}
+	for i, matches := range [][]cisapiv1.HTTPMatch{pl.Match.Headers, pl.Match.Cookies, pl.Match.QueryParameters} {
+		kind := kinds[i]
+		for _, match := range matches {
+			operand := match.Operand
+			if operand == "" {
+				operand = "equals"
+			}
+			values := match.Values
+			if operand == MatchOperandRegex {
+				for _, value := range values {
+					if err := validateRegexPath(value); err != nil {
+						return "", fmt.Errorf("%v %v: %v", kind, match.Name, err)
+					}
+				}
+			} else if !match.CaseSensitive {
+				values = nil
+				for _, value := range match.Values {
+					values = append(values, strings.ToLower(value))
+				}
+			}
+			appendCondition(kind, match.Name, operand, !match.CaseSensitive, values)
+		}
+	}
+	if len(pl.Match.Methods) > 0 {
+		var methods []string
+		for _, method := range pl.Match.Methods {
+			methods = append(methods, strings.ToUpper(method))
+		}
+		appendCondition("method", "", "equals", false, methods)
+	}
+	if len(pl.Match.SourceAddresses) > 0 {
+		appendCondition("source", "", "address", false, pl.Match.SourceAddresses)
+	}
+	if len(allowSourceRange) > 0 {
+		appendCondition("source", "", "address", false, allowSourceRange)
+	}
+	return strings.Join(list, " "), nil
+}
+
+// tclListElement quotes the string as an element of a Tcl list, escaping the characters with a special meaning
+func tclListElement(s string) string {
+	if s == "" {
+		return "{}"
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case ' ', ';', '"', '$', '[', ']', '{', '}', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// updateDataGroupForDirectResponse updates the data group with the direct responses of the pools of the
+// VirtualServer, keyed by the value set by the policy rules of the pools
+func (ctlr *Controller) updateDataGroupForDirectResponse(
+	vs *cisapiv1.VirtualServer,
+	dgName string,
+	partition string,
+	dgMap InternalDataGroupMap,
+) bool {
+	directResponse := false
+	for plIndex, pl := range vs.Spec.Pools {
+		if pl.DirectResponse == nil || pl.Redirect != nil {
+			continue
+		}
+		directResponse = true
+		contentType := pl.DirectResponse.ContentType
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+		// The content type and body are encoded as they may contain spaces
+		updateDataGroup(dgMap, dgName, partition, vs.Namespace, getDirectResponseKey(vs, plIndex),
+			fmt.Sprintf("%d %s %s", pl.DirectResponse.Status,
+				base64.StdEncoding.EncodeToString([]byte(contentType)),
+				base64.StdEncoding.EncodeToString([]byte(pl.DirectResponse.Body))),
+			DataGroupType)
+	}
+	return directResponse
+}
+
+// updateDataGroupForAB updates the data group with the pools selected for the key by their weights
+func updateDataGroupForAB(
+	dgMap InternalDataGroupMap,
+	dgName string,
+	partition string,
+	namespace string,
+	key string,
+	poolNames []string,
+	weights []int,
+) {
+	weightTotal := 0
+	for _, weight := range weights {
+		weightTotal = weightTotal + weight
+	}
+
 	if weightTotal == 0 {
 		// If all services have 0 weight, openshift requires a 503 to be returned
 		// (see https://docs.openshift.com/container-platform/3.6/architecture
@@ -1111,19 +1889,12 @@ func (ctlr *Controller) updateDataGroupForABRoute(
 		// service is listed first, but the list must be in ascending order.
 		var entries []string
 		runningWeightTotal := 0
-		for _, be := range backends {
-			if be.Weight == 0 {
+		for i, poolName := range poolNames {
+			if weights[i] == 0 {
 				continue
 			}
-			runningWeightTotal = runningWeightTotal + be.Weight
+			runningWeightTotal = runningWeightTotal + weights[i]
 			weightedSliceThreshold := float64(runningWeightTotal) / float64(weightTotal)
-			poolName := formatPoolName(
-				route.Namespace,
-				be.Name,
-				port,
-				"",
-				"",
-			)
 			entry := fmt.Sprintf("%s,%4.3f", poolName, weightedSliceThreshold)
 			entries = append(entries, entry)
 		}
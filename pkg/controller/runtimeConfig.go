@@ -0,0 +1,105 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"fmt"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UpdateNamespaces changes the namespaces watched by the controller.
+// Namespaces are added and removed through the resource queue, the same way
+// as namespaces selected with the namespace label.
+func (ctlr *Controller) UpdateNamespaces(namespaces []string) error {
+	if ctlr.namespaceLabel != "" {
+		return fmt.Errorf("namespaces are selected with the namespace label %v", ctlr.namespaceLabel)
+	}
+	updated := make(map[string]bool)
+	for _, ns := range namespaces {
+		updated[ns] = true
+	}
+
+	ctlr.namespacesMutex.Lock()
+	if ctlr.namespaces[""] {
+		ctlr.namespacesMutex.Unlock()
+		if len(updated) == 0 {
+			return nil
+		}
+		return fmt.Errorf("cannot watch specific namespaces when already watching all")
+	}
+	if len(updated) == 0 {
+		ctlr.namespacesMutex.Unlock()
+		return fmt.Errorf("cannot watch all namespaces when already watching specific ones")
+	}
+	var added, removed []string
+	for ns := range updated {
+		if !ctlr.namespaces[ns] {
+			added = append(added, ns)
+		}
+	}
+	for ns := range ctlr.namespaces {
+		if !updated[ns] {
+			removed = append(removed, ns)
+		}
+	}
+	ctlr.namespacesMutex.Unlock()
+
+	for _, ns := range added {
+		log.Infof("Adding Namespace: '%v' to CIS scope", ns)
+		ctlr.enqueueNamespace(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+	}
+	for _, ns := range removed {
+		log.Infof("Removing Namespace: '%v' from CIS scope", ns)
+		ctlr.enqueueDeletedNamespace(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+	}
+	return nil
+}
+
+// UpdateDefaultRouteDomain changes the default route domain of the partitions.
+// The last declaration is posted again with the route domain, later declarations carry it as well.
+func (ctlr *Controller) UpdateDefaultRouteDomain(routeDomain int) {
+	ctlr.settingsMutex.Lock()
+	ctlr.defaultRouteDomain = routeDomain
+	ctlr.settingsMutex.Unlock()
+	ctlr.Agent.repostWithDefaultRouteDomain(routeDomain)
+}
+
+func (ctlr *Controller) getDefaultRouteDomain() int {
+	ctlr.settingsMutex.RLock()
+	defer ctlr.settingsMutex.RUnlock()
+	return ctlr.defaultRouteDomain
+}
+
+// repostWithDefaultRouteDomain posts the last config request with the default route domain
+func (agent *Agent) repostWithDefaultRouteDomain(routeDomain int) {
+	agent.declUpdate.Lock()
+	lastConfig := agent.lastConfig
+	agent.declUpdate.Unlock()
+	if lastConfig == nil || lastConfig.defaultRouteDomain == routeDomain {
+		return
+	}
+	config := *lastConfig
+	config.defaultRouteDomain = routeDomain
+	// A pending config request is newer than the last config and carries the route domain
+	select {
+	case agent.postChan <- config:
+	default:
+	}
+}
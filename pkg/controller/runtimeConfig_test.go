@@ -0,0 +1,54 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var _ = Describe("Runtime Config Tests", func() {
+	var mockCtlr *mockController
+
+	BeforeEach(func() {
+		mockCtlr = newMockController()
+		mockCtlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
+			workqueue.DefaultControllerRateLimiter(), "custom-resource-controller")
+		mockCtlr.namespaces = map[string]bool{"ns1": true, "ns2": true}
+		mockCtlr.Agent = &Agent{postChan: make(chan ResourceConfigRequest, 1)}
+	})
+
+	AfterEach(func() {
+		mockCtlr.resourceQueue.ShutDown()
+	})
+
+	It("Enqueues the added and removed namespaces", func() {
+		Expect(mockCtlr.UpdateNamespaces([]string{"ns2", "ns3"})).To(Succeed())
+		Expect(mockCtlr.resourceQueue.Len()).To(Equal(2))
+		events := make(map[string]string)
+		for i := 0; i < 2; i++ {
+			key, _ := mockCtlr.resourceQueue.Get()
+			rKey := key.(*rqKey)
+			Expect(rKey.kind).To(Equal(Namespace))
+			events[rKey.rscName] = rKey.event
+		}
+		Expect(events).To(Equal(map[string]string{"ns3": Create, "ns1": Delete}))
+
+		Expect(mockCtlr.UpdateNamespaces(nil)).ToNot(Succeed(), "Watching all namespaces at runtime")
+		mockCtlr.namespaceLabel = "cis=true"
+		Expect(mockCtlr.UpdateNamespaces([]string{"ns1"})).ToNot(Succeed(), "Namespaces changed with namespace label")
+	})
+
+	It("Posts the last config with the updated default route domain", func() {
+		mockCtlr.UpdateDefaultRouteDomain(2)
+		Expect(mockCtlr.getDefaultRouteDomain()).To(Equal(2))
+		Expect(mockCtlr.Agent.postChan).To(BeEmpty(), "Config posted before any config request")
+
+		mockCtlr.Agent.lastConfig = &ResourceConfigRequest{reqId: 4, defaultRouteDomain: 2}
+		mockCtlr.UpdateDefaultRouteDomain(5)
+		Expect(mockCtlr.getDefaultRouteDomain()).To(Equal(5))
+		var config ResourceConfigRequest
+		Expect(mockCtlr.Agent.postChan).To(Receive(&config))
+		Expect(config.reqId).To(Equal(4))
+		Expect(config.defaultRouteDomain).To(Equal(5))
+	})
+})
@@ -0,0 +1,296 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// The snapshot ConfigMap holds the hash of each tenant declaration in data
+	// and the gzipped declaration in binaryData
+	snapshotHashSuffix = ".sha256"
+	snapshotDeclSuffix = ".json.gz"
+	// The snapshot of an agent is ignored unless it was taken from the same BIG-IP URL and partition
+	snapshotURLKey       = "bigip-url"
+	snapshotPartitionKey = "partition"
+	// snapshotSaveDelay coalesces the tenants applied together into a single snapshot update
+	snapshotSaveDelay = timeoutSmall
+)
+
+// declarationHash returns the hash of a JSON encoded tenant declaration
+func declarationHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SetDeclarationSnapshot sets the tenant declarations applied before the restart and starts saving
+// the applied tenant declarations with save
+func (agent *Agent) SetDeclarationSnapshot(hashes map[string]string, decls map[string]as3Tenant,
+	save func(map[string]as3Tenant)) {
+	agent.declUpdate.Lock()
+	defer agent.declUpdate.Unlock()
+	agent.snapshot = declarationSnapshot{
+		hashes: hashes,
+		decls:  decls,
+		save:   save,
+		dirty:  make(chan struct{}, 1),
+	}
+	go agent.snapshotWorker(agent.snapshot.dirty)
+}
+
+// matches checks that the declaration is the one applied before the restart. Each tenant is checked once,
+// later declarations of the tenant are compared with cachedTenantDeclMap.
+func (snapshot *declarationSnapshot) matches(tenant string, decl interface{}) bool {
+	hash, ok := snapshot.hashes[tenant]
+	if !ok {
+		return false
+	}
+	delete(snapshot.hashes, tenant)
+	data, err := json.Marshal(decl)
+	return err == nil && declarationHash(data) == hash
+}
+
+// applied records a successfully posted tenant declaration
+func (snapshot *declarationSnapshot) applied(tenant string) {
+	delete(snapshot.decls, tenant)
+	if snapshot.dirty == nil {
+		return
+	}
+	select {
+	case snapshot.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// snapshotWorker saves the applied tenant declarations whenever a tenant is posted
+func (agent *Agent) snapshotWorker(dirty chan struct{}) {
+	for range dirty {
+		<-time.After(snapshotSaveDelay)
+		agent.declUpdate.Lock()
+		decls := make(map[string]as3Tenant, len(agent.cachedTenantDeclMap))
+		for tenant, decl := range agent.cachedTenantDeclMap {
+			decls[tenant] = decl
+		}
+		save := agent.snapshot.save
+		agent.declUpdate.Unlock()
+		save(decls)
+	}
+}
+
+// snapshotTarget returns the BIG-IP URL and partition of the tenant declarations of the agent
+func (agent *Agent) snapshotTarget() (string, string) {
+	var url string
+	if agent.PostManager != nil {
+		url = agent.getBIGIPURL()
+	}
+	return url, agent.Partition
+}
+
+// setupDeclarationSnapshot loads the snapshot of the tenant declarations into the agents, which save
+// the declarations they apply to the snapshot
+func (ctlr *Controller) setupDeclarationSnapshot() {
+	if ctlr.snapshotCfgMap == "" {
+		return
+	}
+	// The keys of the tenants of the BIG-IP targets are prefixed with the name of the target
+	agents := map[string]*Agent{"": ctlr.Agent}
+	for name, target := range ctlr.bigIPTargets {
+		agents[name+"."] = target.Agent
+	}
+	cm, err := ctlr.getSnapshotCfgMap()
+	if err != nil && !errors.IsNotFound(err) {
+		log.Warningf("[AS3] Unable to read declaration snapshot ConfigMap %v: %v", ctlr.snapshotCfgMap, err)
+	}
+	for prefix, agent := range agents {
+		prefix, agent := prefix, agent
+		url, partition := agent.snapshotTarget()
+		hashes, decls := readDeclarationSnapshot(cm, prefix, url, partition)
+		agent.SetDeclarationSnapshot(hashes, decls, func(applied map[string]as3Tenant) {
+			url, partition := agent.snapshotTarget()
+			ctlr.saveDeclarationSnapshot(prefix, url, partition, applied)
+		})
+	}
+	if cm != nil {
+		log.Infof("[AS3] Loaded declaration snapshot from ConfigMap %v", ctlr.snapshotCfgMap)
+	}
+}
+
+// snapshotTenant returns the tenant of a snapshot key with the prefix of an agent
+func snapshotTenant(key, prefix, suffix string) (string, bool) {
+	if !strings.HasPrefix(key, prefix) || !strings.HasSuffix(key, suffix) {
+		return "", false
+	}
+	tenant := strings.TrimSuffix(strings.TrimPrefix(key, prefix), suffix)
+	// Tenant names do not contain dots, unlike the prefixes of the BIG-IP targets
+	return tenant, tenant != "" && !strings.Contains(tenant, ".")
+}
+
+// readDeclarationSnapshot returns the hashes and declarations of the tenants of an agent in the snapshot ConfigMap,
+// which are empty when the snapshot was taken from another BIG-IP URL or partition
+func readDeclarationSnapshot(cm *v1.ConfigMap, prefix, url, partition string) (map[string]string, map[string]as3Tenant) {
+	hashes := make(map[string]string)
+	decls := make(map[string]as3Tenant)
+	if cm == nil {
+		return hashes, decls
+	}
+	if cm.Data[prefix+snapshotURLKey] != url || cm.Data[prefix+snapshotPartitionKey] != partition {
+		log.Warningf("[AS3] Ignoring declaration snapshot of BIG-IP %v partition %v, taken from BIG-IP %v partition %v",
+			url, partition, cm.Data[prefix+snapshotURLKey], cm.Data[prefix+snapshotPartitionKey])
+		return hashes, decls
+	}
+	for key, hash := range cm.Data {
+		tenant, ok := snapshotTenant(key, prefix, snapshotHashSuffix)
+		if !ok {
+			continue
+		}
+		data, err := gunzip(cm.BinaryData[prefix+tenant+snapshotDeclSuffix])
+		if err == nil && declarationHash(data) != hash {
+			err = fmt.Errorf("hash mismatch")
+		}
+		var decl as3Tenant
+		if err == nil {
+			err = json.Unmarshal(data, &decl)
+		}
+		if err != nil {
+			log.Warningf("[AS3] Ignoring snapshot of tenant %v: %v", tenant, err)
+			continue
+		}
+		hashes[tenant] = hash
+		decls[tenant] = decl
+	}
+	return hashes, decls
+}
+
+// saveDeclarationSnapshot replaces the tenant declarations of an agent in the snapshot ConfigMap
+func (ctlr *Controller) saveDeclarationSnapshot(prefix, url, partition string, decls map[string]as3Tenant) {
+	data := map[string]string{
+		prefix + snapshotURLKey:       url,
+		prefix + snapshotPartitionKey: partition,
+	}
+	binaryData := make(map[string][]byte, len(decls))
+	for tenant, decl := range decls {
+		declJSON, err := json.Marshal(decl)
+		if err != nil {
+			log.Warningf("[AS3] Unable to encode snapshot of tenant %v: %v", tenant, err)
+			continue
+		}
+		compressed, err := gzipData(declJSON)
+		if err != nil {
+			log.Warningf("[AS3] Unable to compress snapshot of tenant %v: %v", tenant, err)
+			continue
+		}
+		data[prefix+tenant+snapshotHashSuffix] = declarationHash(declJSON)
+		binaryData[prefix+tenant+snapshotDeclSuffix] = compressed
+	}
+	namespace, name, _ := splitNamespacedName(ctlr.snapshotCfgMap)
+	// The agents of the BIG-IP targets update their tenants in the same ConfigMap
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := ctlr.getSnapshotCfgMap()
+		if errors.IsNotFound(err) {
+			cm = &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Data:       data,
+				BinaryData: binaryData,
+			}
+			_, err = ctlr.kubeClient.CoreV1().ConfigMaps(namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		if cm.BinaryData == nil {
+			cm.BinaryData = make(map[string][]byte)
+		}
+		for key := range cm.Data {
+			if _, ok := snapshotTenant(key, prefix, snapshotHashSuffix); ok {
+				delete(cm.Data, key)
+			}
+		}
+		for key := range cm.BinaryData {
+			if _, ok := snapshotTenant(key, prefix, snapshotDeclSuffix); ok {
+				delete(cm.BinaryData, key)
+			}
+		}
+		for key, value := range data {
+			cm.Data[key] = value
+		}
+		for key, value := range binaryData {
+			cm.BinaryData[key] = value
+		}
+		_, err = ctlr.kubeClient.CoreV1().ConfigMaps(namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		log.Warningf("[AS3] Unable to save declaration snapshot to ConfigMap %v: %v", ctlr.snapshotCfgMap, err)
+	}
+}
+
+func (ctlr *Controller) getSnapshotCfgMap() (*v1.ConfigMap, error) {
+	namespace, name, err := splitNamespacedName(ctlr.snapshotCfgMap)
+	if err != nil {
+		return nil, err
+	}
+	return ctlr.kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func splitNamespacedName(namespacedName string) (string, string, error) {
+	namespaceName := strings.Split(namespacedName, "/")
+	if len(namespaceName) != 2 {
+		return "", "", fmt.Errorf("invalid ConfigMap %v, expected namespace/name", namespacedName)
+	}
+	return namespaceName[0], namespaceName[1], nil
+}
+
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return ioutil.ReadAll(zr)
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var _ = Describe("Declaration Snapshot Tests", func() {
+	var mockCtlr *mockController
+
+	newTenant := func(vsName string) as3Tenant {
+		return as3Tenant{
+			"class": "Tenant",
+			as3SharedApplication: as3Application{
+				"class":    "Application",
+				"template": "shared",
+				vsName:     &as3Service{Class: "Service_HTTP"},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		mockCtlr = newMockController()
+		mockCtlr.kubeClient = k8sfake.NewSimpleClientset()
+		mockCtlr.snapshotCfgMap = "default/snapshot"
+		mockCtlr.Agent = newMockAgent(nil)
+	})
+
+	It("Saves and loads the tenant declarations of each agent", func() {
+		mockCtlr.saveDeclarationSnapshot("", "https://bigip1", "test",
+			map[string]as3Tenant{"test": newTenant("vs"), "test2": newTenant("vs2")})
+		mockCtlr.saveDeclarationSnapshot("bigip2.", "https://bigip2", "test", map[string]as3Tenant{"test": newTenant("vs3")})
+
+		cm, err := mockCtlr.getSnapshotCfgMap()
+		Expect(err).To(BeNil())
+		hashes, decls := readDeclarationSnapshot(cm, "", "https://bigip1", "test")
+		Expect(hashes).To(HaveLen(2))
+		data, _ := json.Marshal(newTenant("vs"))
+		Expect(hashes["test"]).To(Equal(declarationHash(data)))
+		Expect(countVirtualServers(decls["test"])).To(Equal(1))
+		hashes, _ = readDeclarationSnapshot(cm, "bigip2.", "https://bigip2", "test")
+		Expect(hashes).To(HaveLen(1))
+		Expect(hashes["test"]).NotTo(Equal(declarationHash(data)))
+
+		// Snapshots of another BIG-IP or partition are ignored
+		hashes, decls = readDeclarationSnapshot(cm, "", "https://bigip3", "test")
+		Expect(hashes).To(BeEmpty())
+		Expect(decls).To(BeEmpty())
+		hashes, _ = readDeclarationSnapshot(cm, "", "https://bigip1", "test2")
+		Expect(hashes).To(BeEmpty())
+
+		// Tenants no longer applied are removed, keeping the tenants of the other agents
+		mockCtlr.saveDeclarationSnapshot("", "https://bigip1", "test", map[string]as3Tenant{"test": newTenant("vs")})
+		cm, _ = mockCtlr.getSnapshotCfgMap()
+		hashes, _ = readDeclarationSnapshot(cm, "", "https://bigip1", "test")
+		Expect(hashes).To(HaveLen(1))
+		hashes, _ = readDeclarationSnapshot(cm, "bigip2.", "https://bigip2", "test")
+		Expect(hashes).To(HaveLen(1))
+
+		// Corrupted declarations are ignored
+		cm.BinaryData["test"+snapshotDeclSuffix] = []byte("invalid")
+		_, _ = mockCtlr.kubeClient.CoreV1().ConfigMaps("default").Update(context.TODO(), cm, metav1.UpdateOptions{})
+		cm, _ = mockCtlr.getSnapshotCfgMap()
+		hashes, _ = readDeclarationSnapshot(cm, "", "https://bigip1", "test")
+		Expect(hashes).To(BeEmpty())
+	})
+
+	It("Skips the tenants unchanged since restart once", func() {
+		data, _ := json.Marshal(newTenant("vs"))
+		snapshot := declarationSnapshot{hashes: map[string]string{"test": declarationHash(data), "test2": "changed"}}
+		Expect(snapshot.matches("test", newTenant("vs"))).To(BeTrue())
+		Expect(snapshot.matches("test", newTenant("vs"))).To(BeFalse())
+		Expect(snapshot.matches("test2", newTenant("vs"))).To(BeFalse())
+		Expect(snapshot.matches("test3", newTenant("vs"))).To(BeFalse())
+	})
+
+	It("Guards deletions against the snapshot after restart", func() {
+		agent := mockCtlr.Agent
+		agent.cachedTenantDeclMap = make(map[string]as3Tenant)
+		agent.tenantPriorityMap = make(map[string]int)
+		agent.deletionGuard = deletionGuard{
+			DeletionGuardParams: DeletionGuardParams{MaxDeleteCount: 0, MaxDeletePercent: 50},
+			heldTenants:         make(map[string]struct{}),
+		}
+		var restored as3Tenant
+		data, _ := json.Marshal(newTenant("vs"))
+		Expect(json.Unmarshal(data, &restored)).To(Succeed())
+		agent.snapshot = declarationSnapshot{decls: map[string]as3Tenant{"test": restored}}
+		agent.incomingTenantDeclMap = map[string]as3Tenant{"test": {"class": "Tenant"}}
+		agent.holdMassDeletions(ResourceConfigRequest{})
+		Expect(agent.incomingTenantDeclMap).NotTo(HaveKey("test"))
+	})
+
+	It("Holds the config until the keys requeued during init are processed", func() {
+		mockCtlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
+			workqueue.DefaultControllerRateLimiter(), "snapshot-test")
+		defer mockCtlr.resourceQueue.ShutDown()
+		mockCtlr.initState = true
+		mockCtlr.initialSvcCount = 1
+		mockCtlr.resourceQueue.Add(&rqKey{namespace: "default", kind: VirtualServer, rscName: "vs"})
+		mockCtlr.processResources()
+		Expect(mockCtlr.initRequeued).To(HaveLen(1))
+		mockCtlr.postResourceConfig()
+		Expect(mockCtlr.Agent.postChan).To(BeEmpty())
+	})
+})
@@ -0,0 +1,198 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxTaskPolls is the number of times the status of an accepted declaration is polled
+const maxTaskPolls = 10
+
+// dispatchTenantUpdates hands the declarations of the tenants to their tenant workers,
+// settled is done for each tenant once its declaration is posted or superseded
+func (agent *Agent) dispatchTenantUpdates(tenants []string, decls map[string]as3Tenant, rsConfig ResourceConfigRequest,
+	settled *sync.WaitGroup) {
+	for _, tenant := range tenants {
+		settled.Add(1)
+		agent.getTenantWorker(tenant).enqueue(tenantUpdate{
+			decl:    decls[tenant],
+			reqId:   rsConfig.reqId,
+			spanCtx: rsConfig.spanCtx,
+			settled: settled,
+		})
+	}
+}
+
+// getTenantWorker returns the worker of the tenant, starting it with the first declaration of the tenant
+func (agent *Agent) getTenantWorker(tenant string) *tenantWorker {
+	if agent.tenantWorkers == nil {
+		agent.tenantWorkers = make(map[string]*tenantWorker)
+	}
+	worker, ok := agent.tenantWorkers[tenant]
+	if !ok {
+		worker = &tenantWorker{
+			tenant:     tenant,
+			updateChan: make(chan tenantUpdate, 1),
+		}
+		agent.tenantWorkers[tenant] = worker
+		go agent.tenantWorkerLoop(worker)
+	}
+	return worker
+}
+
+// enqueue hands the declaration to the tenant worker, replacing the declaration which is not posted yet
+func (worker *tenantWorker) enqueue(update tenantUpdate) {
+	select {
+	case worker.updateChan <- update:
+	case stale := <-worker.updateChan:
+		stale.settle()
+		worker.updateChan <- update
+	}
+}
+
+// settle marks the declaration as posted or superseded
+func (update *tenantUpdate) settle() {
+	if update.settled != nil {
+		update.settled.Done()
+		update.settled = nil
+	}
+}
+
+// tenantWorkerLoop posts the declarations of a tenant until the agent is stopped
+func (agent *Agent) tenantWorkerLoop(worker *tenantWorker) {
+	update, ok := <-worker.updateChan
+	for ok {
+		update, ok = agent.postTenantUpdate(worker, update)
+	}
+}
+
+// postTenantUpdate posts the declaration of the tenant and retries it until it succeeds or a newer
+// declaration of the tenant arrives, and returns the next declaration of the tenant
+func (agent *Agent) postTenantUpdate(worker *tenantWorker, update tenantUpdate) (tenantUpdate, bool) {
+	tenant := worker.tenant
+	retry := false
+	// Declarations replaced or paused after they were handed to the worker are not posted
+	if agent.isPendingTenantDecl(tenant, update.decl) {
+		cfg := agent.newTenantConfig(tenant, update.decl, update.reqId, update.spanCtx)
+		agent.publishConfig(cfg)
+		retry = agent.completeTenantPost(cfg, update.decl)
+	}
+	update.settle()
+
+	retryBackoff := newBackoff(timeoutMedium, agent.RetryMaxInterval)
+	for retry {
+		interval := retryBackoff.next()
+		tenantLogger(tenant, 0).Debugf("[AS3] Posting failed tenant %v configuration in %v", tenant, interval)
+		select {
+		case next, ok := <-worker.updateChan:
+			// A newer declaration of the tenant replaces the failed one
+			return next, ok
+		case <-time.After(interval):
+		}
+		decl, ok := agent.getRetryTenantDecl(tenant)
+		if !ok {
+			// The failed declaration is reverted, paused or replaced by a newer declaration
+			break
+		}
+		cfg := agent.newTenantConfig(tenant, decl, 0, trace.SpanContext{})
+		agent.postConfig(cfg)
+		retry = agent.completeTenantPost(cfg, decl)
+		agent.notifyRscStatusHandler(0, false)
+	}
+	next, ok := <-worker.updateChan
+	return next, ok
+}
+
+// newTenantConfig creates the AS3 declaration of a single tenant
+func (agent *Agent) newTenantConfig(tenant string, decl as3Tenant, reqId int, spanCtx trace.SpanContext) *agentConfig {
+	return &agentConfig{
+		data:              string(agent.createAS3Declaration(map[string]as3Tenant{tenant: decl})),
+		as3APIURL:         agent.getAS3APIURL([]string{tenant}),
+		id:                reqId,
+		tenants:           []string{tenant},
+		spanCtx:           spanCtx,
+		tenantResponseMap: tenantResponses{tenant: tenantResponse{}},
+	}
+}
+
+// completeTenantPost polls for the status of an accepted declaration and records the response of the tenant.
+// It returns true if the declaration failed and is to be posted again.
+func (agent *Agent) completeTenantPost(cfg *agentConfig, decl as3Tenant) bool {
+	tenant := cfg.tenants[0]
+	pollBackoff := newBackoff(timeoutSmall, agent.RetryMaxInterval)
+	for polls := 0; cfg.tenantResponseMap[tenant].taskId != ""; polls++ {
+		if polls == maxTaskPolls {
+			// The declaration is posted again, which fails with 503 while the task is still in progress
+			tenantLogger(tenant, cfg.id).Warningf("[AS3] Status of task %v of tenant %v not available after %v polls",
+				cfg.tenantResponseMap[tenant].taskId, tenant, polls)
+			cfg.tenantResponseMap.update(http.StatusServiceUnavailable, "", tenant)
+			break
+		}
+		<-time.After(pollBackoff.next())
+		agent.getTenantConfigStatus(cfg.tenantResponseMap[tenant].taskId, cfg.tenantResponseMap)
+	}
+	resp := cfg.tenantResponseMap[tenant]
+
+	agent.declUpdate.Lock()
+	defer agent.declUpdate.Unlock()
+	if resp.agentResponseCode == http.StatusOK {
+		// update cachedTenantDeclMap with successfully posted declaration
+		agent.cachedTenantDeclMap[tenant] = decl
+		agent.snapshot.applied(tenant)
+	}
+	if pending, ok := agent.pendingTenantDeclMap[tenant]; ok && reflect.DeepEqual(pending, decl) {
+		delete(agent.pendingTenantDeclMap, tenant)
+	}
+	if resp.agentResponseCode != http.StatusOK && agent.tenantPause.isPaused(tenant) {
+		// Failed declarations of paused tenants are posted with the catch-up declaration
+		delete(agent.retryTenantDeclMap, tenant)
+		agent.tenantPause.catchUpPending = true
+	} else {
+		agent.updateRetryMap(tenant, resp, decl)
+	}
+	agent.updateDebugState()
+	_, retry := agent.retryTenantDeclMap[tenant]
+	return retry
+}
+
+// isPendingTenantDecl checks that the declaration is the latest declaration handed to the tenant worker
+func (agent *Agent) isPendingTenantDecl(tenant string, decl as3Tenant) bool {
+	agent.declUpdate.Lock()
+	defer agent.declUpdate.Unlock()
+	pending, ok := agent.pendingTenantDeclMap[tenant]
+	return ok && reflect.DeepEqual(pending, decl)
+}
+
+// getRetryTenantDecl returns the failed declaration of the tenant unless a newer declaration is pending
+func (agent *Agent) getRetryTenantDecl(tenant string) (as3Tenant, bool) {
+	agent.declUpdate.Lock()
+	defer agent.declUpdate.Unlock()
+	if _, ok := agent.pendingTenantDeclMap[tenant]; ok {
+		return nil, false
+	}
+	params, ok := agent.retryTenantDeclMap[tenant]
+	if !ok {
+		return nil, false
+	}
+	return params.as3Decl.(as3Tenant), true
+}
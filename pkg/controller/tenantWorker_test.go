@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tenant Worker Tests", func() {
+	var agent *Agent
+	var server *httptest.Server
+	var posts, active, maxActive int32
+
+	BeforeEach(func() {
+		posts, active, maxActive = 0, 0, 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&posts, 1)
+			current := atomic.AddInt32(&active, 1)
+			defer atomic.AddInt32(&active, -1)
+			for {
+				max := atomic.LoadInt32(&maxActive)
+				if current <= max || atomic.CompareAndSwapInt32(&maxActive, max, current) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			tenant := path.Base(r.URL.Path)
+			code := http.StatusOK
+			if tenant == "bad" {
+				code = http.StatusUnprocessableEntity
+			}
+			w.WriteHeader(code)
+			fmt.Fprintf(w, `{"results":[{"code":%d,"message":"none","tenant":"%s"}]}`, code, tenant)
+		}))
+		agent = newMockAgent(nil)
+		agent.PostManager = NewPostManager(PostParams{BIGIPURL: server.URL, PostConcurrency: 2})
+		agent.cachedTenantDeclMap = make(map[string]as3Tenant)
+		agent.retryTenantDeclMap = make(map[string]*tenantParams)
+		agent.pendingTenantDeclMap = make(map[string]as3Tenant)
+		agent.tenantPriorityMap = make(map[string]int)
+	})
+
+	AfterEach(func() {
+		for _, worker := range agent.tenantWorkers {
+			close(worker.updateChan)
+		}
+		server.Close()
+	})
+
+	dispatch := func(decls map[string]as3Tenant) {
+		var tenants []string
+		for tenant, decl := range decls {
+			tenants = append(tenants, tenant)
+			agent.pendingTenantDeclMap[tenant] = decl
+		}
+		var settled sync.WaitGroup
+		agent.dispatchTenantUpdates(tenants, decls, ResourceConfigRequest{reqId: 1}, &settled)
+		settled.Wait()
+	}
+
+	It("Posts the tenants in parallel without waiting for failed tenants", func() {
+		dispatch(map[string]as3Tenant{
+			"bad":   {"class": "Tenant"},
+			"test1": {"class": "Tenant"},
+			"test2": {"class": "Tenant"},
+		})
+		Expect(atomic.LoadInt32(&posts)).To(BeEquivalentTo(3))
+		Expect(atomic.LoadInt32(&maxActive)).To(BeEquivalentTo(2), "Post concurrency not limited")
+
+		agent.declUpdate.Lock()
+		defer agent.declUpdate.Unlock()
+		Expect(agent.cachedTenantDeclMap).To(HaveKey("test1"))
+		Expect(agent.cachedTenantDeclMap).To(HaveKey("test2"))
+		Expect(agent.cachedTenantDeclMap).NotTo(HaveKey("bad"))
+		Expect(agent.retryTenantDeclMap).To(HaveLen(1))
+		Expect(agent.retryTenantDeclMap).To(HaveKey("bad"))
+		Expect(agent.pendingTenantDeclMap).To(BeEmpty())
+	})
+
+	It("Skips declarations replaced before they are posted", func() {
+		stale := as3Tenant{"class": "Tenant", "label": "stale"}
+		agent.pendingTenantDeclMap["test1"] = as3Tenant{"class": "Tenant", "label": "latest"}
+		var settled sync.WaitGroup
+		agent.dispatchTenantUpdates([]string{"test1"}, map[string]as3Tenant{"test1": stale},
+			ResourceConfigRequest{reqId: 1}, &settled)
+		settled.Wait()
+		Expect(atomic.LoadInt32(&posts)).To(BeZero())
+
+		// Paused tenants are dropped from the pending declarations
+		agent.SetPausedTenants(false, map[string]bool{"test1": true})
+		Expect(agent.isPendingTenantDecl("test1", as3Tenant{"class": "Tenant", "label": "latest"})).To(BeFalse())
+	})
+
+	It("Compares the incoming declarations with the pending declarations", func() {
+		agent.cachedTenantDeclMap["test"] = as3Tenant{"class": "Tenant"}
+		agent.pendingTenantDeclMap["test"] = as3Tenant{"class": "Tenant", "label": "pending"}
+		agent.retryTenantDeclMap["test"] = &tenantParams{as3Decl: agent.pendingTenantDeclMap["test"]}
+		decl, ok := agent.getRetryTenantDecl("test")
+		Expect(ok).To(BeFalse(), "Failed declaration retried while a newer one is pending")
+		Expect(decl).To(BeNil())
+		delete(agent.pendingTenantDeclMap, "test")
+		_, ok = agent.getRetryTenantDecl("test")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("Coalesces the status notifications of the retried tenants", func() {
+		agent.respChan = make(chan resourceStatusMeta, 1)
+		agent.notifyRscStatusHandler(5, true)
+		done := make(chan struct{})
+		go func() {
+			agent.retryTenantDeclMap["bad"] = &tenantParams{}
+			agent.notifyRscStatusHandler(0, false)
+			close(done)
+		}()
+		Eventually(done).Should(BeClosed(), "Retry notification blocked on the status handler")
+		var meta resourceStatusMeta
+		Expect(agent.respChan).To(Receive(&meta))
+		Expect(meta.id).To(Equal(5), "Pending notification replaced")
+	})
+})
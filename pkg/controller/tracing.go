@@ -0,0 +1,75 @@
+/*-
+ * Copyright (c) 2016-2021, F5 Networks, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/controller"
+
+// Span attributes correlating the traces to resources, tenants and config requests
+const (
+	attrResourceKey = attribute.Key("cis.resource.key")
+	attrEvent       = attribute.Key("cis.resource.event")
+	attrTenant      = attribute.Key("cis.tenant")
+	attrRequestID   = attribute.Key("cis.request.id")
+	attrTaskID      = attribute.Key("cis.task.id")
+)
+
+// startSpan starts a span as a child of the parent span context.
+// Spans are dropped unless a tracer provider is registered with otel.SetTracerProvider.
+func startSpan(parent trace.SpanContext, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx := trace.ContextWithSpanContext(context.Background(), parent)
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the error, if any, on the span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// resourceKey identifies the resource of the key as namespace/kind/name
+func (rKey *rqKey) resourceKey() string {
+	return rKey.namespace + "/" + rKey.kind + "/" + rKey.rscName
+}
+
+// enqueueKey adds the key to the resource queue and records its span context,
+// so that the processing of the key is traced as part of the enqueue trace
+func (ctlr *Controller) enqueueKey(key *rqKey) {
+	_, span := startSpan(trace.SpanContext{}, "enqueue",
+		attrResourceKey.String(key.resourceKey()),
+		attrEvent.String(key.event),
+	)
+	key.spanCtx = span.SpanContext()
+	span.End()
+	ctlr.resourceQueue.Add(key)
+}
+
+func tenantsAttribute(tenants []string) attribute.KeyValue {
+	return attrTenant.String(strings.Join(tenants, ","))
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"net/http"
+
+	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v2/config/apis/cis/v1"
+	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/test"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var _ = Describe("Tracing Tests", func() {
+	var recorder *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+
+	AfterEach(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	})
+
+	spanAttributes := func(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+		attrs := make(map[attribute.Key]attribute.Value)
+		for _, attr := range span.Attributes() {
+			attrs[attr.Key] = attr.Value
+		}
+		return attrs
+	}
+
+	It("Traces the processing of a key as part of its enqueue trace", func() {
+		mockCtlr := newMockController()
+		mockCtlr.mode = OpenShiftMode
+		mockCtlr.resourceQueue = workqueue.NewNamedRateLimitingQueue(
+			workqueue.DefaultControllerRateLimiter(), "custom-resource-controller")
+		defer mockCtlr.resourceQueue.ShutDown()
+		mockCtlr.resources = NewResourceStore()
+
+		vs := test.NewVirtualServer("SampleVS", "default", cisapiv1.VirtualServerSpec{Host: "test.com"})
+		mockCtlr.enqueueVirtualServer(vs)
+		Expect(mockCtlr.processResources()).To(BeTrue())
+		Expect(mockCtlr.processSpanCtx.IsValid()).To(BeFalse(), "Span context of the processed key is not reset")
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		enqueue, process := spans[0], spans[1]
+		Expect(enqueue.Name()).To(Equal("enqueue"))
+		Expect(process.Name()).To(Equal("processResources"))
+		Expect(process.Parent().SpanID()).To(Equal(enqueue.SpanContext().SpanID()))
+		Expect(process.SpanContext().TraceID()).To(Equal(enqueue.SpanContext().TraceID()))
+		for _, span := range spans {
+			attrs := spanAttributes(span)
+			Expect(attrs[attrResourceKey].AsString()).To(Equal("default/VirtualServer/SampleVS"))
+			Expect(attrs[attrEvent].AsString()).To(Equal(Create))
+		}
+	})
+
+	It("Traces the AS3 post with its request id and tenants", func() {
+		mockPM := newMockPostManger()
+		mockPM.BIGIPURL = "bigip.com"
+		mockPM.setResponses([]responceCtx{{
+			tenant: "test",
+			status: http.StatusOK,
+		}}, http.MethodPost)
+
+		_, parent := startSpan(trace.SpanContext{}, "postResourceConfig", attrRequestID.Int(5))
+		parent.End()
+		mockPM.postConfig(&agentConfig{
+			data:              "{}",
+			as3APIURL:         mockPM.getAS3APIURL([]string{"test"}),
+			id:                5,
+			tenants:           []string{"test"},
+			spanCtx:           parent.SpanContext(),
+			tenantResponseMap: make(tenantResponses),
+		})
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		post := spans[1]
+		Expect(post.Name()).To(Equal("postConfig"))
+		Expect(post.Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+		attrs := spanAttributes(post)
+		Expect(attrs[attrRequestID].AsInt64()).To(BeEquivalentTo(5))
+		Expect(attrs[attrTenant].AsString()).To(Equal("test"))
+		Expect(attrs["http.status_code"].AsInt64()).To(BeEquivalentTo(http.StatusOK))
+	})
+})
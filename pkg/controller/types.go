@@ -21,6 +21,7 @@ import (
 	ficV1 "github.com/F5Networks/f5-ipam-controller/pkg/ipamapis/apis/fic/v1"
 	"net/http"
 	"sync"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -35,6 +36,7 @@ import (
 	apm "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/appmanager"
 	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/pollers"
 	"github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/writer"
+	"go.opentelemetry.io/otel/trace"
 	v1 "k8s.io/api/core/v1"
 	extClient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/labels"
@@ -78,6 +80,26 @@ type (
 		requestQueue           *requestQueue
 		namespaceLabel         string
 		ipamHostSpecEmpty      bool
+		pauseCfgMap            string
+		pause                  reconcilePause
+		// pauseInformer watches the pause ConfigMap and pauseNsInformer the paused annotation of the namespaces
+		pauseInformer   cache.SharedIndexInformer
+		pauseNsInformer cache.SharedIndexInformer
+		// deletionGuardInformer watches the deletion guard ConfigMap read by the agent
+		deletionGuardInformer cache.SharedIndexInformer
+		debugAPI              bool
+		debugMutex            sync.Mutex
+		debugState            controllerDebugState
+		// settingsMutex guards defaultRouteDomain, which can be changed at runtime
+		settingsMutex sync.RWMutex
+		// processSpanCtx is the span context of the key being processed by the worker
+		processSpanCtx trace.SpanContext
+		// bigIPTargets are the additional BIG-IPs selected with bigipRef, keyed by name
+		bigIPTargets map[string]*BigIPTarget
+		// snapshotCfgMap is the namespace/name of the ConfigMap holding the applied tenant declarations
+		snapshotCfgMap string
+		// initRequeued holds the keys requeued during init, config is not posted until they are processed
+		initRequeued map[*rqKey]struct{}
 		resourceContext
 	}
 	resourceContext struct {
@@ -93,6 +115,22 @@ type (
 		processedHostPath  *ProcessedHostPath
 	}
 
+	// reconcilePause holds the reconciliation pause state read from the pause ConfigMap
+	reconcilePause struct {
+		sync.Mutex
+		all        bool
+		partitions map[string]bool
+		namespaces map[string]bool
+		// pausedKeys holds each paused resource and the partitions holding its changes
+		pausedKeys map[string]*pausedResource
+	}
+
+	// pausedResource is a paused resource whose changes are held in its partitions until it is resumed
+	pausedResource struct {
+		key        *rqKey
+		partitions map[string]bool
+	}
+
 	// Params defines parameters
 	Params struct {
 		Config             *rest.Config
@@ -112,6 +150,23 @@ type (
 		Mode               ControllerMode
 		RouteSpecConfigmap string
 		RouteLabel         string
+		PauseConfigmap     string
+		DebugAPI           bool
+		BigIPTargets       []*BigIPTarget
+		// DeclarationSnapshotCfgMap is the namespace/name of the ConfigMap persisting the applied tenant declarations
+		DeclarationSnapshotCfgMap string
+	}
+
+	// BigIPTarget is an additional BIG-IP configured with the resources which reference it with bigipRef.
+	// The partition of its agent is reserved for the target.
+	BigIPTarget struct {
+		Name               string
+		Agent              *Agent
+		DefaultRouteDomain int
+		// lastRequest holds the resources of the last config posted to the target,
+		// their statuses are updated with the responses of the target
+		requestMutex sync.Mutex
+		lastRequest  requestMeta
 	}
 
 	// CRInformer defines the structure of Custom Resource Informer
@@ -154,6 +209,8 @@ type (
 		rscName   string
 		rsc       interface{}
 		event     string
+		// spanCtx is the span context of the enqueue of the key
+		spanCtx trace.SpanContext
 	}
 
 	metaData struct {
@@ -165,6 +222,8 @@ type (
 		hosts         []string
 		Protocol      string
 		httpTraffic   string
+		// defaultPoolVS is the VirtualServer whose defaultPool is the default pool of the virtual
+		defaultPoolVS string
 	}
 
 	// Virtual Server Key - unique server is Name + Port
@@ -195,9 +254,13 @@ type (
 		LogProfiles                []string              `json:"logProfiles,omitempty"`
 		ProfileL4                  string                `json:"profileL4,omitempty"`
 		ProfileMultiplex           string                `json:"profileMultiplex,omitempty"`
+		ProfileWebSocket           string                `json:"profileWebSocket,omitempty"`
+		TCPSettings                *ProfileTCPSettings   `json:"tcpSettings,omitempty"`
+		HTTPSettings               *ProfileHTTPSettings  `json:"httpSettings,omitempty"`
 		ProfileDOS                 string                `json:"profileDOS,omitempty"`
 		ProfileBotDefense          string                `json:"profileBotDefense,omitempty"`
 		TCP                        ProfileTCP            `json:"tcp,omitempty"`
+		GRPC                       bool                  `json:"grpc,omitempty"`
 		Mode                       string                `json:"mode,omitempty"`
 		TranslateServerAddress     bool                  `json:"translateServerAddress"`
 		TranslateServerPort        bool                  `json:"translateServerPort"`
@@ -217,6 +280,21 @@ type (
 		Server string `json:"server,omitempty"`
 	}
 
+	// ProfileTCPSettings is the parameters of the TCP profile generated for a virtual
+	ProfileTCPSettings struct {
+		IdleTimeout       int32  `json:"idleTimeout,omitempty"`
+		Nagle             string `json:"nagle,omitempty"`
+		KeepAliveInterval int32  `json:"keepAliveInterval,omitempty"`
+	}
+
+	// ProfileHTTPSettings is the parameters of the HTTP profile generated for a virtual
+	ProfileHTTPSettings struct {
+		XForwardedFor *bool  `json:"xForwardedFor,omitempty"`
+		ServerHeader  string `json:"serverHeader,omitempty"`
+		MaxHeaderSize int32  `json:"maxHeaderSize,omitempty"`
+		ProxyType     string `json:"proxyType,omitempty"`
+	}
+
 	// ServiceAddress Service IP address definition (BIG-IP virtual-address).
 	ServiceAddress struct {
 		ArpEnabled         bool   `json:"arpEnabled,omitempty"`
@@ -330,6 +408,7 @@ type (
 		gtmConfig          GTMConfig
 		defaultRouteDomain int
 		reqId              int
+		spanCtx            trace.SpanContext
 	}
 
 	resourceStatusMeta struct {
@@ -437,28 +516,39 @@ type (
 		Ordinal    int          `json:"ordinal,omitempty"`
 		Actions    []*action    `json:"actions,omitempty"`
 		Conditions []*condition `json:"conditions,omitempty"`
+		// CatchAll rules match the requests of the host not matched by the other rules
+		CatchAll bool `json:"-"`
 	}
 
 	// action config for a Rule
 	action struct {
-		Name      string `json:"name"`
-		Pool      string `json:"pool,omitempty"`
-		HTTPHost  bool   `json:"httpHost,omitempty"`
-		HttpReply bool   `json:"httpReply,omitempty"`
-		HTTPURI   bool   `json:"httpUri,omitempty"`
-		Forward   bool   `json:"forward,omitempty"`
-		Location  string `json:"location,omitempty"`
-		Path      string `json:"path,omitempty"`
-		Redirect  bool   `json:"redirect,omitempty"`
-		Replace   bool   `json:"replace,omitempty"`
-		Request   bool   `json:"request,omitempty"`
-		Reset     bool   `json:"reset,omitempty"`
-		Select    bool   `json:"select,omitempty"`
-		Value     string `json:"value,omitempty"`
-		WAF       bool   `json:"waf,omitempty"`
-		Policy    string `json:"policy,omitempty"`
-		Drop      bool   `json:"drop,omitempty"`
-		Enabled   *bool  `json:"enabled,omitempty"`
+		Name       string `json:"name"`
+		Pool       string `json:"pool,omitempty"`
+		HTTPHost   bool   `json:"httpHost,omitempty"`
+		HTTPHeader bool   `json:"httpHeader,omitempty"`
+		HeaderName string `json:"headerName,omitempty"`
+		HttpReply  bool   `json:"httpReply,omitempty"`
+		HTTPURI    bool   `json:"httpUri,omitempty"`
+		Code       int32  `json:"code,omitempty"`
+		Forward    bool   `json:"forward,omitempty"`
+		Insert     bool   `json:"insert,omitempty"`
+		Location   string `json:"location,omitempty"`
+		Path       string `json:"path,omitempty"`
+		Redirect   bool   `json:"redirect,omitempty"`
+		Remove     bool   `json:"remove,omitempty"`
+		Replace    bool   `json:"replace,omitempty"`
+		Request    bool   `json:"request,omitempty"`
+		Response   bool   `json:"response,omitempty"`
+		Reset      bool   `json:"reset,omitempty"`
+		Select     bool   `json:"select,omitempty"`
+		Value      string `json:"value,omitempty"`
+		WAF        bool   `json:"waf,omitempty"`
+		Policy     string `json:"policy,omitempty"`
+		Drop       bool   `json:"drop,omitempty"`
+		Enabled    *bool  `json:"enabled,omitempty"`
+		// SetVariable sets the Tcl variable VariableName to the expression in Value
+		SetVariable  bool   `json:"setVariable,omitempty"`
+		VariableName string `json:"variableName,omitempty"`
 	}
 
 	// condition config for a Rule
@@ -482,8 +572,19 @@ type (
 		Scheme          bool     `json:"scheme,omitempty"`
 		Tcp             bool     `json:"tcp,omitempty"`
 		Values          []string `json:"values"`
+		HTTPHeader      bool     `json:"httpHeader,omitempty"`
+		HTTPCookie      bool     `json:"httpCookie,omitempty"`
+		HTTPMethod      bool     `json:"httpMethod,omitempty"`
+		QueryParameter  bool     `json:"queryParameter,omitempty"`
+		StartsWith      bool     `json:"startsWith,omitempty"`
+		Contains        bool     `json:"contains,omitempty"`
+		CaseSensitive   bool     `json:"caseSensitive,omitempty"`
 
 		SSLExtensionClient bool `json:"-"`
+		// ExactPath marks the path condition of a pool with an exact path
+		ExactPath bool `json:"-"`
+		// PoolMatch marks the conditions created from the match of a pool
+		PoolMatch bool `json:"-"`
 	}
 
 	// Rules is a slice of Rule
@@ -630,7 +731,6 @@ type (
 		ConfigWriter    writer.Writer
 		postChan        chan ResourceConfigRequest
 		EventChan       chan interface{}
-		retryChan       chan struct{}
 		respChan        chan resourceStatusMeta
 		PythonDriverPID int
 		userAgent       string
@@ -645,7 +745,67 @@ type (
 		tenantPriorityMap map[string]int
 		// retryTenantDeclMap holds tenant name and its agent Config,tenant details
 		retryTenantDeclMap map[string]*tenantParams
-		ccclGTMAgent       bool
+		// pendingTenantDeclMap holds the declarations handed to the tenant workers which are not applied yet
+		pendingTenantDeclMap map[string]as3Tenant
+		// tenantWorkers post the declarations of each tenant, they are only accessed by agentWorker
+		tenantWorkers map[string]*tenantWorker
+		ccclGTMAgent  bool
+		// python driver config sections, resent when BIG-IP credentials are rotated
+		driverCfgMutex sync.Mutex
+		globalCfg      globalSection
+		bigIPCfg       bigIPSection
+		gtmBigIPCfg    gtmBigIPSection
+		deletionGuard  deletionGuard
+		tenantPause    tenantPause
+		// lastConfig is the last config request declared, which is posted again when the default route domain changes
+		lastConfig *ResourceConfigRequest
+		debugAPI   bool
+		debugMutex sync.Mutex
+		debugState agentDebugState
+		batch      BatchParams
+		snapshot   declarationSnapshot
+		// configRequests counts the config requests received since the last declaration was built
+		configRequests int32
+		// targetAgents are the agents of the BIG-IP targets, whose circuit breakers are reported by "/ready"
+		targetAgentsMutex sync.Mutex
+		targetAgents      []*Agent
+	}
+
+	// tenantWorker posts the declarations of a tenant with its own retry and task polling state,
+	// so that a slow or failing tenant does not delay the other tenants
+	tenantWorker struct {
+		tenant string
+		// updateChan holds the latest declaration of the tenant which is not posted yet
+		updateChan chan tenantUpdate
+	}
+
+	// tenantUpdate is a declaration handed to a tenant worker
+	tenantUpdate struct {
+		decl    as3Tenant
+		reqId   int
+		spanCtx trace.SpanContext
+		// settled is done once the declaration is posted and its task is completed, or it is superseded
+		settled *sync.WaitGroup
+	}
+
+	// declarationSnapshot holds the tenant declarations applied before CIS restarted
+	declarationSnapshot struct {
+		// hashes are compared with the first declaration of each tenant, which is not posted if unchanged
+		hashes map[string]string
+		// decls are the baseline of the deletion guard until the tenant is posted
+		decls map[string]as3Tenant
+		// save persists the applied declarations, it is called by snapshotWorker once dirty is signalled
+		save  func(map[string]as3Tenant)
+		dirty chan struct{}
+	}
+
+	// tenantPause holds the tenants whose declarations are not posted while reconciliation is paused
+	tenantPause struct {
+		all        bool
+		partitions map[string]bool
+		// catchUpPending is set when declarations were skipped, lastConfig is posted once unpaused
+		catchUpPending bool
+		lastConfig     *ResourceConfigRequest
 	}
 
 	AgentParams struct {
@@ -662,13 +822,60 @@ type (
 		EnableIPV6     bool
 		DisableARP     bool
 		CCCLGTMAgent   bool
+		DeletionGuard  DeletionGuardParams
+		DebugAPI       bool
+		Batch          BatchParams
+		// LTMOnly agents post only the AS3 LTM declarations of their partition,
+		// without the python driver for network and GTM configuration
+		LTMOnly bool
+	}
+
+	// DeletionGuardParams configures the thresholds above which removal of virtual servers
+	// from a tenant is held until an operator allows it
+	DeletionGuardParams struct {
+		// MaxDeletePercent is the percentage of virtual servers of a tenant which can be removed at once
+		MaxDeletePercent int
+		// MaxDeleteCount is the number of virtual servers of a tenant which can be removed at once
+		MaxDeleteCount int
+		// OverrideCfgMap is the namespace/name of the ConfigMap used to allow held deletions
+		OverrideCfgMap string
+	}
+
+	// BatchParams configures how config requests are coalesced before the AS3 declaration is built
+	BatchParams struct {
+		// QuietPeriod is the time without new config requests after which the declaration is built
+		QuietPeriod time.Duration
+		// MaxDelay caps the time the first config request of a batch waits for the quiet period
+		MaxDelay time.Duration
+	}
+
+	deletionGuard struct {
+		DeletionGuardParams
+		// overrideActive reports whether held deletions are allowed to proceed
+		overrideActive func() bool
+		// notifyHeld reports a tenant declaration held by the guard
+		notifyHeld func(tenant string, deleted, total int)
+		// heldTenants and heldConfig hold the tenants and config request which are awaiting an override
+		heldTenants map[string]struct{}
+		heldConfig  *ResourceConfigRequest
+		// bigipDecls holds the tenants declared on BIG-IP at startup, the baseline of the tenants
+		// which have no cached or snapshot declaration
+		bigipDecls map[string]as3Tenant
 	}
 
 	PostManager struct {
-		httpClient        *http.Client
-		tenantResponseMap map[string]tenantResponse
+		httpClient *http.Client
 		PostParams
+		// firstPost is guarded by settingsMutex
 		firstPost bool
+		// postSlots bounds the number of declarations posted to BIG-IP at the same time
+		postSlots chan struct{}
+		// breaker stops posting while BIG-IP is unreachable or unavailable, nil when disabled
+		breaker *circuitBreaker
+		// credsMutex guards BIG-IP credentials in PostParams, which can be rotated at runtime
+		credsMutex sync.RWMutex
+		// settingsMutex guards LogResponse and AS3PostDelay in PostParams, which can be changed at runtime
+		settingsMutex sync.RWMutex
 	}
 
 	PostParams struct {
@@ -677,9 +884,23 @@ type (
 		BIGIPURL      string
 		TrustedCerts  string
 		SSLInsecure   bool
-		AS3PostDelay  int
+		// ClientCert and ClientKey are the PEM encoded certificate and key
+		// presented to the BIG-IP management API for mutual TLS
+		ClientCert string
+		ClientKey  string
+		// ServerName enforces verification of the BIG-IP certificate against this SAN
+		ServerName   string
+		AS3PostDelay int
 		//Log the AS3 response body in Controller logs
 		LogResponse bool
+		// PostConcurrency is the number of tenant declarations posted to BIG-IP at the same time
+		PostConcurrency int
+		// RetryMaxInterval caps the exponential backoff between the retries of a failed declaration
+		RetryMaxInterval time.Duration
+		// CircuitBreakerThreshold is the number of consecutive failed requests after which
+		// requests to BIG-IP are paused for CircuitBreakerInterval, 0 disables the circuit breaker
+		CircuitBreakerThreshold int
+		CircuitBreakerInterval  time.Duration
 	}
 
 	GTMParams struct {
@@ -688,6 +909,13 @@ type (
 		GTMBigIpUrl      string
 	}
 
+	// BigIPCredentials holds the BIG-IP credentials returned by a CredentialProvider
+	BigIPCredentials struct {
+		Username string
+		Password string
+		URL      string
+	}
+
 	tenantResponse struct {
 		agentResponseCode int
 		taskId            string
@@ -702,8 +930,15 @@ type (
 		data      string
 		as3APIURL string
 		id        int
+		tenants   []string
+		spanCtx   trace.SpanContext
+		// tenantResponseMap holds the responses of the tenants of the declaration
+		tenantResponseMap tenantResponses
 	}
 
+	// tenantResponses holds the BIG-IP responses by tenant
+	tenantResponses map[string]tenantResponse
+
 	globalSection struct {
 		LogLevel       string `json:"log-level,omitempty"`
 		VerifyInterval int    `json:"verify-interval,omitempty"`
@@ -766,15 +1001,55 @@ type (
 		Egress  *as3ResourcePointer `json:"egress,omitempty"`
 	}
 
+	// as3ProfileHTTP2 maps to the HTTP/2 profiles of the client side and the server side of a virtual
+	as3ProfileHTTP2 struct {
+		Ingress *as3ResourcePointer `json:"ingress,omitempty"`
+		Egress  *as3ResourcePointer `json:"egress,omitempty"`
+	}
+
+	// as3HTTP2Profile maps to HTTP2_Profile in AS3 Resources
+	as3HTTP2Profile struct {
+		Class string `json:"class"`
+	}
+
+	// as3TCPProfile maps to TCP_Profile in AS3 Resources
+	as3TCPProfile struct {
+		Class             string `json:"class"`
+		IdleTimeout       int32  `json:"idleTimeout,omitempty"`
+		Nagle             string `json:"nagle,omitempty"`
+		KeepAliveInterval int32  `json:"keepAliveInterval,omitempty"`
+	}
+
+	// as3HTTPProfile maps to HTTP_Profile in AS3 Resources
+	as3HTTPProfile struct {
+		Class             string              `json:"class"`
+		RequestChunking   string              `json:"requestChunking,omitempty"`
+		ResponseChunking  string              `json:"responseChunking,omitempty"`
+		XForwardedFor     *bool               `json:"xForwardedFor,omitempty"`
+		ServerHeaderValue string              `json:"serverHeaderValue,omitempty"`
+		MaxHeaderSize     int32               `json:"maxHeaderSize,omitempty"`
+		ProxyType         string              `json:"proxyType,omitempty"`
+		ProfileWebSocket  *as3ResourcePointer `json:"profileWebSocket,omitempty"`
+	}
+
 	// as3Action maps to Policy_Action in AS3 Resources
 	as3Action struct {
-		Type     string                  `json:"type,omitempty"`
-		Event    string                  `json:"event,omitempty"`
-		Select   *as3ActionForwardSelect `json:"select,omitempty"`
-		Policy   *as3ResourcePointer     `json:"policy,omitempty"`
-		Enabled  *bool                   `json:"enabled,omitempty"`
-		Location string                  `json:"location,omitempty"`
-		Replace  *as3ActionReplaceMap    `json:"replace,omitempty"`
+		Type        string                  `json:"type,omitempty"`
+		Event       string                  `json:"event,omitempty"`
+		Select      *as3ActionForwardSelect `json:"select,omitempty"`
+		Policy      *as3ResourcePointer     `json:"policy,omitempty"`
+		Enabled     *bool                   `json:"enabled,omitempty"`
+		Location    string                  `json:"location,omitempty"`
+		Code        int32                   `json:"code,omitempty"`
+		Replace     *as3ActionReplaceMap    `json:"replace,omitempty"`
+		Insert      *as3ActionReplaceMap    `json:"insert,omitempty"`
+		Remove      *as3ActionReplaceMap    `json:"remove,omitempty"`
+		SetVariable *as3ActionSetVariable   `json:"setVariable,omitempty"`
+	}
+
+	as3ActionSetVariable struct {
+		Name       string `json:"name"`
+		Expression string `json:"expression"`
 	}
 
 	as3ActionReplaceMap struct {
@@ -785,16 +1060,17 @@ type (
 
 	// as3Condition maps to Policy_Condition in AS3 Resources
 	as3Condition struct {
-		Type        string                  `json:"type,omitempty"`
-		Name        string                  `json:"name,omitempty"`
-		Event       string                  `json:"event,omitempty"`
-		All         *as3PolicyCompareString `json:"all,omitempty"`
-		Index       int                     `json:"index,omitempty"`
-		Host        *as3PolicyCompareString `json:"host,omitempty"`
-		PathSegment *as3PolicyCompareString `json:"pathSegment,omitempty"`
-		Path        *as3PolicyCompareString `json:"path,omitempty"`
-		ServerName  *as3PolicyCompareString `json:"serverName,omitempty"`
-		Address     *as3PolicyAddressString `json:"address,omitempty"`
+		Type           string                  `json:"type,omitempty"`
+		Name           string                  `json:"name,omitempty"`
+		Event          string                  `json:"event,omitempty"`
+		All            *as3PolicyCompareString `json:"all,omitempty"`
+		Index          int                     `json:"index,omitempty"`
+		Host           *as3PolicyCompareString `json:"host,omitempty"`
+		PathSegment    *as3PolicyCompareString `json:"pathSegment,omitempty"`
+		Path           *as3PolicyCompareString `json:"path,omitempty"`
+		QueryParameter *as3PolicyCompareString `json:"queryParameter,omitempty"`
+		ServerName     *as3PolicyCompareString `json:"serverName,omitempty"`
+		Address        *as3PolicyAddressString `json:"address,omitempty"`
 	}
 
 	// as3ActionForwardSelect maps to Policy_Action_Forward_Select in AS3 Resources
@@ -42,11 +42,29 @@ func (ctlr *Controller) checkValidVirtualServer(
 		log.Infof("VirtualServer %s is invalid", vsName)
 		return false
 	}
+	if !ctlr.isValidBigIPRef(vsResource.Spec.BigIPRef) {
+		log.Errorf("VirtualServer %v references unknown BIG-IP %v in bigipRef", vsName, vsResource.Spec.BigIPRef)
+		return false
+	}
 	// Check if HTTPTraffic is set for insecure VS
 	if vsResource.Spec.TLSProfileName == "" && vsResource.Spec.HTTPTraffic != "" {
 		log.Errorf("HTTPTraffic not allowed to be set for insecure VirtualServer: %v", vsName)
 		return false
 	}
+	// Redirects and direct responses are sent by the policy rules of the pools, which do not match regex paths
+	// and regex match conditions
+	for _, pl := range vsResource.Spec.Pools {
+		if pl.PathType == PathTypeRegex && isServicelessPool(pl) {
+			log.Errorf("redirect and directResponse are not supported with the regex path %v of VirtualServer: %v",
+				pl.Path, vsName)
+			return false
+		}
+		if hasRegexMatch(pl) && isServicelessPool(pl) {
+			log.Errorf("redirect and directResponse are not supported with the regex match of path %v of VirtualServer: %v",
+				pl.Path, vsName)
+			return false
+		}
+	}
 
 	bindAddr := vsResource.Spec.VirtualServerAddress
 	if ctlr.ipamCli == nil {
@@ -87,6 +105,10 @@ func (ctlr *Controller) checkValidTransportServer(
 		log.Infof("TransportServer %s is invalid", vsName)
 		return false
 	}
+	if !ctlr.isValidBigIPRef(tsResource.Spec.BigIPRef) {
+		log.Errorf("TransportServer %v references unknown BIG-IP %v in bigipRef", vsName, tsResource.Spec.BigIPRef)
+		return false
+	}
 
 	bindAddr := tsResource.Spec.VirtualServerAddress
 
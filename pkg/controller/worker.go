@@ -34,10 +34,11 @@ import (
 	cisapiv1 "github.com/F5Networks/k8s-bigip-ctlr/v2/config/apis/cis/v1"
 	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
 	routeapi "github.com/openshift/api/route/v1"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 )
 
 const nginxMonitorPort int32 = 8081
@@ -100,11 +101,18 @@ func (ctlr *Controller) processResources() bool {
 
 	defer ctlr.resourceQueue.Done(key)
 	rKey := key.(*rqKey)
-	log.Debugf("Processing Key: %v", rKey)
+	keyLog := rKey.logger()
+	keyLog.Debugf("Processing Key: %v", rKey)
 
 	// During Init time, just accumulate all the poolMembers by processing only services
 	if ctlr.initState && rKey.kind != Namespace {
 		if rKey.kind != Service {
+			// Config is not posted until the requeued keys are processed, as they are not in the queue length
+			// while waiting for the rate limiter
+			if ctlr.initRequeued == nil {
+				ctlr.initRequeued = make(map[*rqKey]struct{})
+			}
+			ctlr.initRequeued[rKey] = struct{}{}
 			ctlr.resourceQueue.AddRateLimited(key)
 			return true
 		}
@@ -113,6 +121,27 @@ func (ctlr *Controller) processResources() bool {
 			ctlr.initState = false
 		}
 	}
+	delete(ctlr.initRequeued, rKey)
+
+	_, span := startSpan(rKey.spanCtx, "processResources",
+		attrResourceKey.String(rKey.resourceKey()),
+		attrEvent.String(rKey.event),
+	)
+	ctlr.processSpanCtx = span.SpanContext()
+	defer func() {
+		ctlr.processSpanCtx = trace.SpanContext{}
+		span.End()
+	}()
+
+	// The changes of a paused resource are computed and held in their partitions until it is resumed
+	var pausedLTMConfig LTMConfig
+	var pausedGTMConfig GTMConfig
+	paused := ctlr.isKeyPaused(rKey)
+	if paused {
+		keyLog.Debugf("Reconciliation paused, holding the changes of Key: %v", rKey)
+		pausedLTMConfig = ctlr.resources.getLTMConfigDeepCopy()
+		pausedGTMConfig = ctlr.resources.getGTMConfigCopy()
+	}
 
 	rscDelete := false
 	if rKey.event == Delete {
@@ -151,7 +180,7 @@ func (ctlr *Controller) processResources() bool {
 			err := ctlr.processRoutes(routeGroup, false)
 			if err != nil {
 				// TODO
-				utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+				keyLog.Errorf("Sync %v failed with %v", key, err)
 				isRetryableError = true
 			}
 		}
@@ -163,7 +192,7 @@ func (ctlr *Controller) processResources() bool {
 		cm := rKey.rsc.(*v1.ConfigMap)
 		err, ok := ctlr.processConfigMap(cm, rscDelete)
 		if err != nil {
-			utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+			keyLog.Errorf("Sync %v failed with %v", key, err)
 			break
 		}
 
@@ -192,7 +221,7 @@ func (ctlr *Controller) processResources() bool {
 		err := ctlr.processVirtualServers(virtual, rscDelete)
 		if err != nil {
 			// TODO
-			utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+			keyLog.Errorf("Sync %v failed with %v", key, err)
 			isRetryableError = true
 		}
 	case TLSProfile:
@@ -209,7 +238,7 @@ func (ctlr *Controller) processResources() bool {
 			err := ctlr.processVirtualServers(virtual, false)
 			if err != nil {
 				// TODO
-				utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+				keyLog.Errorf("Sync %v failed with %v", key, err)
 				isRetryableError = true
 			}
 		}
@@ -233,7 +262,7 @@ func (ctlr *Controller) processResources() bool {
 					err := ctlr.processVirtualServers(virtual, false)
 					if err != nil {
 						// TODO
-						utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+						keyLog.Errorf("Sync %v failed with %v", key, err)
 						isRetryableError = true
 					}
 				}
@@ -248,7 +277,7 @@ func (ctlr *Controller) processResources() bool {
 		err := ctlr.processTransportServers(virtual, rscDelete)
 		if err != nil {
 			// TODO
-			utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+			keyLog.Errorf("Sync %v failed with %v", key, err)
 			isRetryableError = true
 		}
 	case IngressLink:
@@ -256,12 +285,12 @@ func (ctlr *Controller) processResources() bool {
 			break
 		}
 		ingLink := rKey.rsc.(*cisapiv1.IngressLink)
-		log.Infof("Worker got IngressLink: %v\n", ingLink)
-		log.Infof("IngressLink Selector: %v\n", ingLink.Spec.Selector.String())
+		keyLog.Infof("Worker got IngressLink: %v\n", ingLink)
+		keyLog.Infof("IngressLink Selector: %v\n", ingLink.Spec.Selector.String())
 		err := ctlr.processIngressLink(ingLink, rscDelete)
 		if err != nil {
 			// TODO
-			utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+			keyLog.Errorf("Sync %v failed with %v", key, err)
 			isRetryableError = true
 		}
 	case ExternalDNS:
@@ -289,7 +318,7 @@ func (ctlr *Controller) processResources() bool {
 				err := ctlr.processVirtualServers(virtual, false)
 				if err != nil {
 					// TODO
-					utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+					keyLog.Errorf("Sync %v failed with %v", key, err)
 					isRetryableError = true
 				}
 			}
@@ -299,7 +328,7 @@ func (ctlr *Controller) processResources() bool {
 				err := ctlr.processTransportServers(virtual, false)
 				if err != nil {
 					// TODO
-					utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+					keyLog.Errorf("Sync %v failed with %v", key, err)
 					isRetryableError = true
 				}
 			}
@@ -309,7 +338,7 @@ func (ctlr *Controller) processResources() bool {
 				err := ctlr.processLBServices(lbService, false)
 				if err != nil {
 					// TODO
-					utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+					keyLog.Errorf("Sync %v failed with %v", key, err)
 					isRetryableError = true
 				}
 			}
@@ -323,7 +352,7 @@ func (ctlr *Controller) processResources() bool {
 			err := ctlr.processLBServices(svc, rscDelete)
 			if err != nil {
 				// TODO
-				utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+				keyLog.Errorf("Sync %v failed with %v", key, err)
 				isRetryableError = true
 			}
 			break
@@ -342,7 +371,7 @@ func (ctlr *Controller) processResources() bool {
 					err := ctlr.processVirtualServers(virtual, false)
 					if err != nil {
 						// TODO
-						utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+						keyLog.Errorf("Sync %v failed with %v", key, err)
 						isRetryableError = true
 					}
 				}
@@ -354,7 +383,7 @@ func (ctlr *Controller) processResources() bool {
 					err := ctlr.processTransportServers(virtual, false)
 					if err != nil {
 						// TODO
-						utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+						keyLog.Errorf("Sync %v failed with %v", key, err)
 						isRetryableError = true
 					}
 				}
@@ -367,10 +396,10 @@ func (ctlr *Controller) processResources() bool {
 					err := ctlr.processIngressLink(ingLink, rscDelete)
 					if err != nil {
 						if rscDelete {
-							utilruntime.HandleError(fmt.Errorf("Deleting IngresLink %v failed with %v", ingLink.Name, err))
+							keyLog.Errorf("Deleting IngresLink %v failed with %v", ingLink.Name, err)
 						} else {
 							// TODO
-							utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+							keyLog.Errorf("Sync %v failed with %v", key, err)
 						}
 						isRetryableError = true
 					}
@@ -392,7 +421,7 @@ func (ctlr *Controller) processResources() bool {
 			err := ctlr.processLBServices(svc, rscDelete)
 			if err != nil {
 				// TODO
-				utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+				keyLog.Errorf("Sync %v failed with %v", key, err)
 				isRetryableError = true
 			}
 			break
@@ -417,7 +446,7 @@ func (ctlr *Controller) processResources() bool {
 			err := ctlr.processLBServices(svc, rscDelete)
 			if err != nil {
 				// TODO
-				utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+				keyLog.Errorf("Sync %v failed with %v", key, err)
 				isRetryableError = true
 			}
 			break
@@ -431,7 +460,7 @@ func (ctlr *Controller) processResources() bool {
 				err := ctlr.processVirtualServers(virtual, false)
 				if err != nil {
 					// TODO
-					utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+					keyLog.Errorf("Sync %v failed with %v", key, err)
 					isRetryableError = true
 				}
 			}
@@ -442,7 +471,7 @@ func (ctlr *Controller) processResources() bool {
 					err := ctlr.processTransportServers(virtual, false)
 					if err != nil {
 						// TODO
-						utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+						keyLog.Errorf("Sync %v failed with %v", key, err)
 						isRetryableError = true
 					}
 				}
@@ -454,7 +483,7 @@ func (ctlr *Controller) processResources() bool {
 					err := ctlr.processIngressLink(ingLink, false)
 					if err != nil {
 						// TODO
-						utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+						keyLog.Errorf("Sync %v failed with %v", key, err)
 						isRetryableError = true
 					}
 				}
@@ -504,7 +533,7 @@ func (ctlr *Controller) processResources() bool {
 					err := ctlr.processVirtualServers(vrt, true)
 					if err != nil {
 						// TODO
-						utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+						keyLog.Errorf("Sync %v failed with %v", key, err)
 						isRetryableError = true
 					}
 				}
@@ -513,7 +542,7 @@ func (ctlr *Controller) processResources() bool {
 					err := ctlr.processTransportServers(ts, true)
 					if err != nil {
 						// TODO
-						utilruntime.HandleError(fmt.Errorf("Sync %v failed with %v", key, err))
+						keyLog.Errorf("Sync %v failed with %v", key, err)
 						isRetryableError = true
 					}
 				}
@@ -533,29 +562,70 @@ func (ctlr *Controller) processResources() bool {
 			}
 		}
 	default:
-		log.Errorf("Unknown resource Kind: %v", rKey.kind)
+		keyLog.Errorf("Unknown resource Kind: %v", rKey.kind)
+	}
+
+	if paused {
+		ctlr.holdChangedPartitions(rKey, pausedLTMConfig, pausedGTMConfig)
 	}
 
 	if isRetryableError {
+		span.SetStatus(codes.Error, "sync failed, retrying")
 		ctlr.resourceQueue.AddRateLimited(key)
 	} else {
 		ctlr.resourceQueue.Forget(key)
 	}
 
+	ctlr.postResourceConfig()
+	return true
+}
+
+// postResourceConfig posts the updated config once all the queued resources are processed
+func (ctlr *Controller) postResourceConfig() {
+	if len(ctlr.initRequeued) > 0 {
+		log.Debugf("Holding config until %v resources requeued during init are processed", len(ctlr.initRequeued))
+		return
+	}
 	if ctlr.resourceQueue.Len() == 0 && ctlr.resources.isConfigUpdated() {
 		config := ResourceConfigRequest{
 			ltmConfig:          ctlr.resources.getLTMConfigDeepCopy(),
 			shareNodes:         ctlr.shareNodes,
 			gtmConfig:          ctlr.resources.getGTMConfigCopy(),
-			defaultRouteDomain: ctlr.defaultRouteDomain,
+			defaultRouteDomain: ctlr.getDefaultRouteDomain(),
 		}
 		go ctlr.TeemData.PostTeemsData()
+		// statuses of the resources of the BIG-IP targets are updated with the responses of their agents
+		targetConfigs := ctlr.splitBigIPTargetConfigs(&config)
 		config.reqId = ctlr.enqueueReq(config)
+		_, span := startSpan(ctlr.processSpanCtx, "postResourceConfig", attrRequestID.Int(config.reqId))
+		defer span.End()
+		config.spanCtx = span.SpanContext()
+		ctlr.updateDebugState(config)
+		ctlr.postBigIPTargetConfigs(targetConfigs, config)
 		ctlr.Agent.PostConfig(config)
 		ctlr.initState = false
 		ctlr.resources.updateCaches()
 	}
-	return true
+}
+
+// logger returns a logger recording the resource of the key with each message
+func (rKey *rqKey) logger() *log.Entry {
+	return log.WithFields(log.Fields{
+		log.FieldComponent: "worker",
+		log.FieldNamespace: rKey.namespace,
+		log.FieldKind:      rKey.kind,
+		log.FieldName:      rKey.rscName,
+	})
+}
+
+// resourceLogger returns a logger recording the resource with each message
+func resourceLogger(kind string, obj metav1.Object) *log.Entry {
+	return log.WithFields(log.Fields{
+		log.FieldComponent: "worker",
+		log.FieldNamespace: obj.GetNamespace(),
+		log.FieldKind:      kind,
+		log.FieldName:      obj.GetName(),
+	})
 }
 
 // getServiceForEndpoints returns the service associated with endpoints.
@@ -780,16 +850,30 @@ func filterVirtualServersForService(allVirtuals []*cisapiv1.VirtualServer,
 	svcNamespace := svc.ObjectMeta.Namespace
 
 	for _, vs := range allVirtuals {
-		if vs.ObjectMeta.Namespace != svcNamespace {
-			continue
-		}
-
 		isValidVirtual := false
 		for _, pool := range vs.Spec.Pools {
-			if pool.Service == svcName {
+			if pool.Service == svcName && vs.ObjectMeta.Namespace == svcNamespace {
 				isValidVirtual = true
 				break
 			}
+			for _, altPool := range getAlternateBackendPools(pool) {
+				altNamespace := vs.ObjectMeta.Namespace
+				if altPool.ServiceNamespace != "" {
+					altNamespace = altPool.ServiceNamespace
+				}
+				if altPool.Service == svcName && altNamespace == svcNamespace {
+					isValidVirtual = true
+				}
+			}
+		}
+		if defaultPool := vs.Spec.DefaultPool; defaultPool != nil {
+			defaultNamespace := vs.ObjectMeta.Namespace
+			if defaultPool.ServiceNamespace != "" {
+				defaultNamespace = defaultPool.ServiceNamespace
+			}
+			if defaultPool.Service == svcName && defaultNamespace == svcNamespace {
+				isValidVirtual = true
+			}
 		}
 		if !isValidVirtual {
 			continue
@@ -814,14 +898,20 @@ func getVirtualServersForTLSProfile(allVirtuals []*cisapiv1.VirtualServer,
 		if vs.ObjectMeta.Namespace == tlsNamespace && vs.Spec.TLSProfileName == tlsName {
 			found := false
 			for _, host := range tls.Spec.Hosts {
-				if vs.Spec.Host == host {
+				for _, vsHost := range getVirtualServerHosts(vs) {
+					if vsHost == host {
+						found = true
+						break
+					}
+				}
+				if found {
 					result = append(result, vs)
-					found = true
 					break
 				}
 			}
 			if !found {
-				log.Errorf("TLSProfile hostname is not same as virtual host %s for profile %s", vs.Spec.Host, vs.Spec.TLSProfileName)
+				log.Errorf("TLSProfile hostname is not same as virtual host %s for profile %s",
+					strings.Join(getVirtualServerHosts(vs), ","), vs.Spec.TLSProfileName)
 			}
 		}
 	}
@@ -952,21 +1042,26 @@ func (ctlr *Controller) processVirtualServers(
 	virtual *cisapiv1.VirtualServer,
 	isVSDeleted bool,
 ) error {
+	rscLog := resourceLogger(VirtualServer, virtual)
 
 	startTime := time.Now()
 	defer func() {
 		endTime := time.Now()
-		log.Debugf("Finished syncing virtual servers %+v (%v)",
+		rscLog.Debugf("Finished syncing virtual servers %+v (%v)",
 			virtual, endTime.Sub(startTime))
 	}()
 
+	if !isVSDeleted {
+		ctlr.holdPausedResource(VirtualServer, virtual, ctlr.getCRPartition(virtual.Spec.Partition, virtual.Spec.BigIPRef))
+	}
+
 	// Skip validation for a deleted Virtual Server
 	if !isVSDeleted {
 		// check if the virutal server matches all the requirements.
 		vkey := virtual.ObjectMeta.Namespace + "/" + virtual.ObjectMeta.Name
 		valid := ctlr.checkValidVirtualServer(virtual)
 		if false == valid {
-			log.Errorf("VirtualServer %s, is not valid",
+			rscLog.Errorf("VirtualServer %s, is not valid",
 				vkey)
 			return nil
 		}
@@ -985,7 +1080,7 @@ func (ctlr *Controller) processVirtualServers(
 
 	// Prepare list of associated VirtualServers to be processed
 	// In the event of deletion, exclude the deleted VirtualServer
-	log.Debugf("Process all the Virtual Servers which share same VirtualServerAddress")
+	rscLog.Debugf("Process all the Virtual Servers which share same VirtualServerAddress")
 
 	VSSpecProps := &VSSpecProperties{}
 	virtuals := ctlr.getAssociatedVirtualServers(virtual, allVirtuals, isVSDeleted, VSSpecProps)
@@ -993,7 +1088,7 @@ func (ctlr *Controller) processVirtualServers(
 
 	var ip string
 	var status int
-	partition := ctlr.getCRPartition(virtual.Spec.Partition)
+	partition := ctlr.getCRPartition(virtual.Spec.Partition, virtual.Spec.BigIPRef)
 	if ctlr.ipamCli != nil {
 		if isVSDeleted && len(virtuals) == 0 && virtual.Spec.VirtualServerAddress == "" {
 			if virtual.Spec.HostGroup != "" {
@@ -1002,8 +1097,10 @@ func (ctlr *Controller) processVirtualServers(
 				key := virtual.Spec.HostGroup + "_hg"
 				ip = ctlr.releaseIP(virtual.Spec.IPAMLabel, "", key)
 			} else {
-				key := virtual.Namespace + "/" + virtual.Spec.Host + "_host"
-				ip = ctlr.releaseIP(virtual.Spec.IPAMLabel, virtual.Spec.Host, key)
+				// A VirtualServer with multiple hosts is keyed by its first host
+				host := getVirtualServerHosts(virtual)[0]
+				key := virtual.Namespace + "/" + host + "_host"
+				ip = ctlr.releaseIP(virtual.Spec.IPAMLabel, host, key)
 			}
 		} else if virtual.Spec.VirtualServerAddress != "" {
 			// Prioritise VirtualServerAddress specified over IPAMLabel
@@ -1015,21 +1112,22 @@ func (ctlr *Controller) processVirtualServers(
 				key := virtual.Spec.HostGroup + "_hg"
 				ip, status = ctlr.requestIP(ipamLabel, "", key)
 			} else {
-				key := virtual.Namespace + "/" + virtual.Spec.Host + "_host"
-				ip, status = ctlr.requestIP(ipamLabel, virtual.Spec.Host, key)
+				host := getVirtualServerHosts(virtual)[0]
+				key := virtual.Namespace + "/" + host + "_host"
+				ip, status = ctlr.requestIP(ipamLabel, host, key)
 			}
 
 			switch status {
 			case NotEnabled:
-				log.Debug("IPAM Custom Resource Not Available")
+				rscLog.Debug("IPAM Custom Resource Not Available")
 				return nil
 			case InvalidInput:
-				log.Debugf("IPAM Invalid IPAM Label: %v for Virtual Server: %s/%s", ipamLabel, virtual.Namespace, virtual.Name)
+				rscLog.Debugf("IPAM Invalid IPAM Label: %v for Virtual Server: %s/%s", ipamLabel, virtual.Namespace, virtual.Name)
 				return nil
 			case NotRequested:
 				return fmt.Errorf("unable make do IPAM Request, will be re-requested soon")
 			case Requested:
-				log.Debugf("IP address requested for service: %s/%s", virtual.Namespace, virtual.Name)
+				rscLog.Debugf("IP address requested for service: %s/%s", virtual.Namespace, virtual.Name)
 				return nil
 			}
 			virtual.Status.VSAddress = ip
@@ -1044,7 +1142,7 @@ func (ctlr *Controller) processVirtualServers(
 			var err error
 			ip, err = getVirtualServerAddress(virtuals)
 			if err != nil {
-				log.Errorf("Error in virtualserver address: %s", err.Error())
+				rscLog.Errorf("Error in virtualserver address: %s", err.Error())
 				return err
 			}
 			if ip == "" {
@@ -1058,6 +1156,9 @@ func (ctlr *Controller) processVirtualServers(
 	// Depending on the ports defined, TLS type or Unsecured we will populate the resource config.
 	portStructs := ctlr.virtualPorts(virtual)
 
+	// Virtuals with multiple hosts are processed as one virtual per host
+	hostVirtuals := getVirtualServersForHosts(virtuals)
+
 	// vsMap holds Resource Configs of current virtuals temporarily
 	vsMap := make(ResourceMap)
 	processingError := false
@@ -1126,11 +1227,11 @@ func (ctlr *Controller) processVirtualServers(
 		}
 		if err != nil {
 			processingError = true
-			log.Errorf("%v", err)
+			rscLog.Errorf("%v", err)
 			break
 		}
 
-		for _, vrt := range virtuals {
+		for _, vrt := range hostVirtuals {
 			passthroughVS := false
 			var tlsProf *cisapiv1.TLSProfile
 			if isTLSVirtualServer(vrt) {
@@ -1147,7 +1248,7 @@ func (ctlr *Controller) processVirtualServers(
 				}
 			}
 
-			log.Debugf("Processing Virtual Server %s for port %v",
+			rscLog.Debugf("Processing Virtual Server %s for port %v",
 				vrt.ObjectMeta.Name, portStruct.port)
 			rsCfg.MetaData.baseResources[vrt.Namespace+"/"+vrt.Name] = VirtualServer
 			err := ctlr.prepareRSConfigFromVirtualServer(
@@ -1169,7 +1270,7 @@ func (ctlr *Controller) processVirtualServers(
 					break
 				}
 
-				log.Debugf("Updated Virtual %s with TLSProfile %s",
+				rscLog.Debugf("Updated Virtual %s with TLSProfile %s",
 					vrt.ObjectMeta.Name, vrt.Spec.TLSProfileName)
 			}
 
@@ -1187,7 +1288,7 @@ func (ctlr *Controller) processVirtualServers(
 			ctlr.addDefaultWAFDisableRule(rsCfg, "vs_waf_disable")
 		}
 		if processingError {
-			log.Errorf("Cannot Publish VirtualServer %s", virtual.ObjectMeta.Name)
+			rscLog.Errorf("Cannot Publish VirtualServer %s", virtual.ObjectMeta.Name)
 			break
 		}
 
@@ -1268,7 +1369,7 @@ func (ctlr *Controller) getAssociatedVirtualServers(
 	var virtuals []*cisapiv1.VirtualServer
 	// {hostname: {path: <empty_struct>}}
 	uniqueHostPathMap := make(map[string]map[string]struct{})
-	currentVSPartition := ctlr.getCRPartition(currentVS.Spec.Partition)
+	currentVSPartition := ctlr.getCRPartition(currentVS.Spec.Partition, currentVS.Spec.BigIPRef)
 
 	for _, vrt := range allVirtuals {
 		// skip the deleted virtual in the event of deletion
@@ -1280,7 +1381,7 @@ func (ctlr *Controller) getAssociatedVirtualServers(
 		// This also handles for host group/VS with same hosts
 		if currentVS.Spec.VirtualServerAddress != "" &&
 			currentVS.Spec.VirtualServerAddress == vrt.Spec.VirtualServerAddress &&
-			currentVSPartition != ctlr.getCRPartition(vrt.Spec.Partition) {
+			currentVSPartition != ctlr.getCRPartition(vrt.Spec.Partition, vrt.Spec.BigIPRef) {
 			log.Errorf("Multiple Virtual Servers %v,%v are configured with same VirtualServerAddress : %v with different partitions", currentVS.Name, vrt.Name, vrt.Spec.VirtualServerAddress)
 			return nil
 		}
@@ -1291,8 +1392,8 @@ func (ctlr *Controller) getAssociatedVirtualServers(
 		}
 
 		if currentVS.Spec.HostGroup == "" {
-			// in the absence of HostGroup, skip the virtuals with other host name
-			if vrt.Spec.Host != currentVS.Spec.Host {
+			// in the absence of HostGroup, skip the virtuals sharing no host name
+			if !shareVirtualServerHost(vrt, currentVS) {
 				continue
 			}
 
@@ -1322,7 +1423,7 @@ func (ctlr *Controller) getAssociatedVirtualServers(
 				return nil
 			}
 			// Empty host with IPAM label is invalid for a Virtual Server
-			if vrt.Spec.IPAMLabel != "" && vrt.Spec.Host == "" {
+			if vrt.Spec.IPAMLabel != "" && getVirtualServerHosts(vrt)[0] == "" {
 				log.Errorf("Hostless VS %v is configured with IPAM label: %v", vrt.ObjectMeta.Name, vrt.Spec.IPAMLabel)
 				return nil
 			}
@@ -1334,38 +1435,63 @@ func (ctlr *Controller) getAssociatedVirtualServers(
 		}
 
 		// Check for duplicate path entries among virtuals
-		uniquePaths, ok := uniqueHostPathMap[vrt.Spec.Host]
-		if !ok {
-			uniqueHostPathMap[vrt.Spec.Host] = make(map[string]struct{})
-			uniquePaths = uniqueHostPathMap[vrt.Spec.Host]
-		}
 		isUnique := true
+		// Pools of the same virtual may share a path with different match conditions or path types
+		vrtPaths := make(map[string]struct{})
 		for _, pool := range vrt.Spec.Pools {
 			//Setting PoolWAF to true if exists
 			if pool.WAF != "" {
 				VSSpecProperties.PoolWAF = true
 			}
-			if _, ok := uniquePaths[pool.Path]; ok {
-				// path already exists for the same host
-				log.Debugf("Discarding the VirtualServer %v/%v due to duplicate path",
-					vrt.ObjectMeta.Namespace, vrt.ObjectMeta.Name)
-				isUnique = false
+			vrtPaths[pool.Path] = struct{}{}
+		}
+		vrtHosts := getVirtualServerHosts(vrt)
+		for _, host := range vrtHosts {
+			for path := range vrtPaths {
+				if _, ok := uniqueHostPathMap[host][path]; ok {
+					// path already exists for the same host
+					log.Debugf("Discarding the VirtualServer %v/%v due to duplicate path",
+						vrt.ObjectMeta.Namespace, vrt.ObjectMeta.Name)
+					isUnique = false
+					break
+				}
+			}
+			if !isUnique {
 				break
 			}
-			uniquePaths[pool.Path] = struct{}{}
 		}
 		if isUnique {
+			for _, host := range vrtHosts {
+				if _, ok := uniqueHostPathMap[host]; !ok {
+					uniqueHostPathMap[host] = make(map[string]struct{})
+				}
+				for path := range vrtPaths {
+					uniqueHostPathMap[host][path] = struct{}{}
+				}
+			}
 			virtuals = append(virtuals, vrt)
 		}
 	}
 	return virtuals
 }
 
+// shareVirtualServerHost checks that two VirtualServers have at least one host in common
+func shareVirtualServerHost(vs1, vs2 *cisapiv1.VirtualServer) bool {
+	for _, host1 := range getVirtualServerHosts(vs1) {
+		for _, host2 := range getVirtualServerHosts(vs2) {
+			if host1 == host2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (ctlr *Controller) validateTSWithSameVSAddress(
 	currentTS *cisapiv1.TransportServer,
 	allVirtuals []*cisapiv1.TransportServer,
 	isVSDeleted bool) bool {
-	currentTSPartition := ctlr.getCRPartition(currentTS.Spec.Partition)
+	currentTSPartition := ctlr.getCRPartition(currentTS.Spec.Partition, currentTS.Spec.BigIPRef)
 	for _, vrt := range allVirtuals {
 		// skip the deleted virtual in the event of deletion
 		if isVSDeleted && vrt.Name == currentTS.Name {
@@ -1376,7 +1502,7 @@ func (ctlr *Controller) validateTSWithSameVSAddress(
 		// This also handles for host group/ vs with same hosts
 		if currentTS.Spec.VirtualServerAddress != "" &&
 			currentTS.Spec.VirtualServerAddress == vrt.Spec.VirtualServerAddress &&
-			currentTSPartition != ctlr.getCRPartition(vrt.Spec.Partition) {
+			currentTSPartition != ctlr.getCRPartition(vrt.Spec.Partition, vrt.Spec.BigIPRef) {
 			log.Errorf("Multiple Transport Servers %v,%v are configured with same VirtualServerAddress : %v "+
 				"with different partitions", currentTS.Name, vrt.Name, vrt.Spec.VirtualServerAddress)
 			return false
@@ -1388,7 +1514,7 @@ func (ctlr *Controller) validateILsWithSameVSAddress(
 	currentIL *cisapiv1.IngressLink,
 	allILs []*cisapiv1.IngressLink,
 	isILDeleted bool) bool {
-	currentILPartition := ctlr.getCRPartition(currentIL.Spec.Partition)
+	currentILPartition := ctlr.getCRPartition(currentIL.Spec.Partition, "")
 	for _, vrt := range allILs {
 		// skip the deleted virtual in the event of deletion
 		if isILDeleted && vrt.Name == currentIL.Name {
@@ -1398,7 +1524,7 @@ func (ctlr *Controller) validateILsWithSameVSAddress(
 		// Multiple IL sharing same VS address with different partition is invalid
 		if currentIL.Spec.VirtualServerAddress != "" &&
 			currentIL.Spec.VirtualServerAddress == vrt.Spec.VirtualServerAddress &&
-			currentILPartition != ctlr.getCRPartition(vrt.Spec.Partition) {
+			currentILPartition != ctlr.getCRPartition(vrt.Spec.Partition, "") {
 			log.Errorf("Multiple Ingress Links %v,%v are configured with same VirtualServerAddress : %v "+
 				"with different partitions", currentIL.Name, vrt.Name, vrt.Spec.VirtualServerAddress)
 			return false
@@ -1406,7 +1532,13 @@ func (ctlr *Controller) validateILsWithSameVSAddress(
 	}
 	return true
 }
-func (ctlr *Controller) getCRPartition(partition string) string {
+
+// getCRPartition returns the partition of a custom resource.
+// The partition of the BIG-IP target referenced with bigipRef takes precedence.
+func (ctlr *Controller) getCRPartition(partition, bigipRef string) string {
+	if target, ok := ctlr.bigIPTargets[bigipRef]; ok {
+		return target.Agent.Partition
+	}
 	if partition == "" {
 		return ctlr.Partition
 	}
@@ -1967,20 +2099,25 @@ func (ctlr *Controller) processTransportServers(
 	virtual *cisapiv1.TransportServer,
 	isTSDeleted bool,
 ) error {
+	rscLog := resourceLogger(TransportServer, virtual)
 	startTime := time.Now()
 	defer func() {
 		endTime := time.Now()
-		log.Debugf("Finished syncing transport servers %+v (%v)",
+		rscLog.Debugf("Finished syncing transport servers %+v (%v)",
 			virtual, endTime.Sub(startTime))
 	}()
 
+	if !isTSDeleted {
+		ctlr.holdPausedResource(TransportServer, virtual, ctlr.getCRPartition(virtual.Spec.Partition, virtual.Spec.BigIPRef))
+	}
+
 	// Skip validation for a deleted Virtual Server
 	if !isTSDeleted {
 		// check if the virutal server matches all the requirements.
 		vkey := virtual.ObjectMeta.Namespace + "/" + virtual.ObjectMeta.Name
 		valid := ctlr.checkValidTransportServer(virtual)
 		if false == valid {
-			log.Errorf("TransportServer %s, is not valid",
+			rscLog.Errorf("TransportServer %s, is not valid",
 				vkey)
 			return nil
 		}
@@ -2010,7 +2147,7 @@ func (ctlr *Controller) processTransportServers(
 	var ip string
 	var key string
 	var status int
-	partition := ctlr.getCRPartition(virtual.Spec.Partition)
+	partition := ctlr.getCRPartition(virtual.Spec.Partition, virtual.Spec.BigIPRef)
 	key = virtual.ObjectMeta.Namespace + "/" + virtual.ObjectMeta.Name + "_ts"
 	if ctlr.ipamCli != nil {
 		if virtual.Spec.HostGroup != "" {
@@ -2025,16 +2162,16 @@ func (ctlr *Controller) processTransportServers(
 
 			switch status {
 			case NotEnabled:
-				log.Debug("IPAM Custom Resource Not Available")
+				rscLog.Debug("IPAM Custom Resource Not Available")
 				return nil
 			case InvalidInput:
-				log.Debugf("IPAM Invalid IPAM Label: %v for Transport Server: %s/%s",
+				rscLog.Debugf("IPAM Invalid IPAM Label: %v for Transport Server: %s/%s",
 					virtual.Spec.IPAMLabel, virtual.Namespace, virtual.Name)
 				return nil
 			case NotRequested:
 				return fmt.Errorf("unable to make IPAM Request, will be re-requested soon")
 			case Requested:
-				log.Debugf("IP address requested for Transport Server: %s/%s", virtual.Namespace, virtual.Name)
+				rscLog.Debugf("IP address requested for Transport Server: %s/%s", virtual.Namespace, virtual.Name)
 				return nil
 			}
 			virtual.Status.VSAddress = ip
@@ -2082,16 +2219,16 @@ func (ctlr *Controller) processTransportServers(
 	if plc != nil {
 		err := ctlr.handleTSResourceConfigForPolicy(rsCfg, plc)
 		if err != nil {
-			log.Errorf("%v", err)
+			rscLog.Errorf("%v", err)
 			return nil
 		}
 	}
 	if err != nil {
-		log.Errorf("%v", err)
+		rscLog.Errorf("%v", err)
 		return nil
 	}
 
-	log.Debugf("Processing Transport Server %s for port %v",
+	rscLog.Debugf("Processing Transport Server %s for port %v",
 		virtual.ObjectMeta.Name, virtual.Spec.VirtualServerPort)
 	rsCfg.MetaData.baseResources[virtual.ObjectMeta.Namespace+"/"+virtual.ObjectMeta.Name] = TransportServer
 	err = ctlr.prepareRSConfigFromTransportServer(
@@ -2099,7 +2236,7 @@ func (ctlr *Controller) processTransportServers(
 		virtual,
 	)
 	if err != nil {
-		log.Errorf("Cannot Publish TransportServer %s", virtual.ObjectMeta.Name)
+		rscLog.Errorf("Cannot Publish TransportServer %s", virtual.ObjectMeta.Name)
 		return nil
 	}
 
@@ -2456,7 +2593,7 @@ func (ctlr *Controller) processExternalDNS(edns *cisapiv1.ExternalDNS, isDelete
 	partitions := ctlr.resources.getLTMPartitions()
 
 	for _, pl := range edns.Spec.Pools {
-		UniquePoolName := edns.Spec.DomainName + "_" + AS3NameFormatter(strings.TrimPrefix(ctlr.Agent.BIGIPURL, "https://")) + "_" + ctlr.Partition
+		UniquePoolName := edns.Spec.DomainName + "_" + AS3NameFormatter(strings.TrimPrefix(ctlr.Agent.getBIGIPURL(), "https://")) + "_" + ctlr.Partition
 		log.Debugf("Processing WideIP Pool: %v", UniquePoolName)
 		pool := GSLBPool{
 			Name:          UniquePoolName,
@@ -2499,7 +2636,7 @@ func (ctlr *Controller) processExternalDNS(edns *cisapiv1.ExternalDNS, isDelete
 							pool.Members[0] = fmt.Sprintf("%v/%v/Shared/%v", preGTMServerName, partition, vsName)
 							if partition != ctlr.Partition {
 								// Modify pool name to partition containing VS
-								pool.Name = edns.Spec.DomainName + "_" + AS3NameFormatter(strings.TrimPrefix(ctlr.Agent.BIGIPURL, "https://")) + "_" + partition
+								pool.Name = edns.Spec.DomainName + "_" + AS3NameFormatter(strings.TrimPrefix(ctlr.Agent.getBIGIPURL(), "https://")) + "_" + partition
 							}
 						}
 						continue
@@ -2509,7 +2646,7 @@ func (ctlr *Controller) processExternalDNS(edns *cisapiv1.ExternalDNS, isDelete
 					// Modify pool name to partition containing VS
 					if partition != ctlr.Partition {
 						// Modify pool name to partition containing VS
-						pool.Name = edns.Spec.DomainName + "_" + AS3NameFormatter(strings.TrimPrefix(ctlr.Agent.BIGIPURL, "https://")) + "_" + partition
+						pool.Name = edns.Spec.DomainName + "_" + AS3NameFormatter(strings.TrimPrefix(ctlr.Agent.getBIGIPURL(), "https://")) + "_" + partition
 					}
 					pool.Members = append(
 						pool.Members,
@@ -2735,7 +2872,7 @@ func (ctlr *Controller) processIPAM(ipam *ficV1.IPAM) error {
 			var vss []*cisapiv1.VirtualServer
 			vss = ctlr.getAllVirtualServers(ns)
 			for _, vs := range vss {
-				key := vs.Namespace + "/" + vs.Spec.Host + "_host"
+				key := vs.Namespace + "/" + getVirtualServerHosts(vs)[0] + "_host"
 				if pKey == key {
 					ctlr.TeemData.Lock()
 					ctlr.TeemData.ResourceType.IPAMVS[ns]++
@@ -2798,20 +2935,25 @@ func (ctlr *Controller) processIngressLink(
 	ingLink *cisapiv1.IngressLink,
 	isILDeleted bool,
 ) error {
+	rscLog := resourceLogger(IngressLink, ingLink)
 
 	startTime := time.Now()
 	defer func() {
 		endTime := time.Now()
-		log.Debugf("Finished syncing Ingress Links %+v (%v)",
+		rscLog.Debugf("Finished syncing Ingress Links %+v (%v)",
 			ingLink, endTime.Sub(startTime))
 	}()
+	if !isILDeleted {
+		ctlr.holdPausedResource(IngressLink, ingLink, ctlr.getCRPartition(ingLink.Spec.Partition, ""))
+	}
+
 	// Skip validation for a deleted ingressLink
 	if !isILDeleted {
 		// check if the virutal server matches all the requirements.
 		vkey := ingLink.ObjectMeta.Namespace + "/" + ingLink.ObjectMeta.Name
 		valid := ctlr.checkValidIngressLink(ingLink)
 		if false == valid {
-			log.Errorf("ingressLink %s, is not valid",
+			rscLog.Errorf("ingressLink %s, is not valid",
 				vkey)
 			return nil
 		}
@@ -2830,7 +2972,7 @@ func (ctlr *Controller) processIngressLink(
 	var ip string
 	var key string
 	var status int
-	partition := ctlr.getCRPartition(ingLink.Spec.Partition)
+	partition := ctlr.getCRPartition(ingLink.Spec.Partition, "")
 	key = ingLink.ObjectMeta.Namespace + "/" + ingLink.ObjectMeta.Name + "_il"
 	if ctlr.ipamCli != nil {
 		if isILDeleted && ingLink.Spec.VirtualServerAddress == "" {
@@ -2842,21 +2984,21 @@ func (ctlr *Controller) processIngressLink(
 
 			switch status {
 			case NotEnabled:
-				log.Debug("IPAM Custom Resource Not Available")
+				rscLog.Debug("IPAM Custom Resource Not Available")
 				return nil
 			case InvalidInput:
-				log.Debugf("IPAM Invalid IPAM Label: %v for IngressLink: %s/%s",
+				rscLog.Debugf("IPAM Invalid IPAM Label: %v for IngressLink: %s/%s",
 					ingLink.Spec.IPAMLabel, ingLink.Namespace, ingLink.Name)
 				return nil
 			case NotRequested:
 				return fmt.Errorf("unable to make IPAM Request, will be re-requested soon")
 			case Requested:
-				log.Debugf("IP address requested for IngressLink: %s/%s", ingLink.Namespace, ingLink.Name)
+				rscLog.Debugf("IP address requested for IngressLink: %s/%s", ingLink.Namespace, ingLink.Name)
 				return nil
 			}
-			log.Debugf("[ipam] requested IP for ingLink %v is: %v", ingLink.ObjectMeta.Name, ip)
+			rscLog.Debugf("[ipam] requested IP for ingLink %v is: %v", ingLink.ObjectMeta.Name, ip)
 			if ip == "" {
-				log.Debugf("[ipam] requested IP for ingLink %v is empty.", ingLink.ObjectMeta.Name)
+				rscLog.Debugf("[ipam] requested IP for ingLink %v is empty.", ingLink.ObjectMeta.Name)
 				return nil
 			}
 			ctlr.updateIngressLinkStatus(ingLink, ip)
@@ -2923,7 +3065,7 @@ func (ctlr *Controller) processIngressLink(
 	if ctlr.PoolMemberType == NodePort {
 		targetPort = getNodeport(svc, nginxMonitorPort)
 		if targetPort == 0 {
-			log.Errorf("Nodeport not found for nginx monitor port: %v", nginxMonitorPort)
+			rscLog.Errorf("Nodeport not found for nginx monitor port: %v", nginxMonitorPort)
 		}
 	}
 
@@ -3295,6 +3437,39 @@ func (ctlr *Controller) updateVirtualServerStatus(vs *cisapiv1.VirtualServer, ip
 	}
 }
 
+// recordVirtualServerWarning raises a warning event on the VirtualServer
+func (ctlr *Controller) recordVirtualServerWarning(vs *cisapiv1.VirtualServer, reason, message string) {
+	if ctlr.kubeClient == nil {
+		return
+	}
+	now := metav1.Now()
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: vs.Name + ".",
+			Namespace:    vs.Namespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			Kind:            VirtualServer,
+			APIVersion:      cisapiv1.SchemeGroupVersion.String(),
+			Namespace:       vs.Namespace,
+			Name:            vs.Name,
+			UID:             vs.UID,
+			ResourceVersion: vs.ResourceVersion,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           v1.EventTypeWarning,
+		Source:         v1.EventSource{Component: "k8s-bigip-ctlr"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := ctlr.kubeClient.CoreV1().Events(vs.Namespace).Create(context.TODO(), event, metav1.CreateOptions{})
+	if err != nil {
+		log.Debugf("Unable to create event for VirtualServer %v/%v: %v", vs.Namespace, vs.Name, err)
+	}
+}
+
 // Update Transport server status with virtual server address
 func (ctlr *Controller) updateTransportServerStatus(ts *cisapiv1.TransportServer, ip string, statusOk string) {
 	// Set the vs status to include the virtual IP address
@@ -424,6 +424,25 @@ var _ = Describe("Worker Tests", func() {
 			Expect(len(res)).To(Equal(2), "Wrong list of Virtual Servers")
 			Expect(res[0]).To(Equal(vrt2), "Wrong list of Virtual Servers")
 			Expect(res[1]).To(Equal(vrt3), "Wrong list of Virtual Servers")
+
+			// Services of alternateBackends
+			vrt4 := test.NewVirtualServer(
+				"SampleVS4",
+				"default",
+				cisapiv1.VirtualServerSpec{
+					Host: "test4.com",
+					Pools: []cisapiv1.Pool{
+						cisapiv1.Pool{
+							Path:    "/path",
+							Service: "svc-stable",
+							AlternateBackends: []cisapiv1.AlternateBackend{
+								{Service: "svc", ServiceNamespace: ns},
+							},
+						},
+					},
+				})
+			res = filterVirtualServersForService([]*cisapiv1.VirtualServer{vrt1, vrt4}, svc)
+			Expect(res).To(Equal([]*cisapiv1.VirtualServer{vrt4}), "Wrong list of Virtual Servers")
 		})
 		It("Filter TS for Service", func() {
 			ns := "temp"
@@ -489,6 +508,12 @@ var _ = Describe("Worker Tests", func() {
 			Expect(len(res)).To(Equal(2), "Wrong list of Virtual Servers")
 			Expect(res[0]).To(Equal(vrt2), "Wrong list of Virtual Servers")
 			Expect(res[1]).To(Equal(vrt3), "Wrong list of Virtual Servers")
+
+			vrt3.Spec.Host = ""
+			vrt3.Spec.Hosts = []string{"test1.com", "test2.com"}
+			res = getVirtualServersForTLSProfile([]*cisapiv1.VirtualServer{vrt1, vrt2, vrt3}, tlsProf)
+			Expect(len(res)).To(Equal(2), "Wrong list of Virtual Servers")
+			Expect(res[1]).To(Equal(vrt3), "VirtualServer with multiple hosts not matched")
 		})
 
 		It("VS Handling HTTP", func() {
@@ -549,6 +574,18 @@ var _ = Describe("Worker Tests", func() {
 					false, &VSSpecProperties{})
 				Expect(len(virts)).To(Equal(1), "Wrong number of Virtual Servers")
 				Expect(virts[0].Name).To(Equal("SampleVS2"), "Wrong Virtual Server")
+
+				// Pools of the same VirtualServer may share a path
+				vrt3.Spec.Pools[0].Path = "/path3"
+				vrt3.Spec.Pools = append(vrt3.Spec.Pools, cisapiv1.Pool{
+					Path:     "/path3",
+					PathType: PathTypeExact,
+					Service:  "svc2",
+				})
+				virts = mockCtlr.getAssociatedVirtualServers(vrt2,
+					[]*cisapiv1.VirtualServer{vrt2, vrt3},
+					false, &VSSpecProperties{})
+				Expect(len(virts)).To(Equal(2), "Wrong number of Virtual Servers")
 			})
 			It("Verify Pool Based WAF ", func() {
 				vrt2.Spec.Pools[0].WAF = "/Common/WAF_Policy"
@@ -755,6 +792,28 @@ var _ = Describe("Worker Tests", func() {
 				Expect(virts[2].Name).To(Equal("SampleVS4"), "Wrong Virtual Server")
 			})
 
+			It("Multiple Hosts", func() {
+				vrt2.Spec.Host = ""
+				vrt2.Spec.Hosts = []string{"test1.com", "test2.com"}
+				vrt4.Spec.Host = "test1.com"
+				vrt4.Spec.Pools[0].Path = "/path"
+
+				// vrt3 shares a host with vrt2, vrt4 shares a host and a path with vrt2
+				virts := mockCtlr.getAssociatedVirtualServers(vrt2,
+					[]*cisapiv1.VirtualServer{vrt2, vrt3, vrt4},
+					false, &VSSpecProperties{})
+				Expect(len(virts)).To(Equal(2), "Wrong number of Virtual Servers")
+				Expect(virts[0].Name).To(Equal("SampleVS2"), "Wrong Virtual Server")
+				Expect(virts[1].Name).To(Equal("SampleVS3"), "Wrong Virtual Server")
+
+				hostVirts := getVirtualServersForHosts(virts)
+				Expect(len(hostVirts)).To(Equal(3), "Wrong number of Virtual Servers per host")
+				Expect(hostVirts[0].Spec.Host).To(Equal("test1.com"))
+				Expect(hostVirts[1].Spec.Host).To(Equal("test2.com"))
+				Expect(hostVirts[1].Spec.Pools).To(Equal(vrt2.Spec.Pools))
+				Expect(hostVirts[2]).To(Equal(vrt3))
+			})
+
 			It("IPAM Label", func() {
 				mockCtlr.ipamCli = &ipammachinery.IPAMClient{}
 				vrt2.Spec.IPAMLabel = "test"
@@ -1487,7 +1546,6 @@ var _ = Describe("Worker Tests", func() {
 			mockPM.BIGIPURL = "bigip.com"
 			mockPM.BIGIPUsername = "user"
 			mockPM.BIGIPPassword = "pswd"
-			mockPM.tenantResponseMap = make(map[string]tenantResponse)
 			mockPM.LogResponse = true
 			//					mockPM.AS3PostDelay =
 			mockPM.setupBIGIPRESTClient()
@@ -1783,11 +1841,27 @@ var _ = Describe("Worker Tests", func() {
 				vs.Spec.HTTPTraffic = TLSRedirectInsecure
 				valid = mockCtlr.checkValidVirtualServer(vs)
 				Expect(valid).To(BeFalse(), "HTTPTraffic not allowed to be set for insecure VS")
+				vs.Spec.HTTPTraffic = ""
+				vs.Spec.Pools = append(vs.Spec.Pools, cisapiv1.Pool{
+					Path:           "/old/.*",
+					PathType:       PathTypeRegex,
+					DirectResponse: &cisapiv1.DirectResponse{Status: 410},
+				})
+				valid = mockCtlr.checkValidVirtualServer(vs)
+				Expect(valid).To(BeFalse(), "directResponse allowed with regex path")
+				vs.Spec.Pools[len(vs.Spec.Pools)-1] = cisapiv1.Pool{
+					Path:     "/old",
+					Redirect: &cisapiv1.PoolRedirect{URL: "https://example.com"},
+					Match: &cisapiv1.PoolMatch{Headers: []cisapiv1.HTTPMatch{
+						{Name: "User-Agent", Operand: MatchOperandRegex, Values: []string{"bot"}},
+					}},
+				}
+				valid = mockCtlr.checkValidVirtualServer(vs)
+				Expect(valid).To(BeFalse(), "redirect allowed with regex match")
 
 			})
 			It("Virtual Server with IPAM", func() {
 				go mockCtlr.Agent.agentWorker()
-				go mockCtlr.Agent.retryWorker()
 
 				go mockCtlr.responseHandler(mockCtlr.Agent.respChan)
 				mockCtlr.addPolicy(policy)
@@ -2044,7 +2118,6 @@ var _ = Describe("Worker Tests", func() {
 
 			It("Transport Server Validation", func() {
 				go mockCtlr.Agent.agentWorker()
-				go mockCtlr.Agent.retryWorker()
 				go mockCtlr.responseHandler(mockCtlr.Agent.respChan)
 
 				mockCtlr.addEndpoints(fooEndpts)
@@ -2108,7 +2181,6 @@ var _ = Describe("Worker Tests", func() {
 
 			It("Transport Server with IPAM", func() {
 				go mockCtlr.Agent.agentWorker()
-				go mockCtlr.Agent.retryWorker()
 				mockCtlr.TeemData.ResourceType.IPAMTS = make(map[string]int)
 				//Add Service
 				mockCtlr.addEndpoints(fooEndpts)
@@ -2219,7 +2291,6 @@ var _ = Describe("Worker Tests", func() {
 
 			It("Transport Server with Partition", func() {
 				go mockCtlr.Agent.agentWorker()
-				go mockCtlr.Agent.retryWorker()
 				mockCtlr.Partition = "test"
 				mockCtlr.TeemData.ResourceType.IPAMTS = make(map[string]int)
 				//Add Service
@@ -2350,7 +2421,6 @@ var _ = Describe("Worker Tests", func() {
 			It("EDNS", func() {
 				//Add Service
 				//go mockCtlr.Agent.agentWorker()
-				//go mockCtlr.Agent.retryWorker()
 				mockCtlr.addEndpoints(fooEndpts)
 				mockCtlr.processResources()
 
@@ -2372,7 +2442,6 @@ var _ = Describe("Worker Tests", func() {
 		Describe("Processing Ingress Link", func() {
 			It("Ingress Link", func() {
 				go mockCtlr.Agent.agentWorker()
-				go mockCtlr.Agent.retryWorker()
 				fooPorts := []v1.ServicePort{
 					{
 						Port: 8080,
@@ -2475,7 +2544,6 @@ var _ = Describe("Worker Tests", func() {
 			})
 			It("Ingress Link with partition", func() {
 				go mockCtlr.Agent.agentWorker()
-				go mockCtlr.Agent.retryWorker()
 				mockCtlr.Partition = "test"
 				fooPorts := []v1.ServicePort{
 					{
@@ -2612,7 +2680,6 @@ var _ = Describe("Worker Tests", func() {
 			mockPM.BIGIPURL = "bigip.com"
 			mockPM.BIGIPUsername = "user"
 			mockPM.BIGIPPassword = "pswd"
-			mockPM.tenantResponseMap = make(map[string]tenantResponse)
 			mockPM.LogResponse = true
 			//					mockPM.AS3PostDelay =
 			mockPM.setupBIGIPRESTClient()
@@ -2922,7 +2989,6 @@ extendedRouteSpec:
 				}
 				mockCtlr.Agent.ConfigWriter = writer
 				go mockCtlr.Agent.agentWorker()
-				go mockCtlr.Agent.retryWorker()
 
 				routeGroup := "default"
 				mockCtlr.addPolicy(policy)
@@ -3059,12 +3125,10 @@ extendedRouteSpec:
 				_, ok := mockCtlr.nsInformers[namespace]
 				Expect(ok).To(Equal(false), "Namespace not deleted")
 
-				mockCtlr.Agent.retryFailedTenant()
 				//time.Sleep(1 * time.Microsecond)
 			})
 			It("Process Edge Route", func() {
 				go mockCtlr.Agent.agentWorker()
-				go mockCtlr.Agent.retryWorker()
 
 				mockCtlr.resources.invertedNamespaceLabelMap[namespace] = routeGroup
 				mockCtlr.addConfigMap(cm)
@@ -3106,12 +3170,13 @@ extendedRouteSpec:
 			It("Process Pass-through Route", func() {
 				go mockCtlr.responseHandler(mockCtlr.Agent.respChan)
 				go mockCtlr.Agent.agentWorker()
-				go mockCtlr.Agent.retryWorker()
 				mockCtlr.initState = true
 				mockCtlr.resources.invertedNamespaceLabelMap[namespace] = routeGroup
 				mockCtlr.addConfigMap(cm)
 				mockCtlr.processResources()
-				mockCtlr.resourceQueue.Get()
+				// Drop the ConfigMap requeued during init
+				key, _ := mockCtlr.resourceQueue.Get()
+				delete(mockCtlr.initRequeued, key.(*rqKey))
 				routeGroup := "default"
 
 				mockCtlr.initState = false
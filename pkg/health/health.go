@@ -1,33 +1,69 @@
 package health
 
 import (
+	"errors"
 	"net/http"
 	"os"
 
 	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
 )
 
+var errPythonDead = errors.New("Python process is dead")
+
 type HealthChecker struct {
 	SubPID int
+	// NoSubProcess is set when CIS runs without the python driver
+	NoSubProcess bool
+	// BigIPCheck returns an error while requests to BIG-IP are paused, it only affects the readiness
+	BigIPCheck func() error
 }
 
 // TODO: Add additional health checks
 // TODO: add health check if Kubernetes API is still reachable
 func (hc HealthChecker) HealthCheckHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if hc.SubPID != 0 {
-			_, err := os.FindProcess(hc.SubPID)
-			if err == nil {
-				// assume that Python process is still running
-				w.WriteHeader(http.StatusOK)
-				w.Write([]byte("Ok"))
+		if err := hc.checkSubProcess(); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("Ok"))
+	})
+}
+
+// ReadinessHandler responds with 503 while requests to BIG-IP are paused, unlike HealthCheckHandler
+// which is used as liveness probe and does not restart CIS while the BIG-IP is unavailable
+func (hc HealthChecker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := hc.checkSubProcess(); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		if hc.BigIPCheck != nil {
+			if err := hc.BigIPCheck(); err != nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(err.Error()))
 				return
 			}
-
-			log.Errorf(err.Error())
 		}
-
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte("Python process is dead"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("Ok"))
 	})
 }
+
+func (hc HealthChecker) checkSubProcess() error {
+	if hc.NoSubProcess {
+		return nil
+	}
+	if hc.SubPID != 0 {
+		_, err := os.FindProcess(hc.SubPID)
+		if err == nil {
+			// assume that Python process is still running
+			return nil
+		}
+		log.Errorf(err.Error())
+	}
+	return errPythonDead
+}
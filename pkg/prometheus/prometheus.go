@@ -31,6 +31,30 @@ var CurrentErrors = prometheus.NewGaugeVec(
 	[]string{},
 )
 
+var HeldTenantDeclarations = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "bigip_held_tenant_declarations",
+		Help: "Count of virtual servers removal held by the mass deletion guard per tenant",
+	},
+	[]string{"tenant"},
+)
+
+var CoalescedConfigRequests = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Name:    "bigip_coalesced_config_requests",
+		Help:    "Count of config requests coalesced into each AS3 declaration",
+		Buckets: []float64{1, 2, 5, 10, 20, 50, 100},
+	},
+)
+
+var CircuitBreakerOpen = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "bigip_circuit_breaker_open",
+		Help: "Set to 1 while requests to the BIG-IP are paused as it is unreachable or busy",
+	},
+	[]string{"bigip"},
+)
+
 // further metrics? todo think about
 // RegisterMetrics registers all Prometheus metrics defined above
 func RegisterMetrics() {
@@ -38,4 +62,7 @@ func RegisterMetrics() {
 	prometheus.MustRegister(MonitoredNodes)
 	prometheus.MustRegister(MonitoredServices)
 	prometheus.MustRegister(CurrentErrors)
+	prometheus.MustRegister(HeldTenantDeclarations)
+	prometheus.MustRegister(CoalescedConfigRequests)
+	prometheus.MustRegister(CircuitBreakerOpen)
 }
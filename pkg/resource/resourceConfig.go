@@ -1653,10 +1653,11 @@ func IsAnnotationRule(ruleName string) bool {
 // Returns a copy of the resource config metadata
 func copyRCMetaData(cfg *ResourceConfig) MetaData {
 	metadata := MetaData{
-		Active:       cfg.MetaData.Active,
-		ResourceType: cfg.MetaData.ResourceType,
-		RouteProfs:   make(map[RouteKey]string),
-		IngName:      cfg.MetaData.IngName,
+		Active:             cfg.MetaData.Active,
+		ResourceType:       cfg.MetaData.ResourceType,
+		RouteProfs:         make(map[RouteKey]string),
+		IngName:            cfg.MetaData.IngName,
+		DefaultPoolIngress: cfg.MetaData.DefaultPoolIngress,
 	}
 	for k, v := range cfg.MetaData.RouteProfs {
 		metadata.RouteProfs[k] = v
@@ -2746,6 +2747,10 @@ func (r Rules) Less(i, j int) bool {
 	}
 
 	if r[i].FullURI == r[j].FullURI {
+		// The rules are sorted in reverse, so that the exact path precedes the prefix path
+		if IsExactPathRule(r[i]) != IsExactPathRule(r[j]) {
+			return IsExactPathRule(r[j])
+		}
 		if len(r[j].Actions) > 0 && r[j].Actions[0].Reset {
 			return false
 		}
@@ -2760,6 +2765,16 @@ func (r Rules) Swap(i, j int) {
 	r[j].Ordinal = j
 }
 
+// IsExactPathRule checks that the rule matches the whole path of the request
+func IsExactPathRule(rule *Rule) bool {
+	for _, cond := range rule.Conditions {
+		if cond.Path && cond.Equals {
+			return true
+		}
+	}
+	return false
+}
+
 func createPathSegmentConditions(u *url.URL) []*Condition {
 	var c []*Condition
 	path := strings.TrimPrefix(u.EscapedPath(), "/")
@@ -61,6 +61,8 @@ type (
 		// Name of the Ingress that created this config
 		// Used to prevent single-service Ingresses from sharing virtuals
 		IngName string
+		// Namespace/name of the Ingress whose default backend is the default pool of the virtual
+		DefaultPoolIngress string
 	}
 
 	// Key used to store annotated profiles for a route
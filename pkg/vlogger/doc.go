@@ -80,6 +80,20 @@ vlogger Close() function:
 
 	func Close()
 
+# STRUCTURED LOGGING
+
+Fields can be attached to messages to correlate them to the resources they
+refer to. An Entry holding the fields provides the same logging functions:
+
+	func WithFields(fields Fields) *Entry
+	func WithField(key string, value interface{}) *Entry
+
+Loggers implementing StructuredLogger, such as NewJSONLogger, record the fields
+with each message. Other loggers record only the message.
+
+Identical messages repeated within an interval can be suppressed by wrapping
+a logger with NewRateLimitedLogger.
+
 # LOG LEVELS
 
 To control the global logging level, several package level functions are provided:
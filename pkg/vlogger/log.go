@@ -22,6 +22,7 @@ import (
 	"log/syslog" // For LOG level definitions
 	"os"
 	"strings"
+	"sync"
 )
 
 // LogLevel is used for global (package-level) filtering of log messages based on their priority
@@ -127,6 +128,8 @@ var (
 	// logLevel indicates the current package-level filtering being applied
 	// (may be further restricted by specific concrete loggers).
 	logLevel LogLevel = LL_DEBUG
+	// logLevelMutex guards logLevel, which can be changed at runtime
+	logLevelMutex sync.RWMutex
 
 	// logLevelToSyslogLevel maps vlogger log levels to the internal representation used
 	// by the implementations (which use syslog's definitions).
@@ -251,6 +254,8 @@ func Panicf(format string, params ...interface{}) {
 
 // SetLogLevel sets the current package-level filtering
 func SetLogLevel(level LogLevel) {
+	logLevelMutex.Lock()
+	defer logLevelMutex.Unlock()
 	logLevel = level
 
 	// Update all loggers to the new level
@@ -264,6 +269,8 @@ func SetLogLevel(level LogLevel) {
 
 // GetLogLevel returns the current package-level filtering
 func GetLogLevel() LogLevel {
+	logLevelMutex.RLock()
+	defer logLevelMutex.RUnlock()
 	return logLevel
 }
 
@@ -0,0 +1,169 @@
+// Copyright (c) 2019-2021, F5 Networks, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// log_fields.go:
+//
+//	Provides structured fields which are attached to log messages.
+//	To use, create an Entry with the fields and log through it:
+//	  WithFields(Fields{FieldTenant: "tenant"}).Infof(...)
+package vlogger
+
+import (
+	"fmt"
+)
+
+// Well known field names used to correlate log messages to resources
+const (
+	FieldComponent = "component"
+	FieldNamespace = "namespace"
+	FieldKind      = "kind"
+	FieldName      = "name"
+	FieldTenant    = "tenant"
+	FieldRequestID = "requestId"
+	FieldTaskID    = "taskId"
+)
+
+type (
+	// Fields are key value pairs attached to a log message
+	Fields map[string]interface{}
+
+	// StructuredLogger is implemented by concrete loggers which record the fields of a message.
+	// Messages logged with fields to other loggers are recorded without their fields.
+	StructuredLogger interface {
+		Logger
+		Log(level LogLevel, fields Fields, msg string)
+	}
+
+	// Entry logs messages with a set of fields
+	Entry struct {
+		fields Fields
+	}
+)
+
+// WithFields returns an Entry logging messages with the given fields
+func WithFields(fields Fields) *Entry {
+	return (&Entry{}).WithFields(fields)
+}
+
+// WithField returns an Entry logging messages with the given field
+func WithField(key string, value interface{}) *Entry {
+	return WithFields(Fields{key: value})
+}
+
+// WithFields returns a new Entry with the given fields added to the fields of the entry
+func (e *Entry) WithFields(fields Fields) *Entry {
+	entry := &Entry{fields: make(Fields, len(e.fields)+len(fields))}
+	for k, v := range e.fields {
+		entry.fields[k] = v
+	}
+	for k, v := range fields {
+		entry.fields[k] = v
+	}
+	return entry
+}
+
+// WithField returns a new Entry with the given field added to the fields of the entry
+func (e *Entry) WithField(key string, value interface{}) *Entry {
+	return e.WithFields(Fields{key: value})
+}
+
+// Fields returns the fields of the entry
+func (e *Entry) Fields() Fields {
+	return e.fields
+}
+
+func (e *Entry) log(level LogLevel, msg string) {
+	if level < GetLogLevel() {
+		return
+	}
+	logWithFields(vlog[level], level, e.fields, msg)
+}
+
+// logWithFields records the message with its fields, or only the message when
+// the logger does not support structured logging
+func logWithFields(logger Logger, level LogLevel, fields Fields, msg string) {
+	if sl, ok := logger.(StructuredLogger); ok {
+		sl.Log(level, fields, msg)
+		return
+	}
+	switch level {
+	case LL_DEBUG:
+		logger.Debug(msg)
+	case LL_INFO:
+		logger.Info(msg)
+	case LL_WARNING:
+		logger.Warning(msg)
+	case LL_ERROR:
+		logger.Error(msg)
+	default:
+		logger.Critical(msg)
+	}
+}
+
+func (e *Entry) logf(level LogLevel, format string, params ...interface{}) {
+	if level < GetLogLevel() {
+		return
+	}
+	e.log(level, fmt.Sprintf(format, params...))
+}
+
+// Debug records a debug/trace level message with the fields of the entry
+func (e *Entry) Debug(msg string) {
+	e.log(LL_DEBUG, msg)
+}
+
+// Debugf formats and records a debug/trace level message with the fields of the entry
+func (e *Entry) Debugf(format string, params ...interface{}) {
+	e.logf(LL_DEBUG, format, params...)
+}
+
+// Info records an informational level message with the fields of the entry
+func (e *Entry) Info(msg string) {
+	e.log(LL_INFO, msg)
+}
+
+// Infof formats and records an informational level message with the fields of the entry
+func (e *Entry) Infof(format string, params ...interface{}) {
+	e.logf(LL_INFO, format, params...)
+}
+
+// Warning records a warning level message with the fields of the entry
+func (e *Entry) Warning(msg string) {
+	e.log(LL_WARNING, msg)
+}
+
+// Warningf formats and records a warning level message with the fields of the entry
+func (e *Entry) Warningf(format string, params ...interface{}) {
+	e.logf(LL_WARNING, format, params...)
+}
+
+// Error records an error level message with the fields of the entry
+func (e *Entry) Error(msg string) {
+	e.log(LL_ERROR, msg)
+}
+
+// Errorf formats and records an error level message with the fields of the entry
+func (e *Entry) Errorf(format string, params ...interface{}) {
+	e.logf(LL_ERROR, format, params...)
+}
+
+// Critical records a critical level message with the fields of the entry
+func (e *Entry) Critical(msg string) {
+	e.log(LL_CRITICAL, msg)
+}
+
+// Criticalf formats and records a critical level message with the fields of the entry
+func (e *Entry) Criticalf(format string, params ...interface{}) {
+	e.logf(LL_CRITICAL, format, params...)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2019-2021, F5 Networks, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// log_json.go:
+//
+//	Provides structured logging of JSON lines through the common interface.
+//	To use, create the logger object with the following syntax:
+//	  NewJSONLogger(os.Stdout)
+package vlogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/syslog"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	jsonLogger struct {
+		// slLogLevel uses syslog's definitions which have higher priority
+		// levels defined in descending order (0 is highest)
+		slLogLevel syslog.Priority
+		mutex      sync.Mutex
+		out        io.Writer
+	}
+)
+
+// NewJSONLogger creates a logger object that writes each message with its
+// fields as a JSON object on a single line. The writer is not closed by the logger.
+func NewJSONLogger(out io.Writer) *jsonLogger {
+	return &jsonLogger{
+		slLogLevel: syslog.LOG_DEBUG,
+		out:        out,
+	}
+}
+
+// Log writes the message with its fields
+func (jl *jsonLogger) Log(level LogLevel, fields Fields, msg string) {
+	if jl.slLogLevel < logLevelToSyslogLevel[level] {
+		return
+	}
+	var buf bytes.Buffer
+	buf.WriteString(`{"time":`)
+	writeJSONValue(&buf, time.Now().UTC().Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJSONValue(&buf, level.String())
+	buf.WriteString(`,"msg":`)
+	writeJSONValue(&buf, msg)
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		// Reserved keys are not overwritten by fields
+		if k != "time" && k != "level" && k != "msg" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteByte(',')
+		writeJSONValue(&buf, k)
+		buf.WriteByte(':')
+		writeJSONValue(&buf, fields[k])
+	}
+	buf.WriteString("}\n")
+
+	jl.mutex.Lock()
+	defer jl.mutex.Unlock()
+	jl.out.Write(buf.Bytes())
+}
+
+func writeJSONValue(buf *bytes.Buffer, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(data)
+}
+
+func (jl *jsonLogger) Debug(msg string) {
+	jl.Log(LL_DEBUG, nil, msg)
+}
+
+func (jl *jsonLogger) Debugf(format string, params ...interface{}) {
+	jl.Log(LL_DEBUG, nil, fmt.Sprintf(format, params...))
+}
+
+func (jl *jsonLogger) Info(msg string) {
+	jl.Log(LL_INFO, nil, msg)
+}
+
+func (jl *jsonLogger) Infof(format string, params ...interface{}) {
+	jl.Log(LL_INFO, nil, fmt.Sprintf(format, params...))
+}
+
+func (jl *jsonLogger) Warning(msg string) {
+	jl.Log(LL_WARNING, nil, msg)
+}
+
+func (jl *jsonLogger) Warningf(format string, params ...interface{}) {
+	jl.Log(LL_WARNING, nil, fmt.Sprintf(format, params...))
+}
+
+func (jl *jsonLogger) Error(msg string) {
+	jl.Log(LL_ERROR, nil, msg)
+}
+
+func (jl *jsonLogger) Errorf(format string, params ...interface{}) {
+	jl.Log(LL_ERROR, nil, fmt.Sprintf(format, params...))
+}
+
+func (jl *jsonLogger) Critical(msg string) {
+	jl.Log(LL_CRITICAL, nil, msg)
+}
+
+func (jl *jsonLogger) Criticalf(format string, params ...interface{}) {
+	jl.Log(LL_CRITICAL, nil, fmt.Sprintf(format, params...))
+}
+
+func (jl *jsonLogger) SetLogLevel(slLogLevel syslog.Priority) {
+	jl.slLogLevel = slLogLevel
+}
+
+func (jl *jsonLogger) GetLogLevel() syslog.Priority {
+	return jl.slLogLevel
+}
+
+func (jl *jsonLogger) Close() {
+}
@@ -0,0 +1,78 @@
+package vlogger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	log "github.com/F5Networks/k8s-bigip-ctlr/v2/pkg/vlogger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Structured Logging Tests", func() {
+	var buf *bytes.Buffer
+
+	lines := func() []map[string]interface{} {
+		var entries []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			entry := make(map[string]interface{})
+			Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		log.RegisterLogger(log.LL_MIN_LEVEL, log.LL_MAX_LEVEL, log.NewJSONLogger(buf))
+		log.SetLogLevel(log.LL_INFO)
+	})
+
+	AfterEach(func() {
+		log.RegisterLogger(log.LL_MIN_LEVEL, log.LL_MAX_LEVEL, log.NewJSONLogger(ioutil.Discard))
+		log.SetLogLevel(log.LL_DEBUG)
+	})
+
+	It("Logs messages with fields as JSON lines", func() {
+		entry := log.WithFields(log.Fields{log.FieldComponent: "agent", log.FieldTenant: "test"})
+		entry.WithField(log.FieldRequestID, 3).Errorf("post failed: %v", "timeout")
+		log.Infof("plain %v", "message")
+		entry.Debug("filtered")
+
+		entries := lines()
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0]).To(HaveKeyWithValue("level", "error"))
+		Expect(entries[0]).To(HaveKeyWithValue("msg", "post failed: timeout"))
+		Expect(entries[0]).To(HaveKeyWithValue(log.FieldComponent, "agent"))
+		Expect(entries[0]).To(HaveKeyWithValue(log.FieldTenant, "test"))
+		Expect(entries[0]).To(HaveKeyWithValue(log.FieldRequestID, BeEquivalentTo(3)))
+		Expect(entries[0]).To(HaveKey("time"))
+		Expect(entries[1]).To(HaveKeyWithValue("msg", "plain message"))
+		// Entries are not modified by adding fields
+		Expect(entry.Fields()).NotTo(HaveKey(log.FieldRequestID))
+	})
+
+	It("Suppresses identical repeated messages", func() {
+		log.RegisterLogger(log.LL_MIN_LEVEL, log.LL_MAX_LEVEL, log.NewRateLimitedLogger(log.NewJSONLogger(buf), 100*time.Millisecond))
+		log.SetLogLevel(log.LL_INFO)
+
+		for i := 0; i < 3; i++ {
+			log.Error("BIG-IP unreachable")
+			log.WithField(log.FieldTenant, "test").Error("BIG-IP unreachable")
+		}
+		log.Info("other message")
+		Expect(lines()).To(HaveLen(3))
+
+		time.Sleep(100 * time.Millisecond)
+		log.Error("BIG-IP unreachable")
+		entries := lines()
+		Expect(entries).To(HaveLen(4))
+		Expect(entries[3]).To(HaveKeyWithValue("msg", "BIG-IP unreachable (2 identical messages suppressed)"))
+	})
+})
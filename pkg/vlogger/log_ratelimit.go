@@ -0,0 +1,153 @@
+// Copyright (c) 2019-2021, F5 Networks, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// log_ratelimit.go:
+//
+//	Suppresses identical messages repeated within an interval.
+//	To use, wrap a concrete logger with the following syntax:
+//	  NewRateLimitedLogger(NewConsoleLogger(), interval)
+package vlogger
+
+import (
+	"fmt"
+	"log/syslog"
+	"sync"
+	"time"
+)
+
+// maxTrackedMessages bounds the number of distinct messages remembered by the rate limiter
+const maxTrackedMessages = 1024
+
+type (
+	rateLimitedLogger struct {
+		logger   Logger
+		interval time.Duration
+		now      func() time.Time
+		mutex    sync.Mutex
+		messages map[string]*repeatedMessage
+	}
+
+	repeatedMessage struct {
+		lastLogged time.Time
+		suppressed int
+	}
+)
+
+// NewRateLimitedLogger wraps a logger so that a message identical to one logged
+// within the interval is dropped. The number of dropped messages is reported the
+// next time the message is logged.
+func NewRateLimitedLogger(logger Logger, interval time.Duration) *rateLimitedLogger {
+	return &rateLimitedLogger{
+		logger:   logger,
+		interval: interval,
+		now:      time.Now,
+		messages: make(map[string]*repeatedMessage),
+	}
+}
+
+// allow reports whether the message is logged and the number of identical messages suppressed before it
+func (rl *rateLimitedLogger) allow(level LogLevel, fields Fields, msg string) (bool, int) {
+	key := fmt.Sprint(level, fields, msg)
+	now := rl.now()
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	if repeated, ok := rl.messages[key]; ok {
+		if now.Sub(repeated.lastLogged) < rl.interval {
+			repeated.suppressed++
+			return false, 0
+		}
+		suppressed := repeated.suppressed
+		repeated.lastLogged = now
+		repeated.suppressed = 0
+		return true, suppressed
+	}
+	if len(rl.messages) >= maxTrackedMessages {
+		for k, repeated := range rl.messages {
+			if now.Sub(repeated.lastLogged) >= rl.interval {
+				delete(rl.messages, k)
+			}
+		}
+	}
+	if len(rl.messages) < maxTrackedMessages {
+		rl.messages[key] = &repeatedMessage{lastLogged: now}
+	}
+	return true, 0
+}
+
+// Log records the message unless it is suppressed
+func (rl *rateLimitedLogger) Log(level LogLevel, fields Fields, msg string) {
+	if rl.logger.GetLogLevel() < logLevelToSyslogLevel[level] {
+		return
+	}
+	ok, suppressed := rl.allow(level, fields, msg)
+	if !ok {
+		return
+	}
+	if suppressed > 0 {
+		msg = fmt.Sprintf("%v (%v identical messages suppressed)", msg, suppressed)
+	}
+	logWithFields(rl.logger, level, fields, msg)
+}
+
+func (rl *rateLimitedLogger) Debug(msg string) {
+	rl.Log(LL_DEBUG, nil, msg)
+}
+
+func (rl *rateLimitedLogger) Debugf(format string, params ...interface{}) {
+	rl.Log(LL_DEBUG, nil, fmt.Sprintf(format, params...))
+}
+
+func (rl *rateLimitedLogger) Info(msg string) {
+	rl.Log(LL_INFO, nil, msg)
+}
+
+func (rl *rateLimitedLogger) Infof(format string, params ...interface{}) {
+	rl.Log(LL_INFO, nil, fmt.Sprintf(format, params...))
+}
+
+func (rl *rateLimitedLogger) Warning(msg string) {
+	rl.Log(LL_WARNING, nil, msg)
+}
+
+func (rl *rateLimitedLogger) Warningf(format string, params ...interface{}) {
+	rl.Log(LL_WARNING, nil, fmt.Sprintf(format, params...))
+}
+
+func (rl *rateLimitedLogger) Error(msg string) {
+	rl.Log(LL_ERROR, nil, msg)
+}
+
+func (rl *rateLimitedLogger) Errorf(format string, params ...interface{}) {
+	rl.Log(LL_ERROR, nil, fmt.Sprintf(format, params...))
+}
+
+func (rl *rateLimitedLogger) Critical(msg string) {
+	rl.Log(LL_CRITICAL, nil, msg)
+}
+
+func (rl *rateLimitedLogger) Criticalf(format string, params ...interface{}) {
+	rl.Log(LL_CRITICAL, nil, fmt.Sprintf(format, params...))
+}
+
+func (rl *rateLimitedLogger) SetLogLevel(slLogLevel syslog.Priority) {
+	rl.logger.SetLogLevel(slLogLevel)
+}
+
+func (rl *rateLimitedLogger) GetLogLevel() syslog.Priority {
+	return rl.logger.GetLogLevel()
+}
+
+func (rl *rateLimitedLogger) Close() {
+	rl.logger.Close()
+}